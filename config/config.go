@@ -43,6 +43,24 @@ type LocaleConfig struct {
 type ServerConfig struct {
 	Port int    `mapstructure:"port"`
 	Mode string `mapstructure:"mode"`
+	// ShutdownTimeoutSeconds 优雅关闭总超时（含注销与 drain），默认 15 秒
+	ShutdownTimeoutSeconds int `mapstructure:"shutdownTimeoutSeconds"`
+	// DrainDelaySeconds 注销注册中心后、停止 HTTP/gRPC 前的等待时间，供调用方刷新实例缓存；计入总超时
+	DrainDelaySeconds int `mapstructure:"drainDelaySeconds"`
+	// ReadinessTimeoutSeconds 注册前等待就绪检查通过的最长时间，超时则启动失败，默认 30 秒
+	ReadinessTimeoutSeconds int `mapstructure:"readinessTimeoutSeconds"`
+	// Health /health/ready 依赖检查
+	Health HealthConfig `mapstructure:"health"`
+}
+
+// HealthConfig 健康检查，单项检查可在注册时单独覆盖超时与缓存
+type HealthConfig struct {
+	// TimeoutMs 单项检查超时，默认 2000
+	TimeoutMs int `mapstructure:"timeoutMs"`
+	// CacheMs 检查结果缓存时长，避免探针频繁打到依赖，默认 1000；0 表示不缓存
+	CacheMs *int `mapstructure:"cacheMs"`
+	// SyncIntervalSeconds 按就绪状态同步 gRPC 健康服务的间隔，默认 5
+	SyncIntervalSeconds int `mapstructure:"syncIntervalSeconds"`
 }
 
 // LogConfig 日志配置
@@ -55,6 +73,29 @@ type LogConfig struct {
 	Compress   bool   `mapstructure:"compress"`
 	Stdout     bool   `mapstructure:"stdout"`
 	LogSQL     bool   `mapstructure:"log_sql"` // 新增：是否记录SQL日志
+	// Levels 按命名 logger 覆盖级别（如 rpc = "warn"、"rpc.resolver" = "debug"），与 level 一样支持热更新
+	Levels LogLevels `mapstructure:"levels"`
+	// Sampling 高频日志采样，initial 与 thereafter 均为 0 时不采样；修改后重启生效
+	Sampling LogSamplingConfig `mapstructure:"sampling"`
+	// Redact 日志脱敏，支持热更新
+	Redact LogRedactConfig `mapstructure:"redact"`
+}
+
+// LogRedactConfig 日志脱敏配置：内置敏感键与检测器始终生效，这里只做追加与关闭
+type LogRedactConfig struct {
+	// Keys 追加的敏感键（去掉 _ / - 后忽略大小写按后缀匹配），作用于日志字段、请求响应体与 SQL
+	Keys []string `mapstructure:"keys"`
+	// DisabledDetectors 关闭的内置检测器：bearer、jwt、card
+	DisabledDetectors []string `mapstructure:"disabled_detectors"`
+	// SkipBodyPaths 不记录响应体的路由（支持 * / **），未配置时为登录、注册与文件上传下载
+	SkipBodyPaths []string `mapstructure:"skip_body_paths"`
+}
+
+// LogSamplingConfig 日志采样配置：每个周期内同级别、同消息的前 initial 条全部输出，之后每 thereafter 条输出 1 条
+type LogSamplingConfig struct {
+	Initial     int `mapstructure:"initial"`
+	Thereafter  int `mapstructure:"thereafter"`
+	TickSeconds int `mapstructure:"tick_seconds"` // 统计周期，默认 1 秒
 }
 
 // DatabaseConfig 数据库配置
@@ -149,7 +190,7 @@ func Init() error {
 		fmt.Printf("配置文件 %s 不存在，使用默认配置\n", fileName)
 		setDefaultConfig()
 		GlobalConfig = &Config{}
-		return viper.Unmarshal(GlobalConfig)
+		return unmarshalConfig(GlobalConfig)
 	}
 
 	// 读取配置文件
@@ -161,20 +202,15 @@ func Init() error {
 	viper.WatchConfig()
 	viper.OnConfigChange(func(e fsnotify.Event) {
 		fmt.Println("配置文件已更新:", e.Name)
-		// 重新加载配置
-		tempConfig := &Config{}
-		if err := viper.Unmarshal(tempConfig); err != nil {
-			fmt.Printf("重新加载配置失败: %v\n", err)
-		} else {
-			configMutex.Lock()
-			GlobalConfig = tempConfig
-			configMutex.Unlock()
+		// viper 已重新读取文件，需重新叠加远程配置层
+		if err := Rebuild(); err != nil {
+			fmt.Printf("%v\n", err)
 		}
 	})
 
 	// 解析配置到结构体
 	GlobalConfig = &Config{}
-	if err := viper.Unmarshal(GlobalConfig); err != nil {
+	if err := unmarshalConfig(GlobalConfig); err != nil {
 		return errors.Wrap(err, "解析配置失败")
 	}
 
@@ -193,17 +229,17 @@ func GetConfigByType(configType string, target interface{}) error {
 	// 根据配置类型获取子配置并反序列化到目标结构体
 	switch strings.ToLower(configType) {
 	case "log":
-		return viper.UnmarshalKey("log", target)
+		return viper.UnmarshalKey("log", target, decodeOption)
 	case "server":
-		return viper.UnmarshalKey("server", target)
+		return viper.UnmarshalKey("server", target, decodeOption)
 	case "database", "db":
-		return viper.UnmarshalKey("database", target)
+		return viper.UnmarshalKey("database", target, decodeOption)
 	case "redis":
-		return viper.UnmarshalKey("redis", target)
+		return viper.UnmarshalKey("redis", target, decodeOption)
 	case "plugins", "plugins.nacos", "plugins.rpc":
-		return viper.UnmarshalKey(configType, target)
+		return viper.UnmarshalKey(configType, target, decodeOption)
 	default:
-		return viper.UnmarshalKey(configType, target)
+		return viper.UnmarshalKey(configType, target, decodeOption)
 	}
 }
 
@@ -214,6 +250,12 @@ func setDefaultConfig() {
 	viper.SetDefault("version", "1.0.0")
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.mode", "dev")
+	viper.SetDefault("server.shutdownTimeoutSeconds", 15)
+	viper.SetDefault("server.drainDelaySeconds", 0)
+	viper.SetDefault("server.readinessTimeoutSeconds", 30)
+	viper.SetDefault("server.health.timeoutMs", 2000)
+	viper.SetDefault("server.health.cacheMs", 1000)
+	viper.SetDefault("server.health.syncIntervalSeconds", 5)
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.filename", "app.log")
 	viper.SetDefault("log.maxsize", 100)
@@ -260,14 +302,47 @@ func setDefaultConfig() {
 	viper.SetDefault("plugins.nacos.namespace", "")
 	viper.SetDefault("plugins.nacos.group", "XI_PLATFORM")
 	viper.SetDefault("plugins.nacos.configEnabled", false)
+	viper.SetDefault("plugins.nacos.configPrecedence", "remote")
+	viper.SetDefault("plugins.nacos.configSnapshotDir", "cache/nacos/snapshot")
+	viper.SetDefault("plugins.nacos.weight", 1.0)
+	viper.SetDefault("plugins.nacos.warmupSeconds", 0)
+	viper.SetDefault("plugins.nacos.warmupSteps", 10)
+	viper.SetDefault("plugins.registry.weight", 1.0)
+	viper.SetDefault("plugins.registry.etcd.endpoints", []string{"127.0.0.1:2379"})
+	viper.SetDefault("plugins.registry.etcd.prefix", "/muyi/services")
+	viper.SetDefault("plugins.registry.etcd.dialTimeoutMs", 5000)
+	viper.SetDefault("plugins.registry.etcd.ttlSeconds", 10)
+	viper.SetDefault("plugins.registry.consul.address", "127.0.0.1:8500")
+	viper.SetDefault("plugins.registry.consul.ttlSeconds", 10)
+	viper.SetDefault("plugins.registry.consul.deregisterAfterSeconds", 60)
+	viper.SetDefault("plugins.registry.file.path", "registry.yaml")
+	viper.SetDefault("plugins.metrics.enabled", false)
+	viper.SetDefault("plugins.metrics.path", "/metrics")
+	viper.SetDefault("plugins.metrics.excludePaths", []string{"/ok", "/health/live", "/health/ready", "/metrics"})
+	viper.SetDefault("plugins.tracing.enabled", false)
+	viper.SetDefault("plugins.tracing.exporter", "otlp")
+	viper.SetDefault("plugins.tracing.excludePaths", []string{"/ok", "/health/live", "/health/ready", "/metrics"})
+	viper.SetDefault("plugins.tracing.otlp.protocol", "grpc")
+	viper.SetDefault("plugins.tracing.otlp.timeoutMs", 10000)
+	viper.SetDefault("plugins.tracing.file.path", "trace.json")
+	viper.SetDefault("plugins.admin.enabled", false)
+	viper.SetDefault("plugins.admin.path", "/admin")
+	viper.SetDefault("plugins.admin.pprof", false)
 	viper.SetDefault("plugins.rpc.enabled", false)
 	viper.SetDefault("plugins.rpc.protocol", "grpc")
 	viper.SetDefault("plugins.rpc.registry", "nacos")
+	viper.SetDefault("plugins.rpc.staticRefreshSeconds", 30)
 	viper.SetDefault("plugins.rpc.server.port", 9080)
 	viper.SetDefault("plugins.rpc.server.maxRecvMsgSize", 4194304)
 	viper.SetDefault("plugins.rpc.server.enableReflection", false)
 	viper.SetDefault("plugins.rpc.client.defaultTimeoutMs", 3000)
 	viper.SetDefault("plugins.rpc.client.maxRetry", 0)
+	viper.SetDefault("plugins.rpc.client.retryableCodes", []string{"UNAVAILABLE"})
+	viper.SetDefault("plugins.rpc.client.retryBackoffMs", 100)
+	viper.SetDefault("plugins.rpc.client.retryMaxBackoffMs", 1000)
+	viper.SetDefault("plugins.rpc.client.breaker.failureThreshold", 5)
+	viper.SetDefault("plugins.rpc.client.breaker.openSeconds", 10)
+	viper.SetDefault("plugins.rpc.client.loadBalancer", "round_robin")
 	viper.SetDefault("locale.enabled", true)
 	viper.SetDefault("locale.default_locale", "zh-CN")
 }
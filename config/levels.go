@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// LogLevels 按命名 logger 覆盖的级别，键为 logger 名称（如 rpc.resolver）。
+// viper 会把带点的键拆成嵌套表，解码时展开回扁平的名称，两种写法等价：
+//
+//	[log.levels]
+//	rpc = "warn"
+//	"rpc.resolver" = "debug"
+//
+//	[log.levels.rpc]
+//	resolver = "debug"
+type LogLevels map[string]string
+
+var logLevelsType = reflect.TypeOf(LogLevels{})
+
+// decodeOption 在 viper 默认解码钩子之上增加 LogLevels 的展开
+var decodeOption = viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+	mapstructure.StringToTimeDurationHookFunc(),
+	mapstructure.StringToSliceHookFunc(","),
+	logLevelsHook,
+))
+
+func logLevelsHook(_ reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != logLevelsType {
+		return data, nil
+	}
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+	out := LogLevels{}
+	flattenLevels("", m, out)
+	return out, nil
+}
+
+func flattenLevels(prefix string, m map[string]interface{}, out LogLevels) {
+	for k, v := range m {
+		name := k
+		if prefix != "" {
+			name = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			flattenLevels(name, val, out)
+		case nil:
+		default:
+			out[name] = fmt.Sprint(val)
+		}
+	}
+}
+
+// unmarshalConfig 解析全部配置。viper.Unmarshal 基于 AllSettings，同时配置 rpc 与 rpc.resolver 时
+// 父级会被子级的嵌套表覆盖，log.levels 因此按原始键重新读取一次
+func unmarshalConfig(target *Config) error {
+	if err := viper.Unmarshal(target, decodeOption); err != nil {
+		return err
+	}
+	target.Log.Levels = nil
+	return viper.UnmarshalKey("log.levels", &target.Log.Levels, decodeOption)
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"github.com/spf13/viper"
+)
+
+func readTOML(t *testing.T, content string) {
+	t.Helper()
+	viper.SetConfigType("toml")
+	if err := viper.ReadConfig(strings.NewReader(content)); err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+}
+
+func TestLogLevelsDottedNames(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	t.Cleanup(func() { _ = myLogger.ApplyLevels("info", nil) })
+
+	readTOML(t, `
+[log]
+level = "info"
+
+[log.levels]
+rpc = "warn"
+"rpc.resolver" = "debug"
+
+[log.levels.db]
+gorm = "error"
+`)
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	want := LogLevels{"rpc": "warn", "rpc.resolver": "debug", "db.gorm": "error"}
+	if got := GlobalConfig.Log.Levels; !reflect.DeepEqual(got, want) {
+		t.Fatalf("levels = %v, want %v", got, want)
+	}
+
+	if err := myLogger.ApplyLevels(GlobalConfig.Log.Level, GlobalConfig.Log.Levels); err != nil {
+		t.Fatalf("ApplyLevels: %v", err)
+	}
+	for name, level := range map[string]string{
+		"rpc":              "warn",
+		"rpc.balancer":     "warn",
+		"rpc.resolver":     "debug",
+		"rpc.resolver.dns": "debug",
+		"db.gorm":          "error",
+		"db":               "info",
+	} {
+		if got := myLogger.NamedLevel(name); got != level {
+			t.Errorf("NamedLevel(%s) = %s, want %s", name, got, level)
+		}
+	}
+
+	// 热更新路径（OnKeyChange 按 log 段解析）得到相同结果
+	var pushed LogConfig
+	if err := OnKeyChange("log", func(cfg LogConfig) { pushed = cfg }); err != nil {
+		t.Fatalf("OnKeyChange: %v", err)
+	}
+	readTOML(t, `
+[log.levels.rpc]
+resolver = "error"
+`)
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if want := (LogLevels{"rpc.resolver": "error"}); !reflect.DeepEqual(pushed.Levels, want) {
+		t.Fatalf("pushed levels = %v, want %v", pushed.Levels, want)
+	}
+}
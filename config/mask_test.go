@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestMaskedSettings(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("redis.password", "r-pass")
+	viper.Set("redis.addr", "127.0.0.1:6379")
+	viper.Set("jwt.key", "sign-key")
+	viper.Set("jwt.expireSeconds", 3600)
+	viper.Set("consul.token", "")
+	viper.Set("plugins.tracing.otlp.headers", map[string]interface{}{"authorization": "Bearer x"})
+	viper.Set("auth.signedJwt.keys", []interface{}{
+		map[string]interface{}{"kid": "k1", "privateKey": "pem"},
+	})
+	viper.Set("datasource.dsn", "postgres://app:p%40ss@db:5432/app")
+	viper.Set("custom.webhook", "https://hooks.example/abc")
+	viper.Set("mysql.dsn", "app:p@ss/w0rd@tcp(db:3306)/app?charset=utf8mb4")
+	viper.Set("mysql.replica", "app@tcp(db:3306)/app")
+	viper.Set("notify.email", "ops@example.com")
+
+	got := MaskedSettings("Webhook")
+	at := func(path ...string) interface{} {
+		var v interface{} = got
+		for _, k := range path {
+			v = v.(map[string]interface{})[k]
+		}
+		return v
+	}
+
+	cases := []struct {
+		path []string
+		want interface{}
+	}{
+		{[]string{"redis", "password"}, MaskedValue},
+		{[]string{"redis", "addr"}, "127.0.0.1:6379"},
+		{[]string{"jwt", "key"}, MaskedValue},
+		{[]string{"jwt", "expireseconds"}, 3600},
+		{[]string{"consul", "token"}, ""},
+		{[]string{"plugins", "tracing", "otlp", "headers", "authorization"}, MaskedValue},
+		{[]string{"datasource", "dsn"}, "postgres://app:******@db:5432/app"},
+		{[]string{"custom", "webhook"}, MaskedValue},
+		{[]string{"mysql", "dsn"}, "app:******@tcp(db:3306)/app?charset=utf8mb4"},
+		{[]string{"mysql", "replica"}, "app@tcp(db:3306)/app"},
+		{[]string{"notify", "email"}, "ops@example.com"},
+	}
+	for _, tc := range cases {
+		if v := at(tc.path...); v != tc.want {
+			t.Errorf("%v = %v, want %v", tc.path, v, tc.want)
+		}
+	}
+
+	key := at("auth", "signedjwt", "keys").([]interface{})[0].(map[string]interface{})
+	if key["kid"] != "k1" || key["privateKey"] != MaskedValue {
+		t.Errorf("keys[0] = %v", key)
+	}
+	if viper.GetString("redis.password") != "r-pass" {
+		t.Error("脱敏不应修改生效配置")
+	}
+}
@@ -2,45 +2,263 @@ package config
 
 // PluginsConfig 可插拔基础设施配置
 type PluginsConfig struct {
-	Nacos NacosConfig `mapstructure:"nacos"`
-	Rpc   RpcConfig   `mapstructure:"rpc"`
+	Nacos    NacosConfig    `mapstructure:"nacos"`
+	Registry RegistryConfig `mapstructure:"registry"`
+	Rpc      RpcConfig      `mapstructure:"rpc"`
+	Metrics  MetricsConfig  `mapstructure:"metrics"`
+	Tracing  TracingConfig  `mapstructure:"tracing"`
+	Admin    AdminConfig    `mapstructure:"admin"`
+}
+
+// AdminConfig 运行时管理接口（生效配置、路由、连接池、日志级别等），
+// 须配置 token 或 permission 之一才会挂载
+type AdminConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Path 路由组前缀，默认 /admin
+	Path string `mapstructure:"path"`
+	// Token 静态令牌，请求头 x-admin-token 或 Authorization: Bearer 携带
+	Token string `mapstructure:"token"`
+	// Permission 未配置 token 时使用 myAuth 鉴权并要求该权限码，须在 Run 之前初始化 myAuth
+	Permission string `mapstructure:"permission"`
+	// Pprof 在 {path}/debug/pprof 下暴露 pprof
+	Pprof bool `mapstructure:"pprof"`
+	// MaskKeys 额外需要脱敏的配置键（不区分大小写，按键名后缀匹配）
+	MaskKeys []string `mapstructure:"maskKeys"`
+}
+
+// MetricsConfig Prometheus 指标，启用后在 HTTP 端口暴露 Path
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
+	// Namespace 指标名前缀，如 muyi → muyi_http_server_requests_total
+	Namespace string `mapstructure:"namespace"`
+	// Buckets 耗时直方图分桶（秒），默认 prometheus.DefBuckets
+	Buckets []float64 `mapstructure:"buckets"`
+	// ExcludePaths 不统计的路由（如 /ok、/metrics），按路由模板匹配
+	ExcludePaths []string `mapstructure:"excludePaths"`
+}
+
+// TracingConfig OpenTelemetry 链路追踪
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ServiceName 上报的 service.name，默认沿用 plugins.nacos.serviceName，再退回 appName
+	ServiceName string `mapstructure:"serviceName"`
+	// Exporter otlp / file / stdout / none（仅生成 traceId 与透传，不上报）
+	Exporter string `mapstructure:"exporter"`
+	// SampleRatio 根 Span 采样比例 0-1，默认 1；有上游 traceparent 时跟随上游决定
+	SampleRatio *float64 `mapstructure:"sampleRatio"`
+	// ExcludePaths 不创建 Span 的路由（如 /ok、/metrics），按路由模板匹配
+	ExcludePaths []string        `mapstructure:"excludePaths"`
+	OTLP         OTLPConfig      `mapstructure:"otlp"`
+	File         TraceFileConfig `mapstructure:"file"`
+}
+
+// OTLPConfig OTLP 上报
+type OTLPConfig struct {
+	// Endpoint host:port，grpc 默认 127.0.0.1:4317，http 默认 127.0.0.1:4318
+	Endpoint string `mapstructure:"endpoint"`
+	// Protocol grpc（默认）或 http
+	Protocol string `mapstructure:"protocol"`
+	// Insecure 不使用 TLS
+	Insecure bool              `mapstructure:"insecure"`
+	Headers  map[string]string `mapstructure:"headers"`
+	// TimeoutMs 单次上报超时，默认 10000
+	TimeoutMs int `mapstructure:"timeoutMs"`
+}
+
+// TraceFileConfig file 导出，每个 Span 一行 JSON，便于离线排查
+type TraceFileConfig struct {
+	Path string `mapstructure:"path"`
 }
 
 // NacosConfig Nacos 注册与配置中心
 type NacosConfig struct {
-	Enabled       bool   `mapstructure:"enabled"`
-	ServerAddr    string `mapstructure:"serverAddr"`
-	Namespace     string `mapstructure:"namespace"`
-	Group         string `mapstructure:"group"`
-	ServiceName   string `mapstructure:"serviceName"`
-	ConfigEnabled bool   `mapstructure:"configEnabled"`
-	ConfigDataId  string `mapstructure:"configDataId"`
-	Username      string `mapstructure:"username"`
-	Password      string `mapstructure:"password"`
+	Enabled     bool   `mapstructure:"enabled"`
+	ServerAddr  string `mapstructure:"serverAddr"`
+	Namespace   string `mapstructure:"namespace"`
+	Group       string `mapstructure:"group"`
+	ServiceName string `mapstructure:"serviceName"`
+	// ConfigEnabled 启用配置中心，与 Enabled（注册发现）相互独立
+	ConfigEnabled bool `mapstructure:"configEnabled"`
+	// ConfigDataId 单个 toml dataId（兼容旧配置），拉取不到时不阻断启动；Configs 非空时忽略
+	ConfigDataId string `mapstructure:"configDataId"`
+	// Configs 按顺序加载的 dataId，后加载的覆盖先加载的
+	Configs []NacosConfigSource `mapstructure:"configs"`
+	// ConfigPrecedence remote（默认，远程覆盖本地文件）或 local（本地文件覆盖远程）
+	ConfigPrecedence string `mapstructure:"configPrecedence"`
+	// ConfigSnapshotDir 远程配置的本地快照目录，Nacos 不可用时从快照启动
+	ConfigSnapshotDir string `mapstructure:"configSnapshotDir"`
+	Username          string `mapstructure:"username"`
+	Password          string `mapstructure:"password"`
+	// Weight 实例权重，默认 1
+	Weight float64 `mapstructure:"weight"`
+	// WarmupSeconds 注册后权重从低到 Weight 逐步爬升的时长，0 表示不预热
+	WarmupSeconds int `mapstructure:"warmupSeconds"`
+	// WarmupSteps 预热分几次调整权重，默认 10
+	WarmupSteps int `mapstructure:"warmupSteps"`
+	// RegisterHTTP 另以 HTTPServiceName 注册 HTTP 端口实例，供 rpc.HTTPClient 发现
+	RegisterHTTP bool `mapstructure:"registerHttp"`
+	// HTTPServiceName 默认 serviceName + "-http"
+	HTTPServiceName string `mapstructure:"httpServiceName"`
+}
+
+// NacosConfigSource 配置中心的一个 dataId
+type NacosConfigSource struct {
+	DataId string `mapstructure:"dataId"`
+	// Group 默认 plugins.nacos.group
+	Group string `mapstructure:"group"`
+	// Format toml / yaml / json / properties，默认按 dataId 扩展名推断，无法推断时为 toml
+	Format string `mapstructure:"format"`
+	// Optional 为 true 时远程与快照都取不到也继续启动
+	Optional bool `mapstructure:"optional"`
+}
+
+// RegistryConfig etcd / consul / file 注册中心，由 plugins.rpc.registry 选择（nacos 使用 plugins.nacos）
+type RegistryConfig struct {
+	// ServiceName 默认沿用 plugins.nacos.serviceName，再退回 appName
+	ServiceName string `mapstructure:"serviceName"`
+	// RegisterHTTP 另以 HTTPServiceName 注册 HTTP 端口实例
+	RegisterHTTP bool `mapstructure:"registerHttp"`
+	// HTTPServiceName 默认 serviceName + "-http"
+	HTTPServiceName string `mapstructure:"httpServiceName"`
+	// Weight 实例权重，默认 1
+	Weight float64              `mapstructure:"weight"`
+	Etcd   EtcdRegistryConfig   `mapstructure:"etcd"`
+	Consul ConsulRegistryConfig `mapstructure:"consul"`
+	File   FileRegistryConfig   `mapstructure:"file"`
+}
+
+// EtcdRegistryConfig 实例写入 {prefix}/{group}/{serviceName}/{ip:port}，随租约过期自动摘除
+type EtcdRegistryConfig struct {
+	Endpoints     []string `mapstructure:"endpoints"`
+	Prefix        string   `mapstructure:"prefix"`
+	Username      string   `mapstructure:"username"`
+	Password      string   `mapstructure:"password"`
+	DialTimeoutMs int      `mapstructure:"dialTimeoutMs"`
+	// TTLSeconds 租约时长，进程异常退出后实例在该时间后失效，默认 10
+	TTLSeconds int `mapstructure:"ttlSeconds"`
+}
+
+// ConsulRegistryConfig 实例以 group 作为 tag 注册，TTL 健康检查由本进程心跳维持
+type ConsulRegistryConfig struct {
+	Address    string `mapstructure:"address"`
+	Token      string `mapstructure:"token"`
+	Datacenter string `mapstructure:"datacenter"`
+	// TTLSeconds 健康检查 TTL，默认 10
+	TTLSeconds int `mapstructure:"ttlSeconds"`
+	// DeregisterAfterSeconds 检查持续失败多久后由 Consul 注销实例，默认 60
+	DeregisterAfterSeconds int `mapstructure:"deregisterAfterSeconds"`
+}
+
+// FileRegistryConfig 本地多服务联调使用的 YAML 实例清单，文件变更自动重新加载；只读，不注册本实例
+type FileRegistryConfig struct {
+	Path string `mapstructure:"path"`
 }
 
 // RpcConfig gRPC 服务暴露与消费
 type RpcConfig struct {
-	Enabled  bool              `mapstructure:"enabled"`
-	Protocol string            `mapstructure:"protocol"`
-	Registry string            `mapstructure:"registry"`
-	Server   RpcServerConfig   `mapstructure:"server"`
-	Client   RpcClientConfig   `mapstructure:"client"`
-	Static   map[string]string `mapstructure:"static"`
+	Enabled  bool   `mapstructure:"enabled"`
+	Protocol string `mapstructure:"protocol"`
+	// Registry 注册中心：nacos / etcd / consul / file / static
+	Registry string          `mapstructure:"registry"`
+	Server   RpcServerConfig `mapstructure:"server"`
+	Client   RpcClientConfig `mapstructure:"client"`
+	// Static serviceKey → 地址，逗号分隔多个（host:port），支持热更新
+	Static map[string]string `mapstructure:"static"`
+	// StaticRefreshSeconds static 地址含主机名时重新解析 DNS 的间隔，默认 30
+	StaticRefreshSeconds int `mapstructure:"staticRefreshSeconds"`
 }
 
 // RpcServerConfig gRPC Server 配置
 type RpcServerConfig struct {
-	Port             int  `mapstructure:"port"`
-	MaxRecvMsgSize   int  `mapstructure:"maxRecvMsgSize"`
-	EnableReflection bool `mapstructure:"enableReflection"`
+	Port             int          `mapstructure:"port"`
+	MaxRecvMsgSize   int          `mapstructure:"maxRecvMsgSize"`
+	EnableReflection bool         `mapstructure:"enableReflection"`
+	TLS              RpcTLSConfig `mapstructure:"tls"`
 }
 
 // RpcClientConfig gRPC Client 配置
 type RpcClientConfig struct {
-	DefaultTimeoutMs int               `mapstructure:"defaultTimeoutMs"`
-	MaxRetry         int               `mapstructure:"maxRetry"`
-	Services         map[string]string `mapstructure:"services"`
+	DefaultTimeoutMs int `mapstructure:"defaultTimeoutMs"`
+	// MaxRetry 幂等方法失败后的最大重试次数（不含首次调用）
+	MaxRetry int `mapstructure:"maxRetry"`
+	// RetryableCodes 可重试的 gRPC 状态码名称，默认 ["UNAVAILABLE"]
+	RetryableCodes    []string          `mapstructure:"retryableCodes"`
+	RetryBackoffMs    int               `mapstructure:"retryBackoffMs"`
+	RetryMaxBackoffMs int               `mapstructure:"retryMaxBackoffMs"`
+	Methods           []RpcMethodConfig `mapstructure:"methods"`
+	Breaker           RpcBreakerConfig  `mapstructure:"breaker"`
+	// LoadBalancer 默认负载均衡策略：round_robin / weighted_round_robin / consistent_hash / least_request
+	LoadBalancer string `mapstructure:"loadBalancer"`
+	// Routing 按 serviceKey 覆盖负载均衡与元数据路由
+	Routing  map[string]RpcRoutingConfig `mapstructure:"routing"`
+	Services map[string]string           `mapstructure:"services"`
+	TLS      RpcClientTLSConfig          `mapstructure:"tls"`
+	HTTP     RpcHTTPClientConfig         `mapstructure:"http"`
+}
+
+// RpcHTTPClientConfig rpc.HTTPClient 配置
+type RpcHTTPClientConfig struct {
+	// TimeoutMs 默认沿用 defaultTimeoutMs
+	TimeoutMs int `mapstructure:"timeoutMs"`
+	// Services serviceKey → 注册中心中的 HTTP 服务名，默认为 gRPC 服务名 + "-http"
+	Services map[string]string `mapstructure:"services"`
+	// Static serviceKey → 基础地址（如 http://127.0.0.1:8081），逗号分隔多个；配置后不走注册中心
+	Static map[string]string `mapstructure:"static"`
+}
+
+// RpcRoutingConfig 单个服务的负载均衡与实例路由
+type RpcRoutingConfig struct {
+	LoadBalancer string `mapstructure:"loadBalancer"`
+	// HashKey consistent_hash 使用的 outgoing metadata key（如 x-sso-id）
+	HashKey string `mapstructure:"hashKey"`
+	// Tags 仅路由到元数据全部匹配的实例（如 version = "v2"、zone = "cn-a"）
+	Tags map[string]string `mapstructure:"tags"`
+	// TagFallback 无匹配实例时退回全部实例
+	TagFallback bool `mapstructure:"tagFallback"`
+}
+
+// RpcMethodConfig 按服务 / 方法覆盖超时与重试，按配置顺序首个匹配生效
+type RpcMethodConfig struct {
+	// Service serviceKey，为空匹配全部服务
+	Service string `mapstructure:"service"`
+	// Method 全方法名，支持 /pkg.Service/* 通配，为空匹配全部方法
+	Method    string `mapstructure:"method"`
+	TimeoutMs int    `mapstructure:"timeoutMs"`
+	MaxRetry  int    `mapstructure:"maxRetry"`
+	// Idempotent 仅幂等方法会重试
+	Idempotent bool `mapstructure:"idempotent"`
+}
+
+// RpcBreakerConfig 按 serviceKey 的熔断器
+type RpcBreakerConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// FailureThreshold 连续失败次数达到阈值后熔断，默认 5
+	FailureThreshold int `mapstructure:"failureThreshold"`
+	// OpenSeconds 熔断持续时间，到期后放行一个探测请求，默认 10 秒
+	OpenSeconds int `mapstructure:"openSeconds"`
+}
+
+// RpcTLSConfig gRPC TLS / mTLS 配置，证书文件变更后自动重新加载
+type RpcTLSConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"certFile"`
+	KeyFile  string `mapstructure:"keyFile"`
+	// CAFile Server 侧用于校验客户端证书，Client 侧用于校验服务端证书（为空使用系统根证书）
+	CAFile string `mapstructure:"caFile"`
+	// ClientAuth 仅 Server：none / request / require，配置 CAFile 时默认 require
+	ClientAuth string `mapstructure:"clientAuth"`
+	// ServerName 仅 Client：覆盖证书校验使用的服务端名称
+	ServerName         string `mapstructure:"serverName"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+	// ReloadIntervalSeconds 检查证书文件变更的最小间隔，默认 30 秒
+	ReloadIntervalSeconds int `mapstructure:"reloadIntervalSeconds"`
+}
+
+// RpcClientTLSConfig Client TLS 配置，Services 按 serviceKey 覆盖默认值
+type RpcClientTLSConfig struct {
+	RpcTLSConfig `mapstructure:",squash"`
+	Services     map[string]RpcTLSConfig `mapstructure:"services"`
 }
 
 // GetPluginsConfig 获取插件配置
@@ -58,7 +276,27 @@ func GetNacosConfig() NacosConfig {
 	return GetPluginsConfig().Nacos
 }
 
+// GetRegistryConfig 获取 etcd / consul / file 注册中心配置
+func GetRegistryConfig() RegistryConfig {
+	return GetPluginsConfig().Registry
+}
+
 // GetRpcConfig 获取 RPC 配置
 func GetRpcConfig() RpcConfig {
 	return GetPluginsConfig().Rpc
 }
+
+// GetMetricsConfig 获取指标配置
+func GetMetricsConfig() MetricsConfig {
+	return GetPluginsConfig().Metrics
+}
+
+// GetTracingConfig 获取链路追踪配置
+func GetTracingConfig() TracingConfig {
+	return GetPluginsConfig().Tracing
+}
+
+// GetAdminConfig 获取管理接口配置
+func GetAdminConfig() AdminConfig {
+	return GetPluginsConfig().Admin
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// 远程配置层（如 Nacos 的多个 dataId）与本地配置文件的合并：
+// 每次重建时 viper 的配置层按「本地文件 → 远程层（按首次设置顺序）」合并，
+// precedence 为 local 时本地文件最后合并、覆盖远程同名配置。
+
+const (
+	// PrecedenceRemote 远程配置覆盖本地文件（默认）
+	PrecedenceRemote = "remote"
+	// PrecedenceLocal 本地文件覆盖远程配置
+	PrecedenceLocal = "local"
+)
+
+type remoteLayer struct {
+	name     string
+	settings map[string]interface{}
+}
+
+var (
+	remoteLayers     []remoteLayer
+	remotePrecedence = PrecedenceRemote
+	remoteMu         sync.Mutex
+	// rebuildMu 串行化合并与 Reload，避免并发推送时回调看到乱序的配置
+	rebuildMu sync.Mutex
+)
+
+// SetRemotePrecedence 设置远程配置与本地文件的优先级，下次 Rebuild 生效
+func SetRemotePrecedence(precedence string) {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	if strings.EqualFold(precedence, PrecedenceLocal) {
+		remotePrecedence = PrecedenceLocal
+	} else {
+		remotePrecedence = PrecedenceRemote
+	}
+}
+
+// SetRemoteLayer 新增或替换名为 name 的远程配置层，下次 Rebuild 生效；新层排在已有层之后（优先级更高）
+func SetRemoteLayer(name string, settings map[string]interface{}) {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	for i := range remoteLayers {
+		if remoteLayers[i].name == name {
+			remoteLayers[i].settings = settings
+			return
+		}
+	}
+	remoteLayers = append(remoteLayers, remoteLayer{name: name, settings: settings})
+}
+
+// UpdateRemoteLayer 替换远程配置层并立即重建配置、通知 OnChange 回调
+func UpdateRemoteLayer(name string, settings map[string]interface{}) error {
+	SetRemoteLayer(name, settings)
+	return Rebuild()
+}
+
+// Rebuild 按本地文件与远程层重新合并 viper 配置，随后 Reload；
+// 远程层删除的配置项会随之消失，不会残留上一次合并的值
+func Rebuild() error {
+	rebuildMu.Lock()
+	defer rebuildMu.Unlock()
+	if err := mergeLayers(); err != nil {
+		return err
+	}
+	return Reload()
+}
+
+func mergeLayers() error {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+
+	local, err := readLocalSettings()
+	if err != nil {
+		return err
+	}
+	// 清空配置层（默认值、环境变量等其他层不受影响）
+	if err := viper.ReadConfig(strings.NewReader("")); err != nil {
+		return errors.Wrap(err, "重置配置失败")
+	}
+	if remotePrecedence == PrecedenceRemote {
+		if err := viper.MergeConfigMap(local); err != nil {
+			return errors.Wrap(err, "合并本地配置失败")
+		}
+	}
+	for _, layer := range remoteLayers {
+		// viper 合并时直接引用源 map 的嵌套对象，传入副本以免后续层改写该层保存的配置
+		if err := viper.MergeConfigMap(cloneSettings(layer.settings)); err != nil {
+			return errors.Wrapf(err, "合并远程配置 %s 失败", layer.name)
+		}
+	}
+	if remotePrecedence == PrecedenceLocal {
+		if err := viper.MergeConfigMap(local); err != nil {
+			return errors.Wrap(err, "合并本地配置失败")
+		}
+	}
+	return nil
+}
+
+func cloneSettings(settings map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(settings))
+	for k, v := range settings {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = cloneSettings(m)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// readLocalSettings 读取当前使用的本地配置文件，未使用配置文件时返回空
+func readLocalSettings() (map[string]interface{}, error) {
+	file := viper.ConfigFileUsed()
+	if file == "" {
+		return map[string]interface{}{}, nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "读取配置文件 %s 失败", file)
+	}
+	settings, err := ParseSettings(string(content), FormatOf(file))
+	if err != nil {
+		return nil, errors.Wrapf(err, "解析配置文件 %s 失败", file)
+	}
+	return settings, nil
+}
+
+// FormatOf 按文件名（或 Nacos dataId）扩展名推断格式，无法识别时为 toml（如 app-dev.conf）
+func FormatOf(name string) string {
+	format, err := NormalizeFormat(strings.TrimPrefix(filepath.Ext(name), "."))
+	if err != nil {
+		return "toml"
+	}
+	return format
+}
+
+// NormalizeFormat 校验并规范化配置格式：toml / yaml / json / properties
+func NormalizeFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "toml":
+		return "toml", nil
+	case "yaml", "yml":
+		return "yaml", nil
+	case "json":
+		return "json", nil
+	case "properties", "props", "prop":
+		return "properties", nil
+	default:
+		return "", errors.Errorf("不支持的配置格式 %q", format)
+	}
+}
+
+// ParseSettings 按格式解析配置内容为嵌套 map（key 为小写），可直接用于 SetRemoteLayer
+func ParseSettings(content, format string) (map[string]interface{}, error) {
+	format, err := NormalizeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	v := viper.New()
+	v.SetConfigType(format)
+	if err := v.ReadConfig(strings.NewReader(content)); err != nil {
+		return nil, errors.Wrapf(err, "解析 %s 配置失败", format)
+	}
+	return v.AllSettings(), nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// ChangeListener 配置热更新回调，oldCfg 为更新前的配置（可能为 nil）
+type ChangeListener func(oldCfg, newCfg *Config)
+
+var (
+	changeListeners   []ChangeListener
+	changeListenersMu sync.Mutex
+)
+
+// OnChange 注册配置热更新回调（配置文件变更、Nacos 配置推送后触发）
+func OnChange(listener ChangeListener) {
+	changeListenersMu.Lock()
+	defer changeListenersMu.Unlock()
+	changeListeners = append(changeListeners, listener)
+}
+
+// Reload 从 viper 重新解析 GlobalConfig 并通知 OnChange 回调
+func Reload() error {
+	tempConfig := &Config{}
+	if err := unmarshalConfig(tempConfig); err != nil {
+		return errors.Wrap(err, "重新加载配置失败")
+	}
+	configMutex.Lock()
+	oldConfig := GlobalConfig
+	GlobalConfig = tempConfig
+	configMutex.Unlock()
+
+	changeListenersMu.Lock()
+	listeners := append([]ChangeListener(nil), changeListeners...)
+	changeListenersMu.Unlock()
+	for _, listener := range listeners {
+		listener(oldConfig, tempConfig)
+	}
+	return nil
+}
+
+// OnKeyChange 订阅 key 对应的配置段（如 "plugins.rpc.client"），解析为 T 后与上次比较，有变化时回调新值
+func OnKeyChange[T any](key string, listener func(value T)) error {
+	var last T
+	if err := viper.UnmarshalKey(key, &last, decodeOption); err != nil {
+		return errors.Wrapf(err, "解析配置 %s 失败", key)
+	}
+	var mu sync.Mutex
+	OnChange(func(_, _ *Config) {
+		var current T
+		if err := viper.UnmarshalKey(key, &current, decodeOption); err != nil {
+			return
+		}
+		mu.Lock()
+		changed := !reflect.DeepEqual(last, current)
+		last = current
+		mu.Unlock()
+		if changed {
+			listener(current)
+		}
+	})
+	return nil
+}
@@ -1,9 +1,13 @@
 package core
 
 import (
+	"context"
 	"fmt"
+	"time"
+
 	"github.com/muyi-zcy/tech-muyi-base-go/config"
 	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/tracing"
 	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -103,6 +107,13 @@ func (a *App) Shutdown() error {
 		return errors.Wrap(err, "关闭Redis连接失败")
 	}
 
+	// 上报剩余 Span
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracing.Shutdown(ctx); err != nil {
+		myLogger.Warn("链路追踪关闭失败", zap.Error(err))
+	}
+
 	return nil
 }
 
@@ -7,9 +7,9 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
-	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/nacos"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/health"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
 	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc"
 	"github.com/muyi-zcy/tech-muyi-base-go/middleware"
 	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
@@ -59,11 +59,13 @@ func (s *Starter) RunWithOptions(opts RunOptions) error {
 		if err := rpcMgr.Start(ctx, lis); err != nil {
 			return err
 		}
-		if reg := nacos.GetRegistry(); reg.Enabled() {
-			if err := reg.Register(ctx, rpcMgr.GrpcPort(), nil); err != nil {
-				myLogger.Warn("Nacos 注册失败，服务继续运行", zap.Error(err))
-			}
-		}
+	}
+
+	registering := rpcMgr.Enabled() || registry.RegisterHTTPEnabled(s.App.Config)
+	s.registerRuntimeChecks(rpcMgr, registering)
+	stopHealthSync := make(chan struct{})
+	if rpcMgr.Enabled() {
+		go s.syncGrpcHealth(rpcMgr, stopHealthSync)
 	}
 
 	go func() {
@@ -76,21 +78,43 @@ func (s *Starter) RunWithOptions(opts RunOptions) error {
 		}
 	}()
 
+	// 就绪检查通过后再注册，避免未预热实例接收流量
+	if registering {
+		if err := s.waitReady(ctx); err != nil {
+			return err
+		}
+		if reg := registry.GetRegistry(); reg.Enabled() {
+			if err := reg.Register(ctx, rpcMgr.GrpcPort(), nil); err != nil {
+				myLogger.Warn("服务注册失败，服务继续运行", zap.Error(err))
+			} else {
+				s.registered.Store(true)
+			}
+		}
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	myLogger.Info("收到退出信号，开始优雅关闭...")
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 15*time.Second)
+	// 注销、drain 与停止 HTTP/gRPC 共用一个截止时间，总耗时不超过 shutdownTimeoutSeconds
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), s.shutdownTimeout())
 	defer shutdownCancel()
-
-	if reg := nacos.GetRegistry(); reg.Enabled() {
+	// 先将就绪状态置为 DOWN，探针与 gRPC 健康检查在 drain 期间即可摘流
+	health.SetShuttingDown()
+	close(stopHealthSync)
+	if rpcMgr.Enabled() {
+		rpcMgr.SetServingStatus(false)
+	}
+	if reg := registry.GetRegistry(); reg.Enabled() {
 		if err := reg.Deregister(shutdownCtx); err != nil {
-			myLogger.Warn("Nacos 注销失败", zap.Error(err))
+			myLogger.Warn("服务注销失败", zap.Error(err))
 		}
+		s.registered.Store(false)
+		s.drain(shutdownCtx)
 	}
+
 	if rpcMgr.Enabled() {
-		rpcMgr.GracefulStop()
 		if err := rpcMgr.Shutdown(shutdownCtx); err != nil {
 			myLogger.Warn("RPC 关闭失败", zap.Error(err))
 		}
@@ -1,7 +1,12 @@
 package core
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/health"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc"
+	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
 )
 
 type HealthCheckController struct{}
@@ -10,10 +15,45 @@ func NewHealthCheckController() *HealthCheckController {
 	return &HealthCheckController{}
 }
 
+// Ok 兼容旧探针，仅表示进程存活（同 /health/live）
 func (h *HealthCheckController) Ok(c *gin.Context) {
 	c.String(200, "ok")
 }
 
+// Live 存活探针：进程能处理请求即 UP，不检查依赖，避免依赖抖动导致容器被重启
+func (h *HealthCheckController) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":        health.StatusUp,
+		"uptimeSeconds": int64(health.Uptime().Seconds()),
+	})
+}
+
+// Ready 就绪探针：关键检查全部通过为 200，否则 503，body 为各项检查详情
+func (h *HealthCheckController) Ready(c *gin.Context) {
+	report := health.Ready(c.Request.Context())
+	status := http.StatusOK
+	if report.Status != health.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// Health 健康详情（统一返回结构）；RPC 启用时附带各下游服务的熔断器状态
+func (h *HealthCheckController) Health(c *gin.Context) {
+	report := health.Ready(c.Request.Context())
+	detail := gin.H{"status": report.Status, "checks": report.Checks}
+	if report.Reason != "" {
+		detail["reason"] = report.Reason
+	}
+	if mgr := rpc.GetManager(); mgr.Enabled() {
+		detail["rpc"] = gin.H{"breakers": mgr.Client().BreakerStates()}
+	}
+	myResult.Success(c, detail)
+}
+
 func RegisterHealthCheckRoutes(engine *gin.Engine, controller *HealthCheckController) {
 	engine.GET("/ok", controller.Ok)
+	engine.GET("/health", controller.Health)
+	engine.GET("/health/live", controller.Live)
+	engine.GET("/health/ready", controller.Ready)
 }
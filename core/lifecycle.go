@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/health"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc"
+	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultShutdownTimeout    = 15 * time.Second
+	defaultReadinessTimeout   = 30 * time.Second
+	readinessPollInterval     = 500 * time.Millisecond
+	defaultHealthSyncInterval = 5 * time.Second
+)
+
+// ReadinessCheck 注册中心注册前的就绪检查（缓存预热、依赖连通等），返回 nil 表示就绪
+type ReadinessCheck func(ctx context.Context) error
+
+type namedReadinessCheck struct {
+	name  string
+	check ReadinessCheck
+}
+
+// AddReadinessCheck 添加就绪检查，须在 Run 之前调用；全部通过后才向 Nacos 注册实例，
+// 运行期同样计入 /health/ready
+func (s *Starter) AddReadinessCheck(name string, check ReadinessCheck) {
+	if check == nil {
+		return
+	}
+	s.readinessChecks = append(s.readinessChecks, namedReadinessCheck{name: name, check: check})
+	health.Register(name, health.Check(check))
+}
+
+// AddHealthCheck 添加运行期依赖检查（/health/ready），不影响启动时的注册时机
+func (s *Starter) AddHealthCheck(name string, check health.Check, opts ...health.Option) {
+	health.Register(name, check, opts...)
+}
+
+// registerRuntimeChecks Server 启动后注册 gRPC 与注册中心检查
+func (s *Starter) registerRuntimeChecks(rpcMgr rpc.Manager, registering bool) {
+	if rpcMgr.Enabled() {
+		health.Register("grpc", func(ctx context.Context) error {
+			if !rpcMgr.Running() {
+				return errors.New("gRPC Server 未在运行")
+			}
+			return nil
+		})
+	}
+	if registering && registry.GetRegistry().Enabled() {
+		// 注册失败时服务仍继续运行（直连、static 调用不受影响），故不影响就绪状态
+		health.Register("registry", func(ctx context.Context) error {
+			if !s.registered.Load() {
+				return errors.New("实例未注册到注册中心")
+			}
+			return nil
+		}, health.NonCritical(), health.WithCacheTTL(0))
+	}
+}
+
+// syncGrpcHealth 按就绪检查结果同步 gRPC 健康服务状态，直至 stop 关闭
+func (s *Starter) syncGrpcHealth(rpcMgr rpc.Manager, stop <-chan struct{}) {
+	interval := defaultHealthSyncInterval
+	if s.App.Config != nil && s.App.Config.Server.Health.SyncIntervalSeconds > 0 {
+		interval = time.Duration(s.App.Config.Server.Health.SyncIntervalSeconds) * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	serving := false
+	for {
+		report := health.Ready(context.Background())
+		if next := report.Status == health.StatusUp; next != serving && !health.ShuttingDown() {
+			serving = next
+			rpcMgr.SetServingStatus(serving)
+			myLogger.Info("gRPC 健康状态已同步", zap.Bool("serving", serving), zap.String("reason", report.Reason))
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitReady 轮询就绪检查直至全部通过，超过 server.readinessTimeoutSeconds 返回最后一次失败原因
+func (s *Starter) waitReady(ctx context.Context) error {
+	if len(s.readinessChecks) == 0 {
+		return nil
+	}
+	timeout := defaultReadinessTimeout
+	if s.App.Config != nil && s.App.Config.Server.ReadinessTimeoutSeconds > 0 {
+		timeout = time.Duration(s.App.Config.Server.ReadinessTimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+	for {
+		name, err := s.runReadinessChecks(ctx)
+		if err == nil {
+			myLogger.Info("就绪检查通过", zap.Int("checks", len(s.readinessChecks)))
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(err, "就绪检查 %s 在 %s 内未通过", name, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Starter) runReadinessChecks(ctx context.Context) (string, error) {
+	for _, c := range s.readinessChecks {
+		if err := c.check(ctx); err != nil {
+			return c.name, err
+		}
+	}
+	return "", nil
+}
+
+func (s *Starter) shutdownTimeout() time.Duration {
+	if s.App.Config != nil && s.App.Config.Server.ShutdownTimeoutSeconds > 0 {
+		return time.Duration(s.App.Config.Server.ShutdownTimeoutSeconds) * time.Second
+	}
+	return defaultShutdownTimeout
+}
+
+// drain 注销后等待调用方刷新实例缓存，期间仍正常处理请求；最晚等到关闭截止时间
+func (s *Starter) drain(ctx context.Context) {
+	if s.App.Config == nil || s.App.Config.Server.DrainDelaySeconds <= 0 {
+		return
+	}
+	delay := time.Duration(s.App.Config.Server.DrainDelaySeconds) * time.Second
+	myLogger.Info("等待流量摘除", zap.Duration("drainDelay", delay))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		myLogger.Warn("drain 超过关闭截止时间，提前结束", zap.Duration("drainDelay", delay))
+	}
+}
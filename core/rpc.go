@@ -2,6 +2,7 @@ package core
 
 import (
 	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/nacos"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
 	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc"
 )
 
@@ -20,6 +21,11 @@ func (s *Starter) GetNacosRegistry() nacos.Registry {
 	return nacos.GetRegistry()
 }
 
+// GetRegistry 获取 plugins.rpc.registry 选择的注册中心
+func (s *Starter) GetRegistry() registry.Registry {
+	return registry.GetRegistry()
+}
+
 // RunWithGrpc 启动并注册 gRPC 服务的便捷方法
 func (s *Starter) RunWithGrpc(registrars ...rpc.ServiceRegistrar) error {
 	return s.RunWithOptions(RunOptions{RegisterGrpc: registrars})
@@ -1,12 +1,20 @@
 package core
 
 import (
+	"sync/atomic"
+
 	"github.com/muyi-zcy/tech-muyi-base-go/config"
 	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/admin"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/health"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/metrics"
 	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/nacos"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
 	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/tracing"
 	"github.com/muyi-zcy/tech-muyi-base-go/middleware"
 	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"github.com/muyi-zcy/tech-muyi-base-go/myFeature"
 	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
 	"github.com/pkg/errors"
 
@@ -18,6 +26,9 @@ import (
 type Starter struct {
 	App    *App
 	Engine *gin.Engine
+
+	readinessChecks []namedReadinessCheck
+	registered      atomic.Bool
 }
 
 // NewStarter 创建应用启动器
@@ -89,6 +100,13 @@ func (s *Starter) InitializeLogger() error {
 		MaxBackups: s.App.Config.Log.MaxBackups,
 		Compress:   s.App.Config.Log.Compress,
 		Stdout:     s.App.Config.Log.Stdout,
+		Levels:     s.App.Config.Log.Levels,
+		Sampling: myLogger.SamplingConfig{
+			Initial:     s.App.Config.Log.Sampling.Initial,
+			Thereafter:  s.App.Config.Log.Sampling.Thereafter,
+			TickSeconds: s.App.Config.Log.Sampling.TickSeconds,
+		},
+		Redact: redactConfig(s.App.Config.Log.Redact),
 	}
 
 	if err := myLogger.InitWithConfig(logConfig); err != nil {
@@ -96,13 +114,38 @@ func (s *Starter) InitializeLogger() error {
 		return myLogger.Init()
 	}
 
+	middleware.SetSkipBodyPaths(s.App.Config.Log.Redact.SkipBodyPaths)
+
+	// log.level / log.levels / log.redact 随配置文件与 Nacos 推送热更新
+	if err := config.OnKeyChange("log", func(logCfg config.LogConfig) {
+		if err := myLogger.SetRedaction(redactConfig(logCfg.Redact)); err != nil {
+			myLogger.Warn("日志脱敏热更新失败，保持原规则", zap.Error(err))
+		}
+		middleware.SetSkipBodyPaths(logCfg.Redact.SkipBodyPaths)
+		if err := myLogger.ApplyLevels(logCfg.Level, logCfg.Levels); err != nil {
+			myLogger.Warn("日志级别热更新失败，保持原级别", zap.Error(err))
+			return
+		}
+		myLogger.Info("日志级别已按配置更新", zap.String("level", myLogger.Level()), zap.Any("levels", myLogger.Levels()))
+	}); err != nil {
+		myLogger.Warn("订阅日志配置变更失败", zap.Error(err))
+	}
+
 	myLogger.Info("日志系统初始化成功")
 	return nil
 }
 
+// redactConfig log.redact 转换为 myLogger 脱敏规则（路由列表由日志中间件处理）
+func redactConfig(cfg config.LogRedactConfig) myLogger.RedactConfig {
+	return myLogger.RedactConfig{Keys: cfg.Keys, DisabledDetectors: cfg.DisabledDetectors}
+}
+
 // RegisterDefaultMiddlewares 注册默认中间件
 func (s *Starter) RegisterDefaultMiddlewares() {
-	// 上下文管理中间件（必须在最前面，为其他中间件提供traceId）
+	// 链路追踪中间件（启用时由 traceparent / x-trace-id 确定 traceId，须在上下文中间件之前）
+	s.Engine.Use(tracing.GinMiddleware())
+
+	// 上下文管理中间件（为其他中间件提供traceId）
 	s.Engine.Use(myContext.ContextMiddleware())
 
 	defaultLocale := "zh-CN"
@@ -111,6 +154,9 @@ func (s *Starter) RegisterDefaultMiddlewares() {
 	}
 	s.Engine.Use(myContext.LocaleMiddleware(defaultLocale))
 
+	// 指标中间件（须在异常处理之外，才能读到最终写出的业务码；未启用时直接放行）
+	s.Engine.Use(metrics.GinMiddleware())
+
 	// 异常处理中间件
 	s.Engine.Use(middleware.ExceptionHandler())
 
@@ -129,6 +175,28 @@ func (s *Starter) RegisterInfrastructure() error {
 		return nil
 	}
 
+	// Nacos 配置中心先于其他基础设施加载，远程配置可覆盖数据库、Redis 等配置
+	if err := nacos.InitConfigCenter(s.App.Config); err != nil {
+		myLogger.Error("Nacos 配置中心初始化失败", zap.Error(err))
+		return errors.Wrap(err, "Nacos 配置中心初始化失败")
+	}
+	s.App.Config = config.GetConfig()
+	health.Configure(s.App.Config.Server.Health)
+
+	// 功能开关随配置热更新，加载失败不阻断启动（所有开关视为关闭）
+	if err := myFeature.InitFromViper(); err != nil {
+		myLogger.Warn("功能开关加载失败", zap.Error(err))
+	}
+
+	// 指标与链路追踪须在数据库、Redis、RPC 之前初始化，以便它们注册各自的埋点
+	metrics.Init(config.GetMetricsConfig())
+	metrics.RegisterRoute(s.Engine)
+	admin.RegisterRoutes(s.Engine, config.GetAdminConfig())
+	if err := tracing.Init(s.App.Config); err != nil {
+		myLogger.Error("链路追踪初始化失败", zap.Error(err))
+		return errors.Wrap(err, "链路追踪初始化失败")
+	}
+
 	// 检查是否需要注册数据库
 	if s.needDatabase() {
 		myLogger.Info("初始化数据库连接")
@@ -136,6 +204,7 @@ func (s *Starter) RegisterInfrastructure() error {
 			myLogger.Error("数据库连接初始化失败", zap.Error(err))
 			return errors.Wrap(err, "数据库连接初始化失败")
 		}
+		health.Register("database", infrastructure.HealthCheck)
 		myLogger.Info("数据库连接初始化成功")
 	}
 
@@ -146,6 +215,7 @@ func (s *Starter) RegisterInfrastructure() error {
 			myLogger.Error("Redis连接初始化失败", zap.Error(err))
 			return errors.Wrap(err, "Redis连接初始化失败")
 		}
+		health.Register("redis", infrastructure.RedisHealthCheck)
 		myLogger.Info("Redis连接初始化成功")
 	}
 
@@ -161,10 +231,15 @@ func (s *Starter) registerPlugins() error {
 	if s.App.Config == nil {
 		return nil
 	}
+	// Nacos 注册发现，始终按 plugins.nacos 初始化（plugins.rpc.registry 选择 nacos 时使用）
 	if _, err := nacos.InitWithError(s.App.Config); err != nil {
 		myLogger.Warn("Nacos 插件初始化失败，已降级为 noop", zap.Error(err))
 	}
-	rpc.Init(s.App.Config, nacos.GetRegistry())
+	reg, err := registry.InitWithError(s.App.Config)
+	if err != nil {
+		myLogger.Warn("注册中心初始化失败，已降级为 noop", zap.Error(err))
+	}
+	rpc.Init(s.App.Config, reg)
 	return nil
 }
 
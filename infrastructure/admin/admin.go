@@ -0,0 +1,52 @@
+// Package admin 运行时管理接口：生效配置（脱敏）、构建信息、路由、gRPC 服务、
+// 连接池、注册中心状态、文案版本、功能开关、日志级别与可选的 pprof。
+//
+// 接口挂载在 plugins.admin.path（默认 /admin）下，须配置 token 或 permission 之一，
+// 未配置鉴权时不挂载，避免在生产环境意外暴露。
+package admin
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/myFeature"
+	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"go.uber.org/zap"
+)
+
+// DefaultPath 管理接口路由组前缀
+const DefaultPath = "/admin"
+
+// RegisterRoutes 启用时在 engine 上挂载管理接口；路由列表在请求时读取，之后注册的业务路由同样可见
+func RegisterRoutes(engine *gin.Engine, cfg config.AdminConfig) {
+	if !cfg.Enabled || engine == nil {
+		return
+	}
+	guard := authMiddleware(cfg)
+	if guard == nil {
+		myLogger.Warn("管理接口已启用但未配置 token 或 permission，不挂载")
+		return
+	}
+	path := "/" + strings.Trim(cfg.Path, "/")
+	if path == "/" {
+		path = DefaultPath
+	}
+
+	group := engine.Group(path, guard...)
+	{
+		group.GET("/info", infoHandler)
+		group.GET("/config", configHandler(cfg.MaskKeys))
+		group.GET("/routes", routesHandler(engine))
+		group.GET("/grpc", grpcHandler)
+		group.GET("/pools", poolsHandler)
+		group.GET("/registry", registryHandler)
+		group.GET("/locales", localesHandler)
+		group.GET("/features", myFeature.ListHandler())
+	}
+	myLogger.RegisterRoutes(group)
+	if cfg.Pprof {
+		registerPprof(group)
+	}
+	myLogger.Info("管理接口已挂载", zap.String("path", path), zap.Bool("pprof", cfg.Pprof))
+}
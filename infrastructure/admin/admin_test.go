@@ -0,0 +1,138 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/middleware"
+	"github.com/muyi-zcy/tech-muyi-base-go/myAuth"
+	"github.com/muyi-zcy/tech-muyi-base-go/myFeature"
+	"github.com/spf13/viper"
+)
+
+func newAdminEngine(t *testing.T, cfg config.AdminConfig) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.ExceptionHandler())
+	RegisterRoutes(engine, cfg)
+	return engine
+}
+
+func adminGet(engine *gin.Engine, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+// resultCode 响应体中的业务码
+func resultCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("响应非 JSON: %d %s", w.Code, w.Body.String())
+	}
+	return body.Code
+}
+
+func TestAdminAuth(t *testing.T) {
+	engine := newAdminEngine(t, config.AdminConfig{Enabled: true, Token: "s3cret"})
+	cases := []struct {
+		name   string
+		header map[string]string
+		code   string
+	}{
+		{"missing", nil, myAuth.CodeTokenMissing},
+		{"wrong header", map[string]string{HeaderAdminToken: "guess"}, myAuth.CodeTokenInvalid},
+		{"wrong bearer", map[string]string{"Authorization": "Bearer guess"}, myAuth.CodeTokenInvalid},
+		{"prefix", map[string]string{HeaderAdminToken: "s3cre"}, myAuth.CodeTokenInvalid},
+	}
+	for _, tc := range cases {
+		w := adminGet(engine, "/admin/info", tc.header)
+		if code := resultCode(t, w); code != tc.code || strings.Contains(w.Body.String(), "goVersion") {
+			t.Fatalf("%s: code = %s, body = %s", tc.name, code, w.Body.String())
+		}
+	}
+	for _, header := range []map[string]string{
+		{HeaderAdminToken: "s3cret"},
+		{"Authorization": "Bearer s3cret"},
+	} {
+		if w := adminGet(engine, "/admin/info", header); resultCode(t, w) != "200" {
+			t.Fatalf("%v: %s", header, w.Body.String())
+		}
+	}
+}
+
+func TestAdminNotMounted(t *testing.T) {
+	for _, cfg := range []config.AdminConfig{
+		{Enabled: false, Token: "s3cret"},
+		{Enabled: true},
+	} {
+		engine := newAdminEngine(t, cfg)
+		if n := len(engine.Routes()); n != 0 {
+			t.Fatalf("%+v: mounted %d routes", cfg, n)
+		}
+		if w := adminGet(engine, "/admin/info", map[string]string{HeaderAdminToken: "s3cret"}); w.Code != http.StatusNotFound {
+			t.Fatalf("%+v: status = %d", cfg, w.Code)
+		}
+	}
+}
+
+func TestAdminPermissionWithoutAuth(t *testing.T) {
+	if myAuth.Initialized() {
+		t.Skip("myAuth 已初始化")
+	}
+	engine := newAdminEngine(t, config.AdminConfig{Enabled: true, Permission: "admin:read"})
+	w := adminGet(engine, "/admin/info", map[string]string{"Authorization": "Bearer anything"})
+	if code := resultCode(t, w); code != myAuth.CodePermissionDenied {
+		t.Fatalf("code = %s, body = %s", code, w.Body.String())
+	}
+}
+
+func TestAdminConfigMasked(t *testing.T) {
+	viper.Set("datasource.password", "db-pass")
+	viper.Set("plugins.nacos.dsn", "mysql://root:hunter2@db:3306/app")
+	viper.Set("plugins.custom.webhook", "https://hooks.example/abc")
+	t.Cleanup(viper.Reset)
+	engine := newAdminEngine(t, config.AdminConfig{Enabled: true, Token: "s3cret", MaskKeys: []string{"webhook"}})
+
+	w := adminGet(engine, "/admin/config", map[string]string{HeaderAdminToken: "s3cret"})
+	body := w.Body.String()
+	for _, secret := range []string{"db-pass", "hunter2", "hooks.example", "s3cret"} {
+		if strings.Contains(body, secret) {
+			t.Fatalf("配置泄露 %q: %s", secret, body)
+		}
+	}
+	if !strings.Contains(body, "mysql://root:******@db:3306/app") {
+		t.Fatalf("URL 密码未按约定脱敏: %s", body)
+	}
+}
+
+func TestFeaturesMounted(t *testing.T) {
+	if err := myFeature.Init(myFeature.Config{Flags: map[string]myFeature.FlagConfig{
+		"new_checkout": {Enabled: true, AllowTenants: []string{"t1"}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	engine := newAdminEngine(t, config.AdminConfig{Enabled: true, Token: "s3cret"})
+
+	w := adminGet(engine, "/admin/features?tenantId=t1", map[string]string{HeaderAdminToken: "s3cret"})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"new_checkout"`) ||
+		!strings.Contains(w.Body.String(), `"reason":"allowed"`) {
+		t.Fatalf("features = %d %s", w.Code, w.Body.String())
+	}
+	if w := adminGet(engine, "/admin/features", nil); w.Code == http.StatusOK && strings.Contains(w.Body.String(), "new_checkout") {
+		t.Fatalf("features exposed without token: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/metrics"
+	"github.com/muyi-zcy/tech-muyi-base-go/myAuth"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
+)
+
+// HeaderAdminToken 静态令牌请求头
+const HeaderAdminToken = "x-admin-token"
+
+// authMiddleware token 优先，其次 myAuth + 权限码；两者都未配置时返回 nil
+func authMiddleware(cfg config.AdminConfig) []gin.HandlerFunc {
+	if cfg.Token != "" {
+		return []gin.HandlerFunc{tokenAuth(cfg.Token)}
+	}
+	if cfg.Permission != "" {
+		return []gin.HandlerFunc{requireAuthInitialized, myAuth.Required(), myAuth.RequirePermission(cfg.Permission)}
+	}
+	return nil
+}
+
+// tokenAuth 常量时间比较，避免按响应时间逐位猜测令牌
+func tokenAuth(expected string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(HeaderAdminToken)
+		if token == "" {
+			token = myAuth.NormalizeToken(c.GetHeader("Authorization"))
+		}
+		if token == "" {
+			abort(c, myAuth.CodeTokenMissing)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(expected)) != 1 {
+			abort(c, myAuth.CodeTokenInvalid)
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireAuthInitialized myAuth 未初始化时拒绝访问，而不是让 Required 直接 panic
+func requireAuthInitialized(c *gin.Context) {
+	if !myAuth.Initialized() {
+		myLogger.Warn("管理接口配置了 permission，但 myAuth 未初始化")
+		abort(c, myAuth.CodePermissionDenied)
+		return
+	}
+	c.Next()
+}
+
+func abort(c *gin.Context, code string) {
+	metrics.AuthFailure("http", code)
+	myResult.ErrorWithError(c, myException.NewBizError(code, nil))
+	c.Abort()
+}
@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/health"
+	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
+)
+
+// 构建信息，可在构建时注入：
+//
+//	go build -ldflags "-X github.com/muyi-zcy/tech-muyi-base-go/infrastructure/admin.Commit=$(git rev-parse HEAD)"
+//
+// 未注入时 Commit / BuildTime 取 Go 嵌入的 vcs 信息
+var (
+	BuildVersion string
+	Commit       string
+	BuildTime    string
+)
+
+// BuildInfo 构建与运行信息
+type BuildInfo struct {
+	AppName      string    `json:"appName"`
+	AppCode      string    `json:"appCode,omitempty"`
+	Version      string    `json:"version"`
+	BuildVersion string    `json:"buildVersion,omitempty"`
+	Commit       string    `json:"commit,omitempty"`
+	BuildTime    string    `json:"buildTime,omitempty"`
+	Modified     bool      `json:"modified,omitempty"`
+	Module       string    `json:"module,omitempty"`
+	GoVersion    string    `json:"goVersion"`
+	Hostname     string    `json:"hostname"`
+	Pid          int       `json:"pid"`
+	StartedAt    time.Time `json:"startedAt"`
+	Uptime       int64     `json:"uptimeSeconds"`
+	NumCPU       int       `json:"numCPU"`
+	Goroutines   int       `json:"goroutines"`
+}
+
+// Info 当前进程的构建与运行信息
+func Info() BuildInfo {
+	uptime := health.Uptime()
+	info := BuildInfo{
+		BuildVersion: BuildVersion,
+		Commit:       Commit,
+		BuildTime:    BuildTime,
+		GoVersion:    runtime.Version(),
+		Pid:          os.Getpid(),
+		StartedAt:    time.Now().Add(-uptime),
+		Uptime:       int64(uptime.Seconds()),
+		NumCPU:       runtime.NumCPU(),
+		Goroutines:   runtime.NumGoroutine(),
+	}
+	info.Hostname, _ = os.Hostname()
+	if cfg := config.GetConfig(); cfg != nil {
+		info.AppName = cfg.AppName
+		info.AppCode = cfg.AppCode
+		info.Version = cfg.Version
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Module = bi.Main.Path
+		if info.BuildVersion == "" && bi.Main.Version != "(devel)" {
+			info.BuildVersion = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+func infoHandler(c *gin.Context) {
+	myResult.Success(c, Info())
+}
@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerPprof 在 {path}/debug/pprof 下暴露 pprof；pprof.Index 只识别 /debug/pprof/ 前缀，
+// 故具名 profile（heap、goroutine 等）按名称单独分发
+func registerPprof(group *gin.RouterGroup) {
+	group.GET("/debug/pprof/", gin.WrapF(pprof.Index))
+	group.GET("/debug/pprof/:name", func(c *gin.Context) {
+		switch name := c.Param("name"); name {
+		case "cmdline":
+			pprof.Cmdline(c.Writer, c.Request)
+		case "profile":
+			pprof.Profile(c.Writer, c.Request)
+		case "symbol":
+			pprof.Symbol(c.Writer, c.Request)
+		case "trace":
+			pprof.Trace(c.Writer, c.Request)
+		default:
+			pprof.Handler(name).ServeHTTP(c.Writer, c.Request)
+		}
+	})
+	group.POST("/debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+}
@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc"
+	"github.com/muyi-zcy/tech-muyi-base-go/myLocale"
+	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
+)
+
+// configHandler 当前生效配置（含远程层），敏感键脱敏
+func configHandler(maskKeys []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		myResult.Success(c, config.MaskedSettings(maskKeys...))
+	}
+}
+
+type routeInfo struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"`
+}
+
+// routesHandler Gin 已注册路由，按路径、方法排序
+func routesHandler(engine *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		routes := engine.Routes()
+		list := make([]routeInfo, 0, len(routes))
+		for _, r := range routes {
+			list = append(list, routeInfo{Method: r.Method, Path: r.Path, Handler: r.Handler})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Path != list[j].Path {
+				return list[i].Path < list[j].Path
+			}
+			return list[i].Method < list[j].Method
+		})
+		myResult.Success(c, list)
+	}
+}
+
+type grpcMethod struct {
+	Name         string `json:"name"`
+	ClientStream bool   `json:"clientStream,omitempty"`
+	ServerStream bool   `json:"serverStream,omitempty"`
+}
+
+type grpcService struct {
+	Name    string       `json:"name"`
+	Methods []grpcMethod `json:"methods"`
+}
+
+// grpcHandler 已注册的 gRPC 服务与下游熔断器状态；RPC 未启用时 enabled=false
+func grpcHandler(c *gin.Context) {
+	mgr := rpc.GetManager()
+	if !mgr.Enabled() || mgr.Server() == nil {
+		myResult.Success(c, gin.H{"enabled": false})
+		return
+	}
+	infos := mgr.Server().GetServiceInfo()
+	services := make([]grpcService, 0, len(infos))
+	for name, info := range infos {
+		svc := grpcService{Name: name, Methods: make([]grpcMethod, 0, len(info.Methods))}
+		for _, m := range info.Methods {
+			svc.Methods = append(svc.Methods, grpcMethod{Name: m.Name, ClientStream: m.IsClientStream, ServerStream: m.IsServerStream})
+		}
+		services = append(services, svc)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	myResult.Success(c, gin.H{
+		"enabled":  true,
+		"port":     mgr.GrpcPort(),
+		"running":  mgr.Running(),
+		"services": services,
+		"breakers": mgr.Client().BreakerStates(),
+	})
+}
+
+// poolsHandler 数据库与 Redis 连接池统计，未初始化的组件不返回
+func poolsHandler(c *gin.Context) {
+	pools := gin.H{}
+	if db := infrastructure.GetDB(); db != nil {
+		if sqlDB, err := db.DB(); err == nil {
+			s := sqlDB.Stats()
+			pools["database"] = gin.H{
+				"maxOpenConnections": s.MaxOpenConnections,
+				"openConnections":    s.OpenConnections,
+				"inUse":              s.InUse,
+				"idle":               s.Idle,
+				"waitCount":          s.WaitCount,
+				"waitDurationMs":     s.WaitDuration.Milliseconds(),
+				"maxIdleClosed":      s.MaxIdleClosed,
+				"maxIdleTimeClosed":  s.MaxIdleTimeClosed,
+				"maxLifetimeClosed":  s.MaxLifetimeClosed,
+			}
+		}
+	}
+	if client := infrastructure.GetRedis(); client != nil {
+		s := client.PoolStats()
+		pools["redis"] = gin.H{
+			"hits":       s.Hits,
+			"misses":     s.Misses,
+			"timeouts":   s.Timeouts,
+			"totalConns": s.TotalConns,
+			"idleConns":  s.IdleConns,
+			"staleConns": s.StaleConns,
+		}
+	}
+	myResult.Success(c, pools)
+}
+
+// registryHandler 注册中心状态；后端实现 registry.Describer 时附带已注册实例与当前权重
+func registryHandler(c *gin.Context) {
+	reg := registry.GetRegistry()
+	detail := gin.H{
+		"enabled": reg.Enabled(),
+		"backend": registry.Backend(config.GetConfig()),
+	}
+	if d, ok := reg.(registry.Describer); ok {
+		detail["state"] = d.Describe()
+	}
+	myResult.Success(c, detail)
+}
+
+// localesHandler 错误文案各语言版本，与 open API 返回的 version 一致
+func localesHandler(c *gin.Context) {
+	myResult.Success(c, gin.H{
+		"initialized": myLocale.Initialized(),
+		"appCode":     myLocale.AppCode(),
+		"default":     myLocale.DefaultLocale(),
+		"versions":    myLocale.Versions(),
+	})
+}
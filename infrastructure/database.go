@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/metrics"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/tracing"
 	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -116,6 +118,19 @@ func InitDatabase() error {
 		return errors.Wrap(err, "注册GORM Hooks失败")
 	}
 
+	// 指标启用时注册语句耗时与连接池统计
+	if err := metrics.InstrumentGorm(DB, "default"); err != nil {
+		return errors.Wrap(err, "注册GORM指标失败")
+	}
+	if err := metrics.RegisterDBStats("default", sqlDB); err != nil {
+		myLogger.Warn("注册数据库连接池指标失败", zap.Error(err))
+	}
+
+	// 链路追踪启用时为每条语句创建 Span
+	if err := tracing.InstrumentGorm(DB, "default"); err != nil {
+		return errors.Wrap(err, "注册GORM链路追踪失败")
+	}
+
 	myLogger.Info("GORM数据库连接初始化成功")
 	return nil
 }
@@ -132,17 +147,17 @@ func (l *gormLoggerImpl) LogMode(level gormLogger.LogLevel) gormLogger.Interface
 
 // Info 记录信息日志
 func (l *gormLoggerImpl) Info(ctx context.Context, msg string, data ...interface{}) {
-	myLogger.InfoCtx(ctx, fmt.Sprintf(msg, data...))
+	myLogger.InfoCtx(ctx, myLogger.RedactText(fmt.Sprintf(msg, data...)))
 }
 
 // Warn 记录警告日志
 func (l *gormLoggerImpl) Warn(ctx context.Context, msg string, data ...interface{}) {
-	myLogger.WarnCtx(ctx, fmt.Sprintf(msg, data...))
+	myLogger.WarnCtx(ctx, myLogger.RedactText(fmt.Sprintf(msg, data...)))
 }
 
 // Error 记录错误日志
 func (l *gormLoggerImpl) Error(ctx context.Context, msg string, data ...interface{}) {
-	myLogger.ErrorCtx(ctx, fmt.Sprintf(msg, data...))
+	myLogger.ErrorCtx(ctx, myLogger.RedactText(fmt.Sprintf(msg, data...)))
 }
 
 // Trace 记录SQL执行轨迹
@@ -151,8 +166,9 @@ func (l *gormLoggerImpl) Trace(ctx context.Context, begin time.Time, fc func() (
 	sql, rows := fc()
 	elapsed := time.Since(begin)
 
+	// SQL 已内联参数，敏感列的值与卡号等按文本脱敏
 	fields := []zap.Field{
-		zap.String("sql", sql),
+		zap.String("sql", myLogger.RedactText(sql)),
 		zap.Int64("rows", rows),
 		zap.Duration("elapsed", elapsed),
 	}
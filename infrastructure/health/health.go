@@ -0,0 +1,243 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/pkg/errors"
+)
+
+// Status 检查状态
+type Status string
+
+const (
+	StatusUp   Status = "UP"
+	StatusDown Status = "DOWN"
+
+	defaultTimeout  = 2 * time.Second
+	defaultCacheTTL = time.Second
+)
+
+// Check 依赖检查，返回 nil 表示健康；应尊重 ctx 超时
+type Check func(ctx context.Context) error
+
+// Option 单项检查选项
+type Option func(*entry)
+
+// WithTimeout 覆盖单项检查超时
+func WithTimeout(d time.Duration) Option {
+	return func(e *entry) { e.timeout = d }
+}
+
+// WithCacheTTL 覆盖单项检查结果缓存时长，0 表示每次都检查
+func WithCacheTTL(d time.Duration) Option {
+	return func(e *entry) { e.cacheTTL = &d }
+}
+
+// NonCritical 检查失败只在报告中体现，不影响就绪状态（如注册状态、可降级的下游）
+func NonCritical() Option {
+	return func(e *entry) { e.critical = false }
+}
+
+// Result 单项检查结果
+type Result struct {
+	Status     Status    `json:"status"`
+	Critical   bool      `json:"critical"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	CheckedAt  time.Time `json:"checkedAt"`
+	Cached     bool      `json:"cached,omitempty"`
+}
+
+// Report 就绪报告；任一关键检查失败或正在关闭时为 DOWN
+type Report struct {
+	Status Status            `json:"status"`
+	Reason string            `json:"reason,omitempty"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type entry struct {
+	name     string
+	check    Check
+	timeout  time.Duration
+	cacheTTL *time.Duration
+	critical bool
+
+	mu   sync.Mutex
+	last *Result
+}
+
+var (
+	mu       sync.RWMutex
+	entries  = map[string]*entry{}
+	settings = config.HealthConfig{}
+
+	shuttingDown atomic.Bool
+	startedAt    = time.Now()
+)
+
+// Configure 设置默认超时与缓存（server.health）
+func Configure(cfg config.HealthConfig) {
+	mu.Lock()
+	defer mu.Unlock()
+	settings = cfg
+}
+
+// Register 注册命名检查，同名覆盖
+func Register(name string, check Check, opts ...Option) {
+	if name == "" || check == nil {
+		return
+	}
+	e := &entry{name: name, check: check, critical: true}
+	for _, opt := range opts {
+		opt(e)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	entries[name] = e
+}
+
+// Unregister 移除检查
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(entries, name)
+}
+
+// Names 已注册的检查名（有序）
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetShuttingDown 进入优雅关闭，此后就绪状态恒为 DOWN，负载均衡与探针据此摘流
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+// ShuttingDown 是否正在关闭
+func ShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// Uptime 进程运行时长
+func Uptime() time.Duration {
+	return time.Since(startedAt)
+}
+
+// Ready 并发执行全部检查（命中缓存的直接返回）并汇总
+func Ready(ctx context.Context) Report {
+	mu.RLock()
+	list := make([]*entry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e)
+	}
+	cfg := settings
+	mu.RUnlock()
+
+	results := make([]Result, len(list))
+	var wg sync.WaitGroup
+	for i, e := range list {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = e.run(ctx, cfg)
+		}(i, e)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(list))}
+	var failed []string
+	for i, e := range list {
+		report.Checks[e.name] = results[i]
+		if e.critical && results[i].Status != StatusUp {
+			failed = append(failed, e.name)
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		report.Status = StatusDown
+		report.Reason = "检查未通过: " + strings.Join(failed, ", ")
+	}
+	if ShuttingDown() {
+		report.Status = StatusDown
+		report.Reason = "正在关闭"
+	}
+	return report
+}
+
+// run 同一检查并发调用时串行执行，后到者复用刚产生的结果
+func (e *entry) run(ctx context.Context, cfg config.HealthConfig) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.last != nil && time.Since(e.last.CheckedAt) < e.ttl(cfg) {
+		cached := *e.last
+		cached.Cached = true
+		return cached
+	}
+
+	timeout := e.timeout
+	if timeout <= 0 && cfg.TimeoutMs > 0 {
+		timeout = time.Duration(cfg.TimeoutMs) * time.Millisecond
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := runWithTimeout(ctx, e.check)
+	result := Result{
+		Status:     StatusUp,
+		Critical:   e.critical,
+		DurationMs: time.Since(start).Milliseconds(),
+		CheckedAt:  time.Now(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	e.last = &result
+	return result
+}
+
+func (e *entry) ttl(cfg config.HealthConfig) time.Duration {
+	if e.cacheTTL != nil {
+		return *e.cacheTTL
+	}
+	if cfg.CacheMs != nil {
+		return time.Duration(*cfg.CacheMs) * time.Millisecond
+	}
+	return defaultCacheTTL
+}
+
+// runWithTimeout 检查不响应 ctx 时也按超时返回，避免探针被卡住
+func runWithTimeout(ctx context.Context, check Check) (err error) {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.Errorf("检查 panic: %v", r)
+			}
+		}()
+		done <- check(ctx)
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "检查超时")
+	}
+}
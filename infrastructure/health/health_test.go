@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+)
+
+// resetChecks 清空全局注册表，测试结束后恢复
+func resetChecks(t *testing.T) {
+	t.Helper()
+	mu.Lock()
+	entries = map[string]*entry{}
+	settings = config.HealthConfig{}
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		entries = map[string]*entry{}
+		settings = config.HealthConfig{}
+		mu.Unlock()
+		shuttingDown.Store(false)
+	})
+}
+
+func failing(context.Context) error { return errors.New("down") }
+
+func passing(context.Context) error { return nil }
+
+func TestReadyCriticality(t *testing.T) {
+	resetChecks(t)
+	Register("db", passing, WithCacheTTL(0))
+	Register("registry", failing, WithCacheTTL(0), NonCritical())
+
+	report := Ready(context.Background())
+	if report.Status != StatusUp {
+		t.Fatalf("非关键检查失败不应影响就绪: %+v", report)
+	}
+	if r := report.Checks["registry"]; r.Status != StatusDown || r.Critical || r.Error != "down" {
+		t.Fatalf("registry = %+v", r)
+	}
+
+	Register("redis", failing, WithCacheTTL(0))
+	Register("mq", failing, WithCacheTTL(0))
+	report = Ready(context.Background())
+	if report.Status != StatusDown || report.Reason != "检查未通过: mq, redis" {
+		t.Fatalf("report = %+v", report)
+	}
+
+	Unregister("redis")
+	Unregister("mq")
+	SetShuttingDown()
+	report = Ready(context.Background())
+	if report.Status != StatusDown || report.Reason != "正在关闭" {
+		t.Fatalf("关闭中应为 DOWN: %+v", report)
+	}
+}
+
+func TestReadyTimeout(t *testing.T) {
+	resetChecks(t)
+	block := make(chan struct{})
+	defer close(block)
+	// 不响应 ctx 的检查也须按超时返回
+	Register("stuck", func(context.Context) error { <-block; return nil },
+		WithTimeout(20*time.Millisecond), WithCacheTTL(0))
+	Register("slow", func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }, WithCacheTTL(0))
+	Configure(config.HealthConfig{TimeoutMs: 30})
+	Register("panics", func(context.Context) error { panic("boom") }, WithCacheTTL(0), NonCritical())
+
+	start := time.Now()
+	report := Ready(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Ready 被卡住 %v", elapsed)
+	}
+	if report.Status != StatusDown {
+		t.Fatalf("report = %+v", report)
+	}
+	for _, name := range []string{"stuck", "slow"} {
+		if r := report.Checks[name]; r.Status != StatusDown || !strings.Contains(r.Error, "检查超时") {
+			t.Fatalf("%s = %+v", name, r)
+		}
+	}
+	if r := report.Checks["panics"]; r.Status != StatusDown || !strings.Contains(r.Error, "boom") {
+		t.Fatalf("panics = %+v", r)
+	}
+}
+
+func TestReadyCache(t *testing.T) {
+	resetChecks(t)
+	var calls atomic.Int32
+	counting := func(context.Context) error { calls.Add(1); return nil }
+	Register("cached", counting, WithCacheTTL(time.Hour))
+	Register("uncached", counting, WithCacheTTL(0))
+
+	first := Ready(context.Background())
+	second := Ready(context.Background())
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("calls = %d, want 3", n)
+	}
+	if first.Checks["cached"].Cached || !second.Checks["cached"].Cached || second.Checks["uncached"].Cached {
+		t.Fatalf("first=%+v second=%+v", first.Checks, second.Checks)
+	}
+
+	// 未显式设置时使用 server.health.cacheMs
+	zero := 0
+	Configure(config.HealthConfig{CacheMs: &zero})
+	Register("configured", counting)
+	calls.Store(0)
+	Ready(context.Background())
+	Ready(context.Background())
+	if n := calls.Load(); n != 4 {
+		t.Fatalf("cacheMs=0 时 calls = %d, want 4", n)
+	}
+}
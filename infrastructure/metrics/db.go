@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+const (
+	gormStartKey = "metrics:start"
+	unknownTable = "unknown"
+)
+
+// gormPlugin GORM 语句耗时与错误统计
+type gormPlugin struct {
+	name string
+}
+
+// InstrumentGorm 为 db 注册语句耗时统计，name 作为 db 标签区分多个数据源；未启用时不做任何事
+func InstrumentGorm(db *gorm.DB, name string) error {
+	if !Enabled() || db == nil {
+		return nil
+	}
+	return db.Use(&gormPlugin{name: name})
+}
+
+// Name 插件名称
+func (p *gormPlugin) Name() string {
+	return "metrics:" + p.name
+}
+
+// Initialize 在 create/query/update/delete/row/raw 前后注册回调
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("metrics:before_create", p.before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("metrics:after_create", p.after("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("metrics:before_query", p.before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("metrics:after_query", p.after("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("metrics:before_update", p.before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("metrics:after_update", p.after("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("metrics:before_delete", p.before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("metrics:after_delete", p.after("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("metrics:before_row", p.before); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("metrics:after_row", p.after("row")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("metrics:before_raw", p.before); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("metrics:after_raw", p.after("raw"))
+}
+
+func (p *gormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func (p *gormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = unknownTable
+		}
+		std.dbQueryDuration.WithLabelValues(p.name, operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			std.dbQueryErrors.WithLabelValues(p.name, operation, table).Inc()
+		}
+	}
+}
+
+// dbStatsCollector 抓取时读取 sql.DB 连接池统计
+type dbStatsCollector struct {
+	name  string
+	sqlDB *sql.DB
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+// RegisterDBStats 注册连接池统计，name 作为 db 标签；未启用时不做任何事
+func RegisterDBStats(name string, sqlDB *sql.DB) error {
+	if !Enabled() || sqlDB == nil {
+		return nil
+	}
+	ns := namespace()
+	labels := prometheus.Labels{"db": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(ns, "db_pool", metric), help, nil, labels)
+	}
+	return registry.Register(&dbStatsCollector{
+		name:              name,
+		sqlDB:             sqlDB,
+		maxOpen:           desc("max_open_connections", "最大打开连接数"),
+		open:              desc("open_connections", "当前打开连接数"),
+		inUse:             desc("in_use_connections", "使用中连接数"),
+		idle:              desc("idle_connections", "空闲连接数"),
+		waitCount:         desc("wait_count_total", "等待连接的累计次数"),
+		waitDuration:      desc("wait_duration_seconds_total", "等待连接的累计耗时"),
+		maxIdleClosed:     desc("max_idle_closed_total", "因超出最大空闲数关闭的连接数"),
+		maxIdleTimeClosed: desc("max_idle_time_closed_total", "因超出最大空闲时长关闭的连接数"),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "因超出最大生命周期关闭的连接数"),
+	})
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxIdleTimeClosed
+	ch <- c.maxLifetimeClosed
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.sqlDB.Stats()
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
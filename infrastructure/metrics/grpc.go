@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor gRPC 服务端指标，置于拦截器链最外层以统计 panic 恢复后的结果
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeServer(info.FullMethod, err, start)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor 流式调用按整个流的生命周期统计
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observeServer(info.FullMethod, err, start)
+		return err
+	}
+}
+
+// UnaryClientInterceptor gRPC 客户端指标，service 为 serviceKey
+func UnaryClientInterceptor(service string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		observeClient(service, method, err, start)
+		return err
+	}
+}
+
+// StreamClientInterceptor 统计建立流的结果与耗时
+func StreamClientInterceptor(service string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		observeClient(service, method, err, start)
+		return cs, err
+	}
+}
+
+func observeServer(method string, err error, start time.Time) {
+	if !Enabled() {
+		return
+	}
+	code := grpcCode(err)
+	std.grpcServerHandled.WithLabelValues(method, code).Inc()
+	std.grpcServerDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
+
+func observeClient(service, method string, err error, start time.Time) {
+	if !Enabled() {
+		return
+	}
+	code := grpcCode(err)
+	std.grpcClientHandled.WithLabelValues(service, method, code).Inc()
+	std.grpcClientDuration.WithLabelValues(service, method, code).Observe(time.Since(start).Seconds())
+}
+
+// grpcCode gRPC 状态码名称；非 status 错误（如熔断拒绝的业务错误）取业务错误码
+func grpcCode(err error) string {
+	if err == nil {
+		return "OK"
+	}
+	if s, ok := status.FromError(err); ok {
+		return s.Code().String()
+	}
+	return myException.GetErrorCode(err)
+}
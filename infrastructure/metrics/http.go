@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
+)
+
+// unmatchedRoute 未匹配路由统一归为一类，避免按原始路径产生无限多的序列
+const unmatchedRoute = "unmatched"
+
+// GinMiddleware HTTP 请求指标；未启用时直接放行，可无条件注册
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Enabled() {
+			c.Next()
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		if _, skip := std.excludePaths[route]; skip {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		std.httpInFlight.Inc()
+		defer std.httpInFlight.Dec()
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		code := c.GetString(myResult.CodeKey)
+		elapsed := time.Since(start).Seconds()
+		std.httpRequests.WithLabelValues(c.Request.Method, route, status, code).Inc()
+		std.httpDuration.WithLabelValues(c.Request.Method, route, status, code).Observe(elapsed)
+	}
+}
+
+// RegisterRoute 启用时在 engine 上挂载指标接口
+func RegisterRoute(engine *gin.Engine) {
+	if !Enabled() || engine == nil {
+		return
+	}
+	engine.GET(Path(), gin.WrapH(Handler()))
+}
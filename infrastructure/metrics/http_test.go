@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
+)
+
+func TestGinMiddlewareLabels(t *testing.T) {
+	Init(config.MetricsConfig{Enabled: true, Namespace: "httptest", ExcludePaths: []string{"/ok"}})
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(GinMiddleware())
+	engine.GET("/v1/order/:id", func(c *gin.Context) {
+		if c.Param("id") == "0" {
+			myResult.JSON(c, myResult.FailWithCode("platform.resource.not_found", "not found"))
+			return
+		}
+		myResult.Success(c, c.Param("id"))
+	})
+	engine.GET("/ok", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	RegisterRoute(engine)
+
+	for _, path := range []string{"/v1/order/1", "/v1/order/2", "/v1/order/0", "/ok", "/no/such/path/123"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DefaultPath, nil))
+	var lines []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if strings.HasPrefix(line, "httptest_http_server_requests_total{") {
+			lines = append(lines, line)
+		}
+	}
+	out := strings.Join(lines, "\n")
+
+	// 按路由模板而不是原始路径打标签，业务 code 单独成列
+	if !strings.Contains(out, `route="/v1/order/:id",status="200"} 2`) {
+		t.Errorf("templated route not counted:\n%s", out)
+	}
+	if !strings.Contains(out, `code="platform.resource.not_found",method="GET",route="/v1/order/:id"`) {
+		t.Errorf("business code label missing:\n%s", out)
+	}
+	if !strings.Contains(out, `route="unmatched",status="404"} 1`) || strings.Contains(out, "/no/such/path") {
+		t.Errorf("unmatched path not collapsed:\n%s", out)
+	}
+	if strings.Contains(out, `route="/ok"`) {
+		t.Errorf("excluded route counted:\n%s", out)
+	}
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"github.com/muyi-zcy/tech-muyi-base-go/myId"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// idCollector 抓取时读取 myId 的累计统计，生成 ID 的热路径不依赖 prometheus
+type idCollector struct {
+	generated      *prometheus.Desc
+	clockBackwards *prometheus.Desc
+	sequenceWaits  *prometheus.Desc
+}
+
+func newIDCollector(ns string) *idCollector {
+	return &idCollector{
+		generated: prometheus.NewDesc(prometheus.BuildFQName(ns, "id", "generated_total"),
+			"雪花 ID 生成数", nil, nil),
+		clockBackwards: prometheus.NewDesc(prometheus.BuildFQName(ns, "id", "clock_backwards_total"),
+			"生成 ID 时检测到时钟回拨的次数", nil, nil),
+		sequenceWaits: prometheus.NewDesc(prometheus.BuildFQName(ns, "id", "sequence_waits_total"),
+			"同一毫秒序列号耗尽、等待下一毫秒的次数", nil, nil),
+	}
+}
+
+func (c *idCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.generated
+	ch <- c.clockBackwards
+	ch <- c.sequenceWaits
+}
+
+func (c *idCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := myId.GetStats()
+	ch <- prometheus.MustNewConstMetric(c.generated, prometheus.CounterValue, float64(stats.Generated))
+	ch <- prometheus.MustNewConstMetric(c.clockBackwards, prometheus.CounterValue, float64(stats.ClockBackwards))
+	ch <- prometheus.MustNewConstMetric(c.sequenceWaits, prometheus.CounterValue, float64(stats.SequenceWaits))
+}
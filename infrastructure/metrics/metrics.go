@@ -0,0 +1,237 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// DefaultPath 指标暴露路径
+const DefaultPath = "/metrics"
+
+var (
+	registry = prometheus.NewRegistry()
+	enabled  atomic.Bool
+
+	mu      sync.Mutex
+	current config.MetricsConfig
+	std     *standardMetrics
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Init 按 plugins.metrics 启用指标（enabled=false 时各埋点为空操作）；重复调用仅首次生效
+func Init(cfg config.MetricsConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if enabled.Load() {
+		return
+	}
+	if cfg.Path == "" {
+		cfg.Path = DefaultPath
+	}
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = prometheus.DefBuckets
+	}
+	current = cfg
+	std = newStandardMetrics(cfg)
+	registry.MustRegister(newIDCollector(cfg.Namespace))
+	enabled.Store(true)
+	myLogger.Info("Prometheus 指标已启用", zap.String("path", cfg.Path), zap.String("namespace", cfg.Namespace))
+}
+
+// Enabled 是否已启用指标
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Path 指标暴露路径
+func Path() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if current.Path == "" {
+		return DefaultPath
+	}
+	return current.Path
+}
+
+// Handler Prometheus 抓取接口
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Registry 指标注册表，自定义 Collector 可直接注册
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Register 注册自定义 Collector
+func Register(c prometheus.Collector) error {
+	return registry.Register(c)
+}
+
+// MustRegister 注册自定义 Collector，失败 panic
+func MustRegister(cs ...prometheus.Collector) {
+	registry.MustRegister(cs...)
+}
+
+// NewCounterVec 创建并注册业务计数器（自动加 namespace 前缀）；同名重复创建时返回已注册的实例
+func NewCounterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	return registerOrExisting(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace(), Name: name, Help: help,
+	}, labels))
+}
+
+// NewGaugeVec 创建并注册业务仪表盘指标
+func NewGaugeVec(name, help string, labels ...string) *prometheus.GaugeVec {
+	return registerOrExisting(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace(), Name: name, Help: help,
+	}, labels))
+}
+
+// NewHistogramVec 创建并注册业务直方图，buckets 为空时使用 plugins.metrics.buckets
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	if len(buckets) == 0 {
+		buckets = defaultBuckets()
+	}
+	return registerOrExisting(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace(), Name: name, Help: help, Buckets: buckets,
+	}, labels))
+}
+
+func registerOrExisting[T prometheus.Collector](c T) T {
+	if err := registry.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+// namespace 业务指标可能在 Init 之前创建，此时直接读取配置
+func namespace() string {
+	if Enabled() {
+		mu.Lock()
+		defer mu.Unlock()
+		return current.Namespace
+	}
+	return config.GetMetricsConfig().Namespace
+}
+
+func defaultBuckets() []float64 {
+	if Enabled() {
+		mu.Lock()
+		defer mu.Unlock()
+		return current.Buckets
+	}
+	if buckets := config.GetMetricsConfig().Buckets; len(buckets) > 0 {
+		return buckets
+	}
+	return prometheus.DefBuckets
+}
+
+// standardMetrics 框架内置指标
+type standardMetrics struct {
+	excludePaths map[string]struct{}
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight prometheus.Gauge
+
+	grpcServerHandled  *prometheus.CounterVec
+	grpcServerDuration *prometheus.HistogramVec
+	grpcClientHandled  *prometheus.CounterVec
+	grpcClientDuration *prometheus.HistogramVec
+
+	dbQueryDuration *prometheus.HistogramVec
+	dbQueryErrors   *prometheus.CounterVec
+
+	authFailures *prometheus.CounterVec
+}
+
+func newStandardMetrics(cfg config.MetricsConfig) *standardMetrics {
+	ns := cfg.Namespace
+	m := &standardMetrics{
+		excludePaths: make(map[string]struct{}, len(cfg.ExcludePaths)),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "http_server_requests_total",
+			Help: "HTTP 请求数，route 为路由模板，code 为响应体业务码",
+		}, []string{"method", "route", "status", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns, Name: "http_server_request_duration_seconds",
+			Help: "HTTP 请求耗时", Buckets: cfg.Buckets,
+		}, []string{"method", "route", "status", "code"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: ns, Name: "http_server_requests_in_flight",
+			Help: "处理中的 HTTP 请求数",
+		}),
+
+		grpcServerHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "grpc_server_handled_total",
+			Help: "gRPC 服务端处理完成的调用数",
+		}, []string{"method", "code"}),
+		grpcServerDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns, Name: "grpc_server_handling_seconds",
+			Help: "gRPC 服务端处理耗时", Buckets: cfg.Buckets,
+		}, []string{"method", "code"}),
+		grpcClientHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "grpc_client_handled_total",
+			Help: "gRPC 客户端调用数，熔断等本地拒绝时 code 为业务错误码",
+		}, []string{"service", "method", "code"}),
+		grpcClientDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns, Name: "grpc_client_handling_seconds",
+			Help: "gRPC 客户端调用耗时（含重试）", Buckets: cfg.Buckets,
+		}, []string{"service", "method", "code"}),
+
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: ns, Name: "db_query_duration_seconds",
+			Help: "GORM 语句耗时", Buckets: cfg.Buckets,
+		}, []string{"db", "operation", "table"}),
+		dbQueryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "db_query_errors_total",
+			Help: "GORM 语句错误数（不含记录不存在）",
+		}, []string{"db", "operation", "table"}),
+
+		authFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ns, Name: "auth_failures_total",
+			Help: "鉴权失败次数，transport 为 http / grpc",
+		}, []string{"transport", "code"}),
+	}
+	for _, path := range cfg.ExcludePaths {
+		m.excludePaths[path] = struct{}{}
+	}
+	registry.MustRegister(
+		m.httpRequests, m.httpDuration, m.httpInFlight,
+		m.grpcServerHandled, m.grpcServerDuration, m.grpcClientHandled, m.grpcClientDuration,
+		m.dbQueryDuration, m.dbQueryErrors,
+		m.authFailures,
+	)
+	return m
+}
+
+// AuthFailure 记录鉴权失败（myAuth 调用）
+func AuthFailure(transport, code string) {
+	if !Enabled() {
+		return
+	}
+	std.authFailures.WithLabelValues(transport, code).Inc()
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// redisStatsCollector 抓取时读取 go-redis 连接池统计
+type redisStatsCollector struct {
+	client *redis.Client
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	total      *prometheus.Desc
+	idle       *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+// RegisterRedisStats 注册 Redis 连接池统计，name 作为 redis 标签；未启用时不做任何事
+func RegisterRedisStats(name string, client *redis.Client) error {
+	if !Enabled() || client == nil {
+		return nil
+	}
+	ns := namespace()
+	labels := prometheus.Labels{"redis": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(ns, "redis_pool", metric), help, nil, labels)
+	}
+	return registry.Register(&redisStatsCollector{
+		client:     client,
+		hits:       desc("hits_total", "从连接池取到空闲连接的次数"),
+		misses:     desc("misses_total", "连接池无空闲连接、需新建的次数"),
+		timeouts:   desc("timeouts_total", "等待连接超时的次数"),
+		total:      desc("total_connections", "连接总数"),
+		idle:       desc("idle_connections", "空闲连接数"),
+		staleConns: desc("stale_connections_total", "被移除的失效连接数"),
+	})
+}
+
+func (c *redisStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.total
+	ch <- c.idle
+	ch <- c.staleConns
+}
+
+func (c *redisStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
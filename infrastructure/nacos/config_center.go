@@ -0,0 +1,236 @@
+package nacos
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// configSource 已规范化的 dataId 配置
+type configSource struct {
+	dataId   string
+	group    string
+	format   string
+	optional bool
+}
+
+// layerName 远程配置层名称，同一 dataId 的变更替换原层、保持合并顺序
+func (s configSource) layerName() string {
+	return "nacos:" + s.group + "/" + s.dataId
+}
+
+type configCenter struct {
+	client      config_client.IConfigClient
+	namespace   string
+	snapshotDir string
+	sources     []configSource
+}
+
+// InitConfigCenter 开启 plugins.nacos.configEnabled 时按顺序加载 dataId，合并进配置并监听变更；
+// Nacos 不可用时使用本地快照，必需的 dataId 远程与快照都取不到时返回错误
+func InitConfigCenter(appCfg *config.Config) error {
+	nacosCfg := appCfg.Plugins.Nacos
+	if !nacosCfg.ConfigEnabled {
+		return nil
+	}
+	sources, err := configSources(nacosCfg)
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		logger.Warn("Nacos 配置中心已开启但未配置 dataId，跳过")
+		return nil
+	}
+
+	c := &configCenter{
+		namespace:   nacosCfg.Namespace,
+		snapshotDir: nacosCfg.ConfigSnapshotDir,
+		sources:     sources,
+	}
+	if c.snapshotDir == "" {
+		c.snapshotDir = "cache/nacos/snapshot"
+	}
+	client, err := clients.NewConfigClient(clientParam(nacosCfg))
+	if err != nil {
+		logger.Warn("Nacos ConfigClient 创建失败，仅使用本地快照", zap.Error(err))
+	} else {
+		c.client = client
+	}
+
+	config.SetRemotePrecedence(nacosCfg.ConfigPrecedence)
+	for _, src := range sources {
+		settings, err := c.load(src)
+		if err != nil {
+			return err
+		}
+		config.SetRemoteLayer(src.layerName(), settings)
+	}
+	if err := config.Rebuild(); err != nil {
+		return errors.Wrap(err, "合并 Nacos 远程配置失败")
+	}
+	logger.Info("Nacos 远程配置已合并",
+		zap.Int("dataIds", len(sources)),
+		zap.String("precedence", nacosCfg.ConfigPrecedence),
+	)
+
+	c.listen()
+	return nil
+}
+
+// configSources 规范化 configs；未配置时兼容单个 configDataId（toml、可选）
+func configSources(nacosCfg config.NacosConfig) ([]configSource, error) {
+	group := nacosCfg.Group
+	if group == "" {
+		group = registry.DefaultGroup
+	}
+	if len(nacosCfg.Configs) == 0 {
+		if nacosCfg.ConfigDataId == "" {
+			return nil, nil
+		}
+		return []configSource{{dataId: nacosCfg.ConfigDataId, group: group, format: "toml", optional: true}}, nil
+	}
+
+	sources := make([]configSource, 0, len(nacosCfg.Configs))
+	for _, item := range nacosCfg.Configs {
+		if item.DataId == "" {
+			return nil, errors.New("plugins.nacos.configs 中 dataId 不能为空")
+		}
+		src := configSource{dataId: item.DataId, group: item.Group, format: config.FormatOf(item.DataId), optional: item.Optional}
+		if src.group == "" {
+			src.group = group
+		}
+		if item.Format != "" {
+			format, err := config.NormalizeFormat(item.Format)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Nacos 配置 %s", item.DataId)
+			}
+			src.format = format
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// load 拉取并解析一个 dataId，成功时更新快照；拉取失败时退回快照
+func (c *configCenter) load(src configSource) (map[string]interface{}, error) {
+	content, fetchErr := c.fetch(src)
+	fromSnapshot := false
+	if fetchErr != nil {
+		snapshot, err := c.readSnapshot(src)
+		if err != nil {
+			if src.optional {
+				logger.Warn("拉取 Nacos 配置失败且无本地快照，跳过", zap.String("dataId", src.dataId), zap.Error(fetchErr))
+				return nil, nil
+			}
+			return nil, errors.Wrapf(fetchErr, "拉取 Nacos 配置 %s 失败且无本地快照", src.dataId)
+		}
+		logger.Warn("拉取 Nacos 配置失败，使用本地快照", zap.String("dataId", src.dataId), zap.Error(fetchErr))
+		content = snapshot
+		fromSnapshot = true
+	}
+	if content == "" {
+		if src.optional {
+			return nil, nil
+		}
+		return nil, errors.Errorf("Nacos 配置 %s（group=%s）不存在或为空", src.dataId, src.group)
+	}
+
+	settings, err := config.ParseSettings(content, src.format)
+	if err != nil {
+		return nil, errors.Wrapf(err, "解析 Nacos 配置 %s 失败", src.dataId)
+	}
+	if !fromSnapshot {
+		c.writeSnapshot(src, content)
+	}
+	return settings, nil
+}
+
+func (c *configCenter) fetch(src configSource) (string, error) {
+	if c.client == nil {
+		return "", errors.New("Nacos ConfigClient 不可用")
+	}
+	return c.client.GetConfig(vo.ConfigParam{DataId: src.dataId, Group: src.group})
+}
+
+// listen 监听全部 dataId，变更时替换对应配置层并重建配置
+func (c *configCenter) listen() {
+	if c.client == nil {
+		return
+	}
+	for _, src := range c.sources {
+		src := src
+		err := c.client.ListenConfig(vo.ConfigParam{
+			DataId: src.dataId,
+			Group:  src.group,
+			OnChange: func(_, _, _, data string) {
+				c.onChange(src, data)
+			},
+		})
+		if err != nil {
+			logger.Warn("监听 Nacos 配置失败", zap.String("dataId", src.dataId), zap.Error(err))
+		}
+	}
+}
+
+// onChange 内容为空表示配置已删除，对应配置层随之清空；格式错误时保留旧配置
+func (c *configCenter) onChange(src configSource, data string) {
+	var settings map[string]interface{}
+	if data != "" {
+		parsed, err := config.ParseSettings(data, src.format)
+		if err != nil {
+			logger.Warn("Nacos 配置格式错误，忽略本次变更", zap.String("dataId", src.dataId), zap.Error(err))
+			return
+		}
+		settings = parsed
+		c.writeSnapshot(src, data)
+	} else {
+		_ = os.Remove(c.snapshotPath(src))
+	}
+	if err := config.UpdateRemoteLayer(src.layerName(), settings); err != nil {
+		logger.Warn("Nacos 配置热更新失败", zap.String("dataId", src.dataId), zap.Error(err))
+		return
+	}
+	logger.Info("Nacos 配置已热更新", zap.String("dataId", src.dataId), zap.String("group", src.group))
+}
+
+// snapshotPath {snapshotDir}/{namespace}/{group}/{dataId}
+func (c *configCenter) snapshotPath(src configSource) string {
+	namespace := c.namespace
+	if namespace == "" {
+		namespace = "public"
+	}
+	return filepath.Join(c.snapshotDir, namespace, src.group, src.dataId)
+}
+
+func (c *configCenter) readSnapshot(src configSource) (string, error) {
+	data, err := os.ReadFile(c.snapshotPath(src))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// writeSnapshot 先写临时文件再重命名，避免进程中断留下半截快照
+func (c *configCenter) writeSnapshot(src configSource, content string) {
+	path := c.snapshotPath(src)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		logger.Warn("创建 Nacos 配置快照目录失败", zap.String("path", path), zap.Error(err))
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o600); err != nil {
+		logger.Warn("写入 Nacos 配置快照失败", zap.String("path", path), zap.Error(err))
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		logger.Warn("写入 Nacos 配置快照失败", zap.String("path", path), zap.Error(err))
+	}
+}
@@ -0,0 +1,149 @@
+package nacos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/spf13/viper"
+)
+
+// fakeConfigClient 仅实现 GetConfig，contents 中没有的 dataId 返回空内容；down 为 true 时模拟 Nacos 不可用
+type fakeConfigClient struct {
+	config_client.IConfigClient
+	contents map[string]string
+	down     bool
+}
+
+func (c *fakeConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	if c.down {
+		return "", errors.New("connection refused")
+	}
+	return c.contents[param.DataId], nil
+}
+
+func TestConfigSources(t *testing.T) {
+	legacy, err := configSources(config.NacosConfig{ConfigDataId: "app.conf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(legacy) != 1 || legacy[0].format != "toml" || !legacy[0].optional || legacy[0].group != registry.DefaultGroup {
+		t.Fatalf("legacy source = %+v", legacy)
+	}
+
+	sources, err := configSources(config.NacosConfig{
+		Group:        "G",
+		ConfigDataId: "ignored.conf",
+		Configs: []config.NacosConfigSource{
+			{DataId: "common.yml"},
+			{DataId: "db.properties", Group: "DB"},
+			{DataId: "feature", Format: "JSON", Optional: true},
+			{DataId: "app-prod.conf"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []configSource{
+		{dataId: "common.yml", group: "G", format: "yaml"},
+		{dataId: "db.properties", group: "DB", format: "properties"},
+		{dataId: "feature", group: "G", format: "json", optional: true},
+		{dataId: "app-prod.conf", group: "G", format: "toml"},
+	}
+	if len(sources) != len(want) {
+		t.Fatalf("sources = %+v", sources)
+	}
+	for i := range want {
+		if sources[i] != want[i] {
+			t.Errorf("source %d = %+v, want %+v", i, sources[i], want[i])
+		}
+	}
+
+	for name, cfg := range map[string]config.NacosConfig{
+		"empty dataId":   {Configs: []config.NacosConfigSource{{Format: "yaml"}}},
+		"unknown format": {Configs: []config.NacosConfigSource{{DataId: "a", Format: "xml"}}},
+	} {
+		if _, err := configSources(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestConfigCenterLoadFallsBackToSnapshot(t *testing.T) {
+	client := &fakeConfigClient{contents: map[string]string{
+		"db.properties": "database.host=10.0.0.1\ndatabase.port=3306\n",
+	}}
+	c := &configCenter{client: client, snapshotDir: t.TempDir()}
+	src := configSource{dataId: "db.properties", group: "G", format: "properties"}
+
+	settings, err := c.load(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db, _ := settings["database"].(map[string]interface{}); db["host"] != "10.0.0.1" {
+		t.Fatalf("settings = %+v", settings)
+	}
+
+	// Nacos 不可用时使用上次成功拉取写入的快照
+	client.down = true
+	settings, err = c.load(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db, _ := settings["database"].(map[string]interface{}); db["host"] != "10.0.0.1" {
+		t.Fatalf("snapshot settings = %+v", settings)
+	}
+
+	missing := configSource{dataId: "missing.yaml", group: "G", format: "yaml"}
+	if _, err := c.load(missing); err == nil {
+		t.Error("required dataId without snapshot should fail")
+	}
+	missing.optional = true
+	if settings, err := c.load(missing); err != nil || settings != nil {
+		t.Errorf("optional dataId = %v, %v", settings, err)
+	}
+
+	client.down = false
+	if _, err := c.load(configSource{dataId: "empty.toml", group: "G", format: "toml"}); err == nil {
+		t.Error("required empty dataId should fail")
+	}
+	client.contents["broken.yaml"] = "a: [1"
+	if _, err := c.load(configSource{dataId: "broken.yaml", group: "G", format: "yaml"}); err == nil {
+		t.Error("malformed content should fail")
+	}
+}
+
+func TestRemoteLayersMerge(t *testing.T) {
+	common, err := config.ParseSettings("app_name = \"demo\"\n[server]\nport = 8080\nmode = \"debug\"\n", "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	override, err := config.ParseSettings("server:\n  mode: release\n", "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		config.SetRemoteLayer("nacos:test/common.toml", nil)
+		_ = config.UpdateRemoteLayer("nacos:test/override.yaml", nil)
+	}()
+
+	config.SetRemoteLayer("nacos:test/common.toml", common)
+	config.SetRemoteLayer("nacos:test/override.yaml", override)
+	if err := config.Rebuild(); err != nil {
+		t.Fatal(err)
+	}
+	if got := config.GetServerConfig(); got.Port != 8080 || got.Mode != "release" {
+		t.Fatalf("server = %+v, later dataId should override earlier", got)
+	}
+
+	// 推送删除后，该层的配置项不残留
+	if err := config.UpdateRemoteLayer("nacos:test/override.yaml", nil); err != nil {
+		t.Fatal(err)
+	}
+	if mode := viper.GetString("server.mode"); mode != "debug" {
+		t.Fatalf("server.mode = %q after layer removed", mode)
+	}
+}
@@ -1,35 +1,32 @@
 package nacos
 
 import (
-	"context"
-
 	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
 	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
 	"go.uber.org/zap"
 )
 
-// Registry Nacos 注册与发现接口（可插拔）
-type Registry interface {
-	Enabled() bool
-	Register(ctx context.Context, grpcPort int, metadata map[string]string) error
-	Deregister(ctx context.Context) error
-	NamingClient() NamingClient
-}
+// logger 命名日志，级别可由 log.levels.nacos 单独调整
+var logger = myLogger.Named("nacos")
 
-// NamingClient 供 gRPC Resolver 使用的命名服务抽象
-type NamingClient interface {
-	Subscribe(serviceName, groupName string, callback func(instances []ServiceInstance)) error
-	Unsubscribe(serviceName, groupName string) error
-	SelectInstances(serviceName, groupName string) ([]ServiceInstance, error)
-}
+// Registry 注册与发现接口，见 registry.Registry
+type Registry = registry.Registry
+
+// NamingClient 命名服务抽象，见 registry.NamingClient
+type NamingClient = registry.NamingClient
 
 // ServiceInstance 服务实例
-type ServiceInstance struct {
-	IP       string
-	Port     uint64
-	Weight   float64
-	Healthy  bool
-	Metadata map[string]string
+type ServiceInstance = registry.ServiceInstance
+
+// HTTPServiceSuffix HTTP 服务实例默认服务名后缀（serviceName + "-http"）
+const HTTPServiceSuffix = registry.HTTPServiceSuffix
+
+// plugins.rpc.registry = nacos 时使用 Nacos 注册中心（需先 InitWithError）
+func init() {
+	registry.RegisterFactory(registry.Nacos, func(*config.Config) (registry.Registry, error) {
+		return GetRegistry(), nil
+	})
 }
 
 var globalRegistry Registry = &noopRegistry{}
@@ -38,7 +35,7 @@ var globalRegistry Registry = &noopRegistry{}
 func Init(cfg *config.Config) Registry {
 	reg, err := InitWithError(cfg)
 	if err != nil {
-		myLogger.Warn("Nacos 初始化失败，降级为 noop", zap.Error(err))
+		logger.Warn("Nacos 初始化失败，降级为 noop", zap.Error(err))
 	}
 	return reg
 }
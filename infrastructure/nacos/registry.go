@@ -3,21 +3,18 @@ package nacos
 import (
 	"context"
 	"net"
-	"os"
 	"strconv"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/muyi-zcy/tech-muyi-base-go/config"
-	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients"
-	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 	"github.com/pkg/errors"
-	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
@@ -26,11 +23,12 @@ type nacosRegistry struct {
 	appVersion   string
 	httpPort     int
 	namingClient naming_client.INamingClient
-	configClient config_client.IConfigClient
-	registered   bool
-	instanceIP   string
-	instancePort uint64
-	mu           sync.Mutex
+	instances    []registry.Endpoint
+	weight       float64
+	stopWarmup   chan struct{}
+	// warmupDone 预热协程退出后关闭，注销前等待，避免进行中的权重更新与注销交错
+	warmupDone chan struct{}
+	mu         sync.Mutex
 }
 
 func newNacosRegistry(appCfg *config.Config) (*nacosRegistry, error) {
@@ -41,27 +39,17 @@ func newNacosRegistry(appCfg *config.Config) (*nacosRegistry, error) {
 	if nacosCfg.Group == "" {
 		nacosCfg.Group = "XI_PLATFORM"
 	}
-
-	sc := []constant.ServerConfig{
-		*constant.NewServerConfig(parseHost(nacosCfg.ServerAddr), parsePort(nacosCfg.ServerAddr)),
+	if nacosCfg.Weight <= 0 {
+		nacosCfg.Weight = 1
+	}
+	if nacosCfg.WarmupSteps <= 0 {
+		nacosCfg.WarmupSteps = 10
 	}
-	cc := constant.ClientConfig{
-		NamespaceId:         nacosCfg.Namespace,
-		TimeoutMs:           5000,
-		NotLoadCacheAtStart: true,
-		LogDir:              "logs/nacos",
-		CacheDir:            "cache/nacos",
-		LogLevel:            "warn",
-		Username:            nacosCfg.Username,
-		Password:            nacosCfg.Password,
+	if nacosCfg.HTTPServiceName == "" {
+		nacosCfg.HTTPServiceName = nacosCfg.ServiceName + HTTPServiceSuffix
 	}
 
-	namingClient, err := clients.NewNamingClient(
-		vo.NacosClientParam{
-			ClientConfig:  &cc,
-			ServerConfigs: sc,
-		},
-	)
+	namingClient, err := clients.NewNamingClient(clientParam(nacosCfg))
 	if err != nil {
 		return nil, errors.Wrap(err, "创建 Nacos NamingClient 失败")
 	}
@@ -73,24 +61,7 @@ func newNacosRegistry(appCfg *config.Config) (*nacosRegistry, error) {
 		namingClient: namingClient,
 	}
 
-	if nacosCfg.ConfigEnabled && nacosCfg.ConfigDataId != "" {
-		configClient, err := clients.NewConfigClient(
-			vo.NacosClientParam{
-				ClientConfig:  &cc,
-				ServerConfigs: sc,
-			},
-		)
-		if err != nil {
-			myLogger.Warn("Nacos ConfigClient 创建失败，跳过配置中心", zap.Error(err))
-		} else {
-			reg.configClient = configClient
-			if err := reg.loadRemoteConfig(); err != nil {
-				myLogger.Warn("拉取 Nacos 远程配置失败", zap.Error(err))
-			}
-		}
-	}
-
-	myLogger.Info("Nacos 插件已启用",
+	logger.Info("Nacos 插件已启用",
 		zap.String("serviceName", nacosCfg.ServiceName),
 		zap.String("serverAddr", nacosCfg.ServerAddr),
 	)
@@ -99,113 +70,205 @@ func newNacosRegistry(appCfg *config.Config) (*nacosRegistry, error) {
 
 func (r *nacosRegistry) Enabled() bool { return true }
 
+// Register 注册 gRPC 实例（grpcPort > 0）；开启 registerHttp 时另注册 HTTP 服务实例
 func (r *nacosRegistry) Register(_ context.Context, grpcPort int, metadata map[string]string) error {
-	ip, err := localIP()
+	instances, err := registry.LocalEndpoints(registry.EndpointOptions{
+		ServiceName:     r.cfg.ServiceName,
+		HTTPServiceName: r.cfg.HTTPServiceName,
+		RegisterHTTP:    r.cfg.RegisterHTTP,
+		HTTPPort:        r.httpPort,
+		Version:         r.appVersion,
+	}, grpcPort, metadata)
 	if err != nil {
 		return err
 	}
-	if metadata == nil {
-		metadata = map[string]string{}
+	if len(instances) == 0 {
+		return nil
 	}
-	metadata["version"] = r.appVersion
-	metadata["protocol"] = "grpc"
-	metadata["language"] = "go"
-	if r.httpPort > 0 {
-		metadata["httpPort"] = strconv.Itoa(r.httpPort)
+
+	weight := r.warmupWeight(1)
+	for i, inst := range instances {
+		ok, err := r.namingClient.RegisterInstance(vo.RegisterInstanceParam{
+			Ip:          inst.IP,
+			Port:        inst.Port,
+			ServiceName: inst.ServiceName,
+			GroupName:   r.cfg.Group,
+			Weight:      weight,
+			Enable:      true,
+			Healthy:     true,
+			Ephemeral:   true,
+			Metadata:    inst.Metadata,
+		})
+		if err == nil && !ok {
+			err = errors.New("Nacos 注册实例返回 false")
+		}
+		if err != nil {
+			r.deregisterInstances(instances[:i])
+			return errors.Wrapf(err, "Nacos 注册实例 %s 失败", inst.ServiceName)
+		}
+		logger.Info("Nacos 注册成功",
+			zap.String("service", inst.ServiceName),
+			zap.String("ip", inst.IP),
+			zap.Uint64("port", inst.Port),
+			zap.String("protocol", inst.Metadata["protocol"]),
+			zap.Float64("weight", weight),
+		)
 	}
 
-	param := vo.RegisterInstanceParam{
-		Ip:          ip,
-		Port:        uint64(grpcPort),
-		ServiceName: r.cfg.ServiceName,
-		GroupName:   r.cfg.Group,
-		Weight:      1,
-		Enable:      true,
-		Healthy:     true,
-		Ephemeral:   true,
-		Metadata:    metadata,
+	r.mu.Lock()
+	r.instances = instances
+	r.weight = weight
+	if r.cfg.WarmupSeconds > 0 {
+		r.stopWarmup = make(chan struct{})
+		r.warmupDone = make(chan struct{})
+		go r.warmup(r.stopWarmup, r.warmupDone)
 	}
-	ok, err := r.namingClient.RegisterInstance(param)
-	if err != nil {
-		return errors.Wrap(err, "Nacos 注册实例失败")
+	r.mu.Unlock()
+	return nil
+}
+
+// warmupWeight 预热第 step 步（从 1 开始）的权重；未开启预热时直接返回目标权重
+func (r *nacosRegistry) warmupWeight(step int) float64 {
+	if r.cfg.WarmupSeconds <= 0 || step >= r.cfg.WarmupSteps {
+		return r.cfg.Weight
 	}
-	if !ok {
-		return errors.New("Nacos 注册实例返回 false")
+	return r.cfg.Weight * float64(step) / float64(r.cfg.WarmupSteps)
+}
+
+// warmup 按步长逐步提升权重，新实例避免冷启动时承接全量流量
+func (r *nacosRegistry) warmup(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	interval := time.Duration(r.cfg.WarmupSeconds) * time.Second / time.Duration(r.cfg.WarmupSteps)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for step := 2; step <= r.cfg.WarmupSteps; step++ {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		weight := r.warmupWeight(step)
+		if err := r.updateWeight(stop, weight); err != nil {
+			if errors.Is(err, errWarmupStopped) {
+				return
+			}
+			logger.Warn("Nacos 预热调整权重失败", zap.Float64("weight", weight), zap.Error(err))
+			continue
+		}
+		logger.Debug("Nacos 预热调整权重", zap.Float64("weight", weight))
 	}
+	logger.Info("Nacos 实例预热完成", zap.Float64("weight", r.cfg.Weight))
+}
+
+var errWarmupStopped = errors.New("Nacos 预热已停止")
 
+// updateWeight 在锁外逐个调用 UpdateInstance，避免网络调用阻塞 Deregister / Describe；
+// 每个实例更新前检查 stop，注销开始后不再更新
+func (r *nacosRegistry) updateWeight(stop <-chan struct{}, weight float64) error {
 	r.mu.Lock()
-	r.registered = true
-	r.instanceIP = ip
-	r.instancePort = uint64(grpcPort)
+	instances := append([]registry.Endpoint(nil), r.instances...)
 	r.mu.Unlock()
-
-	myLogger.Info("Nacos 注册成功",
-		zap.String("service", r.cfg.ServiceName),
-		zap.String("ip", ip),
-		zap.Int("grpcPort", grpcPort),
-	)
+	for _, inst := range instances {
+		select {
+		case <-stop:
+			return errWarmupStopped
+		default:
+		}
+		ok, err := r.namingClient.UpdateInstance(vo.UpdateInstanceParam{
+			Ip:          inst.IP,
+			Port:        inst.Port,
+			Weight:      weight,
+			Enable:      true,
+			Healthy:     true,
+			Metadata:    inst.Metadata,
+			ServiceName: inst.ServiceName,
+			GroupName:   r.cfg.Group,
+			Ephemeral:   true,
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("Nacos 更新实例返回 false")
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-stop:
+		return errWarmupStopped
+	default:
+	}
+	r.weight = weight
 	return nil
 }
 
-func (r *nacosRegistry) Deregister(_ context.Context) error {
+// Deregister 先停止预热并等待进行中的权重更新结束（受 ctx 约束），再在锁外逐个注销
+func (r *nacosRegistry) Deregister(ctx context.Context) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-	if !r.registered {
-		return nil
+	instances := r.instances
+	done := r.warmupDone
+	if r.stopWarmup != nil {
+		close(r.stopWarmup)
+		r.stopWarmup = nil
+		r.warmupDone = nil
 	}
-	param := vo.DeregisterInstanceParam{
-		Ip:          r.instanceIP,
-		Port:        r.instancePort,
-		ServiceName: r.cfg.ServiceName,
-		GroupName:   r.cfg.Group,
-		Ephemeral:   true,
-	}
-	ok, err := r.namingClient.DeregisterInstance(param)
-	if err != nil {
-		return errors.Wrap(err, "Nacos 注销实例失败")
+	r.instances = nil
+	r.weight = 0
+	r.mu.Unlock()
+	if len(instances) == 0 {
+		return nil
 	}
-	if !ok {
-		myLogger.Warn("Nacos 注销实例返回 false")
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			logger.Warn("等待 Nacos 预热停止超时，直接注销", zap.Error(ctx.Err()))
+		}
 	}
-	r.registered = false
-	myLogger.Info("Nacos 实例已注销", zap.String("service", r.cfg.ServiceName))
-	return nil
+	return r.deregisterInstances(instances)
 }
 
-func (r *nacosRegistry) NamingClient() NamingClient {
-	return &nacosNamingAdapter{client: r.namingClient, group: r.cfg.Group}
+// Describe 已注册实例与当前权重（预热中为爬升过程中的权重）
+func (r *nacosRegistry) Describe() registry.State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return registry.State{
+		Backend:    registry.Nacos,
+		ServerAddr: r.cfg.ServerAddr,
+		Namespace:  r.cfg.Namespace,
+		Group:      r.cfg.Group,
+		Registered: len(r.instances) > 0,
+		Weight:     r.weight,
+		Instances:  append([]registry.Endpoint{}, r.instances...),
+	}
 }
 
-func (r *nacosRegistry) loadRemoteConfig() error {
-	content, err := r.configClient.GetConfig(vo.ConfigParam{
-		DataId: r.cfg.ConfigDataId,
-		Group:  r.cfg.Group,
-	})
-	if err != nil {
-		return err
-	}
-	if content == "" {
-		return nil
-	}
-	if err := viper.MergeConfig(strings.NewReader(content)); err != nil {
-		return err
+// deregisterInstances 逐个注销，返回最后一个错误
+func (r *nacosRegistry) deregisterInstances(instances []registry.Endpoint) error {
+	var lastErr error
+	for _, inst := range instances {
+		ok, err := r.namingClient.DeregisterInstance(vo.DeregisterInstanceParam{
+			Ip:          inst.IP,
+			Port:        inst.Port,
+			ServiceName: inst.ServiceName,
+			GroupName:   r.cfg.Group,
+			Ephemeral:   true,
+		})
+		if err != nil {
+			lastErr = errors.Wrapf(err, "Nacos 注销实例 %s 失败", inst.ServiceName)
+			continue
+		}
+		if !ok {
+			logger.Warn("Nacos 注销实例返回 false", zap.String("service", inst.ServiceName))
+		}
+		logger.Info("Nacos 实例已注销", zap.String("service", inst.ServiceName))
 	}
-	myLogger.Info("Nacos 远程配置已合并", zap.String("dataId", r.cfg.ConfigDataId))
+	return lastErr
+}
 
-	return r.configClient.ListenConfig(vo.ConfigParam{
-		DataId: r.cfg.ConfigDataId,
-		Group:  r.cfg.Group,
-		OnChange: func(_, _, dataId, data string) {
-			if data == "" {
-				return
-			}
-			if err := viper.MergeConfig(strings.NewReader(data)); err != nil {
-				myLogger.Warn("Nacos 配置热更新失败", zap.Error(err))
-				return
-			}
-			myLogger.Info("Nacos 配置已热更新", zap.String("dataId", dataId))
-		},
-	})
+func (r *nacosRegistry) NamingClient() NamingClient {
+	return &nacosNamingAdapter{client: r.namingClient, group: r.cfg.Group}
 }
 
 type nacosNamingAdapter struct {
@@ -222,7 +285,7 @@ func (a *nacosNamingAdapter) Subscribe(serviceName, groupName string, callback f
 		GroupName:   groupName,
 		SubscribeCallback: func(services []model.Instance, err error) {
 			if err != nil {
-				myLogger.Warn("Nacos 订阅回调错误", zap.Error(err))
+				logger.Warn("Nacos 订阅回调错误", zap.Error(err))
 				return
 			}
 			callback(convertInstances(services))
@@ -269,6 +332,25 @@ func convertInstances(instances []model.Instance) []ServiceInstance {
 	return result
 }
 
+// clientParam 注册发现与配置中心共用的客户端参数
+func clientParam(nacosCfg config.NacosConfig) vo.NacosClientParam {
+	return vo.NacosClientParam{
+		ClientConfig: &constant.ClientConfig{
+			NamespaceId:         nacosCfg.Namespace,
+			TimeoutMs:           5000,
+			NotLoadCacheAtStart: true,
+			LogDir:              "logs/nacos",
+			CacheDir:            "cache/nacos",
+			LogLevel:            "warn",
+			Username:            nacosCfg.Username,
+			Password:            nacosCfg.Password,
+		},
+		ServerConfigs: []constant.ServerConfig{
+			*constant.NewServerConfig(parseHost(nacosCfg.ServerAddr), parsePort(nacosCfg.ServerAddr)),
+		},
+	}
+}
+
 func parseHost(addr string) string {
 	host, _, err := net.SplitHostPort(addr)
 	if err != nil {
@@ -288,20 +370,3 @@ func parsePort(addr string) uint64 {
 	}
 	return port
 }
-
-func localIP() (string, error) {
-	addrs, err := net.InterfaceAddrs()
-	if err != nil {
-		return "", err
-	}
-	for _, addr := range addrs {
-		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
-			return ipNet.IP.String(), nil
-		}
-	}
-	hostname, err := os.Hostname()
-	if err != nil {
-		return "127.0.0.1", nil
-	}
-	return hostname, nil
-}
@@ -0,0 +1,90 @@
+package nacos
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// fakeNamingClient 仅实现 UpdateInstance / DeregisterInstance；UpdateInstance 阻塞到 release 关闭
+type fakeNamingClient struct {
+	naming_client.INamingClient
+	updating chan struct{}
+	release  chan struct{}
+
+	mu     sync.Mutex
+	events []string
+}
+
+func (c *fakeNamingClient) UpdateInstance(param vo.UpdateInstanceParam) (bool, error) {
+	c.updating <- struct{}{}
+	<-c.release
+	c.record("update " + param.ServiceName)
+	return true, nil
+}
+
+func (c *fakeNamingClient) DeregisterInstance(param vo.DeregisterInstanceParam) (bool, error) {
+	c.record("deregister " + param.ServiceName)
+	return true, nil
+}
+
+func (c *fakeNamingClient) record(event string) {
+	c.mu.Lock()
+	c.events = append(c.events, event)
+	c.mu.Unlock()
+}
+
+func TestWarmupUpdateOutsideLock(t *testing.T) {
+	client := &fakeNamingClient{updating: make(chan struct{}), release: make(chan struct{})}
+	r := &nacosRegistry{
+		cfg:          config.NacosConfig{WarmupSeconds: 1, WarmupSteps: 100, Weight: 1},
+		namingClient: client,
+		instances:    []registry.Endpoint{{ServiceName: "grpc"}, {ServiceName: "http"}},
+		stopWarmup:   make(chan struct{}),
+		warmupDone:   make(chan struct{}),
+	}
+	go r.warmup(r.stopWarmup, r.warmupDone)
+
+	// 第一个实例的 UpdateInstance 进行中，Describe 不应被阻塞
+	<-client.updating
+	described := make(chan registry.State, 1)
+	go func() { described <- r.Describe() }()
+	select {
+	case state := <-described:
+		if !state.Registered {
+			t.Fatalf("state = %+v", state)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Describe 被进行中的权重更新阻塞")
+	}
+
+	deregistered := make(chan error, 1)
+	go func() { deregistered <- r.Deregister(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	close(client.release)
+	if err := <-deregistered; err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+
+	// 注销开始后不再更新剩余实例，注销在进行中的更新结束之后
+	want := []string{"update grpc", "deregister grpc", "deregister http"}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.events) != len(want) {
+		t.Fatalf("events = %v, want %v", client.events, want)
+	}
+	for i := range want {
+		if client.events[i] != want[i] {
+			t.Fatalf("events = %v, want %v", client.events, want)
+		}
+	}
+	if state := r.Describe(); state.Registered || state.Weight != 0 {
+		t.Fatalf("注销后 state = %+v", state)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/metrics"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/tracing"
 	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
 	"github.com/pkg/errors"
 
@@ -29,12 +31,7 @@ func InitRedis() error {
 	myLogger.Info("Redis配置",
 		zap.String("host", redisConfig.Host),
 		zap.Int("port", redisConfig.Port),
-		zap.String("password", func() string {
-			if redisConfig.Password != "" {
-				return "***"
-			}
-			return ""
-		}()),
+		zap.Bool("passwordSet", redisConfig.Password != ""), // 不输出密码本身，日志脱敏仅作兜底
 		zap.Int("db", redisConfig.DB),
 		zap.Int("poolSize", redisConfig.PoolSize),
 		zap.Int("minIdleConns", redisConfig.MinIdleConns))
@@ -61,9 +58,22 @@ func InitRedis() error {
 		return errors.Wrap(err, "Redis连接测试失败")
 	}
 
+	if err := metrics.RegisterRedisStats("default", RedisClient); err != nil {
+		myLogger.Warn("注册Redis连接池指标失败", zap.Error(err))
+	}
+	tracing.InstrumentRedis(RedisClient)
+
 	return nil
 }
 
+// RedisHealthCheck Redis健康检查
+func RedisHealthCheck(ctx context.Context) error {
+	if RedisClient == nil {
+		return errors.New("redis not initialized")
+	}
+	return RedisClient.Ping(ctx).Err()
+}
+
 // GetRedis 获取Redis客户端实例
 func GetRedis() *redis.Client {
 	return RedisClient
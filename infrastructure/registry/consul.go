@@ -0,0 +1,302 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// consulWeightScale Consul 权重为整数，按 Nacos 权重 ×100 保存
+const consulWeightScale = 100
+
+type consulRegistry struct {
+	cfg    config.ConsulRegistryConfig
+	opts   EndpointOptions
+	group  string
+	weight float64
+	client *api.Client
+	naming *consulNaming
+
+	mu        sync.Mutex
+	endpoints []Endpoint
+	stop      chan struct{}
+}
+
+func newConsulRegistry(appCfg *config.Config) (Registry, error) {
+	cfg := appCfg.Plugins.Registry.Consul
+	if cfg.TTLSeconds <= 0 {
+		cfg.TTLSeconds = 10
+	}
+	if cfg.DeregisterAfterSeconds <= 0 {
+		cfg.DeregisterAfterSeconds = 60
+	}
+
+	apiCfg := api.DefaultConfig()
+	if cfg.Address != "" {
+		apiCfg.Address = cfg.Address
+	}
+	apiCfg.Token = cfg.Token
+	apiCfg.Datacenter = cfg.Datacenter
+	client, err := api.NewClient(apiCfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "创建 Consul 客户端失败")
+	}
+	if _, err := client.Agent().Self(); err != nil {
+		return nil, errors.Wrap(err, "连接 Consul 失败")
+	}
+
+	weight := appCfg.Plugins.Registry.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+	reg := &consulRegistry{
+		cfg:    cfg,
+		opts:   defaultOptions(appCfg),
+		group:  Group(appCfg),
+		weight: weight,
+		client: client,
+		naming: &consulNaming{client: client, group: Group(appCfg), watches: map[string][]context.CancelFunc{}},
+	}
+	logger.Info("Consul 注册中心已启用",
+		zap.String("address", apiCfg.Address),
+		zap.String("serviceName", reg.opts.ServiceName),
+	)
+	return reg, nil
+}
+
+func (r *consulRegistry) Enabled() bool { return true }
+
+func serviceID(ep Endpoint) string {
+	return fmt.Sprintf("%s-%s-%d", ep.ServiceName, ep.IP, ep.Port)
+}
+
+func checkID(ep Endpoint) string {
+	return "service:" + serviceID(ep)
+}
+
+// Register 以 TTL 检查注册实例，由心跳维持 passing；进程异常退出后 Consul 在 deregisterAfterSeconds 后注销
+func (r *consulRegistry) Register(_ context.Context, grpcPort int, metadata map[string]string) error {
+	endpoints, err := LocalEndpoints(r.opts, grpcPort, metadata)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	agent := r.client.Agent()
+	for i, ep := range endpoints {
+		err := agent.ServiceRegister(&api.AgentServiceRegistration{
+			ID:      serviceID(ep),
+			Name:    ep.ServiceName,
+			Tags:    []string{r.group},
+			Address: ep.IP,
+			Port:    int(ep.Port),
+			Meta:    ep.Metadata,
+			Weights: &api.AgentWeights{Passing: consulWeight(r.weight), Warning: 1},
+			Check: &api.AgentServiceCheck{
+				CheckID:                        checkID(ep),
+				TTL:                            fmt.Sprintf("%ds", r.cfg.TTLSeconds),
+				DeregisterCriticalServiceAfter: fmt.Sprintf("%ds", r.cfg.DeregisterAfterSeconds),
+			},
+		})
+		if err == nil {
+			err = agent.UpdateTTL(checkID(ep), "", api.HealthPassing)
+		}
+		if err != nil {
+			r.deregisterEndpoints(endpoints[:i+1])
+			return errors.Wrapf(err, "Consul 注册实例 %s 失败", ep.ServiceName)
+		}
+		logger.Info("Consul 注册成功",
+			zap.String("service", ep.ServiceName),
+			zap.String("addr", ep.Addr()),
+			zap.String("protocol", ep.Metadata["protocol"]),
+		)
+	}
+
+	r.mu.Lock()
+	r.endpoints = endpoints
+	r.stop = make(chan struct{})
+	go r.heartbeat(endpoints, r.stop)
+	r.mu.Unlock()
+	return nil
+}
+
+// heartbeat 每 TTL/3 上报一次 passing
+func (r *consulRegistry) heartbeat(endpoints []Endpoint, stop <-chan struct{}) {
+	interval := time.Duration(r.cfg.TTLSeconds) * time.Second / 3
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			for _, ep := range endpoints {
+				if err := r.client.Agent().UpdateTTL(checkID(ep), "", api.HealthPassing); err != nil {
+					logger.Warn("Consul 心跳失败", zap.String("service", ep.ServiceName), zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+func (r *consulRegistry) Deregister(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.endpoints) == 0 {
+		return nil
+	}
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+	err := r.deregisterEndpoints(r.endpoints)
+	r.endpoints = nil
+	return err
+}
+
+// deregisterEndpoints 逐个注销，返回最后一个错误
+func (r *consulRegistry) deregisterEndpoints(endpoints []Endpoint) error {
+	var lastErr error
+	for _, ep := range endpoints {
+		if err := r.client.Agent().ServiceDeregister(serviceID(ep)); err != nil {
+			lastErr = errors.Wrapf(err, "Consul 注销实例 %s 失败", ep.ServiceName)
+			continue
+		}
+		logger.Info("Consul 实例已注销", zap.String("service", ep.ServiceName))
+	}
+	return lastErr
+}
+
+// Describe 已注册实例
+func (r *consulRegistry) Describe() State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return State{
+		Backend:    Consul,
+		ServerAddr: r.cfg.Address,
+		Group:      r.group,
+		Registered: len(r.endpoints) > 0,
+		Weight:     r.weight,
+		Instances:  append([]Endpoint{}, r.endpoints...),
+	}
+}
+
+func (r *consulRegistry) NamingClient() NamingClient {
+	return r.naming
+}
+
+func consulWeight(w float64) int {
+	if n := int(w * consulWeightScale); n > 0 {
+		return n
+	}
+	return 1
+}
+
+type consulNaming struct {
+	client *api.Client
+	group  string
+
+	mu      sync.Mutex
+	watches map[string][]context.CancelFunc
+}
+
+func (n *consulNaming) tag(groupName string) string {
+	if groupName == "" {
+		return n.group
+	}
+	return groupName
+}
+
+func (n *consulNaming) SelectInstances(serviceName, groupName string) ([]ServiceInstance, error) {
+	instances, _, err := n.query(context.Background(), serviceName, groupName, 0)
+	return instances, err
+}
+
+// query 查询 passing 实例；waitIndex > 0 时为阻塞查询，直到变化或超时
+func (n *consulNaming) query(ctx context.Context, serviceName, groupName string, waitIndex uint64) ([]ServiceInstance, uint64, error) {
+	q := (&api.QueryOptions{WaitIndex: waitIndex, WaitTime: 5 * time.Minute}).WithContext(ctx)
+	entries, meta, err := n.client.Health().Service(serviceName, n.tag(groupName), true, q)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "Consul 查询服务 %s 失败", serviceName)
+	}
+	instances := make([]ServiceInstance, 0, len(entries))
+	for _, entry := range entries {
+		svc := entry.Service
+		ip := svc.Address
+		if ip == "" {
+			ip = entry.Node.Address
+		}
+		weight := 1.0
+		if svc.Weights.Passing > 0 {
+			weight = float64(svc.Weights.Passing) / consulWeightScale
+		}
+		instances = append(instances, ServiceInstance{
+			IP:       ip,
+			Port:     uint64(svc.Port),
+			Weight:   weight,
+			Healthy:  true,
+			Metadata: svc.Meta,
+		})
+	}
+	return instances, meta.LastIndex, nil
+}
+
+// Subscribe 阻塞查询（long polling），index 变化时回调全量实例
+func (n *consulNaming) Subscribe(serviceName, groupName string, callback func(instances []ServiceInstance)) error {
+	key := n.tag(groupName) + "/" + serviceName
+	ctx, cancel := context.WithCancel(context.Background())
+	n.mu.Lock()
+	n.watches[key] = append(n.watches[key], cancel)
+	n.mu.Unlock()
+
+	go func() {
+		var lastIndex uint64
+		for ctx.Err() == nil {
+			instances, index, err := n.query(ctx, serviceName, groupName, lastIndex)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Warn("Consul 订阅查询失败", zap.String("service", serviceName), zap.Error(err))
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+				continue
+			}
+			// index 回退（如 Consul 重启）时重新开始
+			if index < lastIndex {
+				lastIndex = 0
+				continue
+			}
+			if index != lastIndex {
+				callback(instances)
+			}
+			lastIndex = index
+		}
+	}()
+	return nil
+}
+
+func (n *consulNaming) Unsubscribe(serviceName, groupName string) error {
+	key := n.tag(groupName) + "/" + serviceName
+	n.mu.Lock()
+	cancels := n.watches[key]
+	delete(n.watches, key)
+	n.mu.Unlock()
+	for _, cancel := range cancels {
+		cancel()
+	}
+	return nil
+}
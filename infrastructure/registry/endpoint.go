@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+)
+
+// Endpoint 本进程待注册的一个实例（gRPC 或 HTTP）
+type Endpoint struct {
+	ServiceName string            `json:"serviceName"`
+	IP          string            `json:"ip"`
+	Port        uint64            `json:"port"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// Addr ip:port
+func (e Endpoint) Addr() string {
+	return net.JoinHostPort(e.IP, strconv.FormatUint(e.Port, 10))
+}
+
+// EndpointOptions 构建本实例注册信息所需的配置
+type EndpointOptions struct {
+	ServiceName     string
+	HTTPServiceName string
+	RegisterHTTP    bool
+	HTTPPort        int
+	Version         string
+}
+
+// LocalEndpoints 构建 gRPC 实例（grpcPort > 0）与可选的 HTTP 实例，各后端元数据一致：
+// version / protocol / language，gRPC 实例附带 httpPort，HTTP 实例附带 grpcPort
+func LocalEndpoints(opts EndpointOptions, grpcPort int, metadata map[string]string) ([]Endpoint, error) {
+	ip, err := LocalIP()
+	if err != nil {
+		return nil, err
+	}
+	var endpoints []Endpoint
+	if grpcPort > 0 {
+		md := baseMetadata(metadata, opts.Version, "grpc")
+		if opts.HTTPPort > 0 {
+			md["httpPort"] = strconv.Itoa(opts.HTTPPort)
+		}
+		endpoints = append(endpoints, Endpoint{ServiceName: opts.ServiceName, IP: ip, Port: uint64(grpcPort), Metadata: md})
+	}
+	if opts.RegisterHTTP && opts.HTTPPort > 0 {
+		md := baseMetadata(metadata, opts.Version, "http")
+		if grpcPort > 0 {
+			md["grpcPort"] = strconv.Itoa(grpcPort)
+		}
+		httpServiceName := opts.HTTPServiceName
+		if httpServiceName == "" {
+			httpServiceName = opts.ServiceName + HTTPServiceSuffix
+		}
+		endpoints = append(endpoints, Endpoint{ServiceName: httpServiceName, IP: ip, Port: uint64(opts.HTTPPort), Metadata: md})
+	}
+	return endpoints, nil
+}
+
+func baseMetadata(extra map[string]string, version, protocol string) map[string]string {
+	md := make(map[string]string, len(extra)+4)
+	for k, v := range extra {
+		md[k] = v
+	}
+	md["version"] = version
+	md["protocol"] = protocol
+	md["language"] = "go"
+	return md
+}
+
+// LocalIP 首个非回环 IPv4 地址，取不到时退回 hostname
+func LocalIP() (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			return ipNet.IP.String(), nil
+		}
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "127.0.0.1", nil
+	}
+	return hostname, nil
+}
+
+// defaultOptions etcd / consul 使用 plugins.registry 的服务名，未配置时沿用 nacos.serviceName / appName
+func defaultOptions(cfg *config.Config) EndpointOptions {
+	regCfg := cfg.Plugins.Registry
+	serviceName := regCfg.ServiceName
+	if serviceName == "" {
+		serviceName = cfg.Plugins.Nacos.ServiceName
+	}
+	if serviceName == "" {
+		serviceName = cfg.AppName
+	}
+	return EndpointOptions{
+		ServiceName:     serviceName,
+		HTTPServiceName: regCfg.HTTPServiceName,
+		RegisterHTTP:    regCfg.RegisterHTTP,
+		HTTPPort:        cfg.Server.Port,
+		Version:         cfg.Version,
+	}
+}
@@ -0,0 +1,317 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// etcdInstance etcd 中保存的实例信息（JSON）
+type etcdInstance struct {
+	IP       string            `json:"ip"`
+	Port     uint64            `json:"port"`
+	Weight   float64           `json:"weight"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type etcdRegistry struct {
+	cfg    config.EtcdRegistryConfig
+	opts   EndpointOptions
+	group  string
+	weight float64
+	client *clientv3.Client
+	naming *etcdNaming
+
+	mu        sync.Mutex
+	endpoints []Endpoint
+	leaseID   clientv3.LeaseID
+	stop      context.CancelFunc
+}
+
+func newEtcdRegistry(appCfg *config.Config) (Registry, error) {
+	cfg := appCfg.Plugins.Registry.Etcd
+	if len(cfg.Endpoints) == 0 {
+		return nil, errors.New("plugins.registry.etcd.endpoints 不能为空")
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "/muyi/services"
+	}
+	if cfg.TTLSeconds <= 0 {
+		cfg.TTLSeconds = 10
+	}
+	dialTimeout := time.Duration(cfg.DialTimeoutMs) * time.Millisecond
+	if dialTimeout <= 0 {
+		dialTimeout = 5 * time.Second
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Endpoints,
+		DialTimeout: dialTimeout,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "创建 etcd 客户端失败")
+	}
+	// 连接是惰性建立的，启动时探测一次以便不可用时降级
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	if _, err := client.Status(ctx, cfg.Endpoints[0]); err != nil {
+		_ = client.Close()
+		return nil, errors.Wrap(err, "连接 etcd 失败")
+	}
+
+	weight := appCfg.Plugins.Registry.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+	reg := &etcdRegistry{
+		cfg:    cfg,
+		opts:   defaultOptions(appCfg),
+		group:  Group(appCfg),
+		weight: weight,
+		client: client,
+		naming: &etcdNaming{client: client, prefix: cfg.Prefix, group: Group(appCfg), watches: map[string][]context.CancelFunc{}},
+	}
+	logger.Info("etcd 注册中心已启用",
+		zap.Strings("endpoints", cfg.Endpoints),
+		zap.String("prefix", cfg.Prefix),
+		zap.String("serviceName", reg.opts.ServiceName),
+	)
+	return reg, nil
+}
+
+func (r *etcdRegistry) Enabled() bool { return true }
+
+// Register 以租约写入实例并保持续约；租约丢失（如网络分区后过期）时自动重新注册
+func (r *etcdRegistry) Register(ctx context.Context, grpcPort int, metadata map[string]string) error {
+	endpoints, err := LocalEndpoints(r.opts, grpcPort, metadata)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+	leaseID, err := r.put(ctx, endpoints)
+	if err != nil {
+		return err
+	}
+
+	keepCtx, stop := context.WithCancel(context.Background())
+	r.mu.Lock()
+	r.endpoints = endpoints
+	r.leaseID = leaseID
+	r.stop = stop
+	r.mu.Unlock()
+	go r.keepAlive(keepCtx, leaseID)
+
+	for _, ep := range endpoints {
+		logger.Info("etcd 注册成功",
+			zap.String("service", ep.ServiceName),
+			zap.String("addr", ep.Addr()),
+			zap.String("protocol", ep.Metadata["protocol"]),
+		)
+	}
+	return nil
+}
+
+// put 申请租约并写入全部实例
+func (r *etcdRegistry) put(ctx context.Context, endpoints []Endpoint) (clientv3.LeaseID, error) {
+	lease, err := r.client.Grant(ctx, int64(r.cfg.TTLSeconds))
+	if err != nil {
+		return 0, errors.Wrap(err, "etcd 申请租约失败")
+	}
+	ops := make([]clientv3.Op, 0, len(endpoints))
+	for _, ep := range endpoints {
+		value, err := json.Marshal(etcdInstance{IP: ep.IP, Port: ep.Port, Weight: r.weight, Metadata: ep.Metadata})
+		if err != nil {
+			return 0, errors.Wrap(err, "序列化实例信息失败")
+		}
+		ops = append(ops, clientv3.OpPut(r.naming.instanceKey(r.group, ep.ServiceName, ep.Addr()), string(value), clientv3.WithLease(lease.ID)))
+	}
+	if _, err := r.client.Txn(ctx).Then(ops...).Commit(); err != nil {
+		_, _ = r.client.Revoke(context.Background(), lease.ID)
+		return 0, errors.Wrap(err, "etcd 写入实例失败")
+	}
+	return lease.ID, nil
+}
+
+func (r *etcdRegistry) keepAlive(ctx context.Context, leaseID clientv3.LeaseID) {
+	for {
+		ch, err := r.client.KeepAlive(ctx, leaseID)
+		if err == nil {
+			for range ch {
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("etcd 租约续约中断，重新注册", zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+
+		r.mu.Lock()
+		endpoints := r.endpoints
+		r.mu.Unlock()
+		putCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		newID, err := r.put(putCtx, endpoints)
+		cancel()
+		if err != nil {
+			logger.Warn("etcd 重新注册失败", zap.Error(err))
+			continue
+		}
+		r.mu.Lock()
+		if ctx.Err() != nil {
+			// 重新注册期间已 Deregister
+			r.mu.Unlock()
+			_, _ = r.client.Revoke(context.Background(), newID)
+			return
+		}
+		r.leaseID = newID
+		r.mu.Unlock()
+		leaseID = newID
+	}
+}
+
+// Deregister 撤销租约，关联的实例 key 随之删除
+func (r *etcdRegistry) Deregister(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.endpoints) == 0 {
+		return nil
+	}
+	if r.stop != nil {
+		r.stop()
+		r.stop = nil
+	}
+	r.endpoints = nil
+	if _, err := r.client.Revoke(ctx, r.leaseID); err != nil {
+		return errors.Wrap(err, "etcd 注销实例失败")
+	}
+	logger.Info("etcd 实例已注销")
+	return nil
+}
+
+// Describe 已注册实例
+func (r *etcdRegistry) Describe() State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return State{
+		Backend:    Etcd,
+		ServerAddr: strings.Join(r.cfg.Endpoints, ","),
+		Group:      r.group,
+		Registered: len(r.endpoints) > 0,
+		Weight:     r.weight,
+		Instances:  append([]Endpoint{}, r.endpoints...),
+	}
+}
+
+func (r *etcdRegistry) NamingClient() NamingClient {
+	return r.naming
+}
+
+type etcdNaming struct {
+	client *clientv3.Client
+	prefix string
+	group  string
+
+	mu      sync.Mutex
+	watches map[string][]context.CancelFunc
+}
+
+func (n *etcdNaming) serviceKey(groupName, serviceName string) string {
+	if groupName == "" {
+		groupName = n.group
+	}
+	return path.Join(n.prefix, groupName, serviceName) + "/"
+}
+
+func (n *etcdNaming) instanceKey(groupName, serviceName, addr string) string {
+	return n.serviceKey(groupName, serviceName) + addr
+}
+
+func (n *etcdNaming) SelectInstances(serviceName, groupName string) ([]ServiceInstance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := n.client.Get(ctx, n.serviceKey(groupName, serviceName), clientv3.WithPrefix())
+	if err != nil {
+		return nil, errors.Wrapf(err, "etcd 查询服务 %s 失败", serviceName)
+	}
+	instances := make([]ServiceInstance, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var inst etcdInstance
+		if err := json.Unmarshal(kv.Value, &inst); err != nil {
+			logger.Warn("etcd 实例信息格式错误", zap.String("key", string(kv.Key)), zap.Error(err))
+			continue
+		}
+		instances = append(instances, ServiceInstance{
+			IP:       inst.IP,
+			Port:     inst.Port,
+			Weight:   inst.Weight,
+			Healthy:  true,
+			Metadata: inst.Metadata,
+		})
+	}
+	return instances, nil
+}
+
+// Subscribe watch 服务前缀，任一实例变化时重新查询全量实例回调；watch 中断（如失去 leader）后重建并全量刷新
+func (n *etcdNaming) Subscribe(serviceName, groupName string, callback func(instances []ServiceInstance)) error {
+	key := n.serviceKey(groupName, serviceName)
+	ctx, cancel := context.WithCancel(context.Background())
+	n.mu.Lock()
+	n.watches[key] = append(n.watches[key], cancel)
+	n.mu.Unlock()
+
+	refresh := func() {
+		instances, err := n.SelectInstances(serviceName, groupName)
+		if err != nil {
+			logger.Warn("etcd 刷新实例失败", zap.String("service", serviceName), zap.Error(err))
+			return
+		}
+		callback(instances)
+	}
+	go func() {
+		for first := true; ctx.Err() == nil; first = false {
+			watchCh := n.client.Watch(clientv3.WithRequireLeader(ctx), key, clientv3.WithPrefix())
+			if !first {
+				refresh()
+			}
+			for resp := range watchCh {
+				if err := resp.Err(); err != nil {
+					logger.Warn("etcd watch 错误", zap.String("service", serviceName), zap.Error(err))
+					continue
+				}
+				refresh()
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}()
+	return nil
+}
+
+func (n *etcdNaming) Unsubscribe(serviceName, groupName string) error {
+	key := n.serviceKey(groupName, serviceName)
+	n.mu.Lock()
+	cancels := n.watches[key]
+	delete(n.watches, key)
+	n.mu.Unlock()
+	for _, cancel := range cancels {
+		cancel()
+	}
+	return nil
+}
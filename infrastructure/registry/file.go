@@ -0,0 +1,210 @@
+package registry
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// fileContent 实例清单格式：
+//
+//	services:
+//	  example-producer:
+//	    - addr: 127.0.0.1:9081
+//	      weight: 1
+//	      metadata: { version: v2 }
+//	  example-producer-http:
+//	    - addr: 127.0.0.1:8081
+type fileContent struct {
+	Services map[string][]fileInstance `yaml:"services"`
+}
+
+type fileInstance struct {
+	Addr string `yaml:"addr"`
+	// Weight 默认 1
+	Weight *float64 `yaml:"weight"`
+	// Healthy 默认 true，置为 false 可临时摘除实例
+	Healthy  *bool             `yaml:"healthy"`
+	Metadata map[string]string `yaml:"metadata"`
+}
+
+// fileRegistry 只读的本地注册中心，group 被忽略
+type fileRegistry struct {
+	path    string
+	watcher *fsnotify.Watcher
+
+	mu       sync.RWMutex
+	services map[string][]ServiceInstance
+	subs     map[string][]func(instances []ServiceInstance)
+}
+
+func newFileRegistry(appCfg *config.Config) (Registry, error) {
+	path := appCfg.Plugins.Registry.File.Path
+	if path == "" {
+		return nil, errors.New("plugins.registry.file.path 不能为空")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "解析注册文件路径 %s 失败", path)
+	}
+	r := &fileRegistry{path: abs, subs: map[string][]func(instances []ServiceInstance){}}
+	services, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.services = services
+
+	// 监听所在目录：编辑器保存时常以重命名替换文件
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "创建文件监听失败")
+	}
+	if err := watcher.Add(filepath.Dir(abs)); err != nil {
+		_ = watcher.Close()
+		return nil, errors.Wrapf(err, "监听注册文件 %s 失败", abs)
+	}
+	r.watcher = watcher
+	go r.watch()
+
+	logger.Info("file 注册中心已启用", zap.String("path", abs), zap.Int("services", len(services)))
+	return r, nil
+}
+
+func (r *fileRegistry) load() (map[string][]ServiceInstance, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "读取注册文件 %s 失败", r.path)
+	}
+	var content fileContent
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, errors.Wrapf(err, "解析注册文件 %s 失败", r.path)
+	}
+	services := make(map[string][]ServiceInstance, len(content.Services))
+	for name, list := range content.Services {
+		instances := make([]ServiceInstance, 0, len(list))
+		for _, item := range list {
+			host, portStr, err := net.SplitHostPort(item.Addr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "服务 %s 的地址 %q 无效", name, item.Addr)
+			}
+			port, err := strconv.ParseUint(portStr, 10, 16)
+			if err != nil {
+				return nil, errors.Wrapf(err, "服务 %s 的端口 %q 无效", name, portStr)
+			}
+			inst := ServiceInstance{IP: host, Port: port, Weight: 1, Healthy: true, Metadata: item.Metadata}
+			if item.Weight != nil {
+				inst.Weight = *item.Weight
+			}
+			if item.Healthy != nil {
+				inst.Healthy = *item.Healthy
+			}
+			instances = append(instances, inst)
+		}
+		services[name] = instances
+	}
+	return services, nil
+}
+
+func (r *fileRegistry) watch() {
+	for {
+		select {
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != r.path || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			r.reload()
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warn("注册文件监听错误", zap.Error(err))
+		}
+	}
+}
+
+// reload 重新加载并通知实例有变化的订阅者；文件格式错误时保留旧内容。
+// 覆盖写入时会先截断再写入，空文件视为写入未完成，等待下一次写事件
+func (r *fileRegistry) reload() {
+	if info, err := os.Stat(r.path); err == nil && info.Size() == 0 {
+		return
+	}
+	services, err := r.load()
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("注册文件重新加载失败，沿用旧内容", zap.Error(err))
+		}
+		return
+	}
+
+	type notify struct {
+		callbacks []func(instances []ServiceInstance)
+		instances []ServiceInstance
+	}
+	var pending []notify
+	r.mu.Lock()
+	old := r.services
+	r.services = services
+	for name, callbacks := range r.subs {
+		if !reflect.DeepEqual(old[name], services[name]) {
+			pending = append(pending, notify{callbacks: append(callbacks[:0:0], callbacks...), instances: services[name]})
+		}
+	}
+	r.mu.Unlock()
+
+	logger.Info("注册文件已重新加载", zap.String("path", r.path))
+	for _, n := range pending {
+		for _, callback := range n.callbacks {
+			callback(n.instances)
+		}
+	}
+}
+
+func (r *fileRegistry) Enabled() bool { return true }
+
+// Register 文件注册中心只读，本实例需手动写入清单
+func (r *fileRegistry) Register(_ context.Context, _ int, _ map[string]string) error {
+	logger.Info("file 注册中心为只读，跳过注册", zap.String("path", r.path))
+	return nil
+}
+
+func (r *fileRegistry) Deregister(_ context.Context) error {
+	return nil
+}
+
+func (r *fileRegistry) NamingClient() NamingClient {
+	return r
+}
+
+// SelectInstances 清单中暂无该服务时返回空列表，补充后经订阅推送
+func (r *fileRegistry) SelectInstances(serviceName, _ string) ([]ServiceInstance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.services[serviceName], nil
+}
+
+func (r *fileRegistry) Subscribe(serviceName, _ string, callback func(instances []ServiceInstance)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs[serviceName] = append(r.subs[serviceName], callback)
+	return nil
+}
+
+func (r *fileRegistry) Unsubscribe(serviceName, _ string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subs, serviceName)
+	return nil
+}
@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+)
+
+func newTestFileRegistry(t *testing.T, content string) (*fileRegistry, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Plugins.Registry.File.Path = path
+	reg, err := newFileRegistry(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := reg.(*fileRegistry)
+	t.Cleanup(func() { _ = r.watcher.Close() })
+	return r, path
+}
+
+func TestFileRegistryLoad(t *testing.T) {
+	r, _ := newTestFileRegistry(t, `
+services:
+  producer:
+    - addr: 10.0.0.1:9081
+    - addr: 10.0.0.2:9081
+      weight: 3
+      healthy: false
+      metadata: { version: v2 }
+`)
+	instances, err := r.SelectInstances("producer", "ignored")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("instances = %+v", instances)
+	}
+	if got := instances[0]; got.IP != "10.0.0.1" || got.Port != 9081 || got.Weight != 1 || !got.Healthy {
+		t.Errorf("defaults not applied: %+v", got)
+	}
+	if got := instances[1]; got.Weight != 3 || got.Healthy || got.Metadata["version"] != "v2" {
+		t.Errorf("explicit values lost: %+v", got)
+	}
+	if missing, _ := r.SelectInstances("unknown", ""); len(missing) != 0 {
+		t.Errorf("unknown service = %+v", missing)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	_ = os.WriteFile(bad, []byte("services:\n  producer:\n    - addr: no-port\n"), 0o644)
+	cfg := &config.Config{}
+	cfg.Plugins.Registry.File.Path = bad
+	if _, err := newFileRegistry(cfg); err == nil {
+		t.Error("expected error for address without port")
+	}
+}
+
+func TestFileRegistryReload(t *testing.T) {
+	r, path := newTestFileRegistry(t, `
+services:
+  producer:
+    - addr: 10.0.0.1:9081
+  other:
+    - addr: 10.0.0.9:9081
+`)
+	updates := make(chan []ServiceInstance, 4)
+	_ = r.Subscribe("producer", "", func(instances []ServiceInstance) { updates <- instances })
+	otherUpdates := make(chan []ServiceInstance, 4)
+	_ = r.Subscribe("other", "", func(instances []ServiceInstance) { otherUpdates <- instances })
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`
+services:
+  producer:
+    - addr: 10.0.0.1:9081
+    - addr: 10.0.0.3:9081
+  other:
+    - addr: 10.0.0.9:9081
+`)
+	select {
+	case instances := <-updates:
+		if len(instances) != 2 || instances[1].IP != "10.0.0.3" {
+			t.Fatalf("pushed instances = %+v", instances)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("subscriber not notified")
+	}
+	select {
+	case instances := <-otherUpdates:
+		t.Fatalf("unchanged service notified: %+v", instances)
+	default:
+	}
+
+	// 格式错误时保留旧内容
+	write("services: [")
+	time.Sleep(200 * time.Millisecond)
+	if instances, _ := r.SelectInstances("producer", ""); len(instances) != 2 {
+		t.Fatalf("invalid file replaced instances: %+v", instances)
+	}
+}
@@ -0,0 +1,157 @@
+// Package registry 服务注册与发现抽象。
+//
+// 由 plugins.rpc.registry 选择后端：nacos（infrastructure/nacos）、etcd、consul、file；
+// static 或未启用时为 noop。gRPC resolver 与 HTTP 客户端只依赖 NamingClient。
+package registry
+
+import (
+	"context"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// logger 命名日志，级别可由 log.levels.registry 单独调整
+var logger = myLogger.Named("registry")
+
+// Registry 服务注册与发现接口（可插拔）
+type Registry interface {
+	Enabled() bool
+	// Register grpcPort 为 0 时仅注册 HTTP 实例（需开启 registerHttp）
+	Register(ctx context.Context, grpcPort int, metadata map[string]string) error
+	Deregister(ctx context.Context) error
+	NamingClient() NamingClient
+}
+
+// Describer 可选接口：后端描述本实例的注册状态，供管理接口展示
+type Describer interface {
+	Describe() State
+}
+
+// State 本实例在注册中心的状态
+type State struct {
+	Backend    string     `json:"backend"`
+	ServerAddr string     `json:"serverAddr,omitempty"`
+	Namespace  string     `json:"namespace,omitempty"`
+	Group      string     `json:"group,omitempty"`
+	Registered bool       `json:"registered"`
+	Weight     float64    `json:"weight,omitempty"`
+	Instances  []Endpoint `json:"instances"`
+}
+
+// NamingClient 供 gRPC Resolver 与 HTTP 客户端使用的命名服务抽象
+type NamingClient interface {
+	// Subscribe 实例变更时以全量健康实例回调
+	Subscribe(serviceName, groupName string, callback func(instances []ServiceInstance)) error
+	Unsubscribe(serviceName, groupName string) error
+	SelectInstances(serviceName, groupName string) ([]ServiceInstance, error)
+}
+
+// ServiceInstance 服务实例，Weight 与 Nacos 一致（默认 1.0）
+type ServiceInstance struct {
+	IP       string
+	Port     uint64
+	Weight   float64
+	Healthy  bool
+	Metadata map[string]string
+}
+
+// HTTPServiceSuffix HTTP 服务实例默认服务名后缀（serviceName + "-http"）
+const HTTPServiceSuffix = "-http"
+
+// DefaultGroup 未配置 plugins.nacos.group 时的默认分组
+const DefaultGroup = "XI_PLATFORM"
+
+// 后端名称，与 plugins.rpc.registry 取值一致
+const (
+	Nacos  = "nacos"
+	Etcd   = "etcd"
+	Consul = "consul"
+	File   = "file"
+	Static = "static"
+)
+
+// Factory 按配置创建 Registry
+type Factory func(cfg *config.Config) (Registry, error)
+
+var factories = map[string]Factory{
+	Etcd:   newEtcdRegistry,
+	Consul: newConsulRegistry,
+	File:   newFileRegistry,
+}
+
+var globalRegistry Registry = &noopRegistry{}
+
+// RegisterFactory 注册后端（nacos 包在 init 中注册），同名覆盖
+func RegisterFactory(name string, f Factory) {
+	factories[name] = f
+}
+
+// InitWithError 按 plugins.rpc.registry 初始化，失败时降级为 noop 并返回错误
+func InitWithError(cfg *config.Config) (Registry, error) {
+	name := Backend(cfg)
+	if cfg == nil || name == Static {
+		globalRegistry = &noopRegistry{}
+		return globalRegistry, nil
+	}
+	f, ok := factories[name]
+	if !ok {
+		globalRegistry = &noopRegistry{}
+		return globalRegistry, errors.Errorf("不支持的注册中心: %s", name)
+	}
+	reg, err := f(cfg)
+	if err != nil {
+		globalRegistry = &noopRegistry{}
+		return globalRegistry, errors.Wrapf(err, "初始化注册中心 %s 失败", name)
+	}
+	globalRegistry = reg
+	return globalRegistry, nil
+}
+
+// Init 初始化注册中心（失败时降级为 noop，不阻断启动）
+func Init(cfg *config.Config) Registry {
+	reg, err := InitWithError(cfg)
+	if err != nil {
+		logger.Warn("注册中心初始化失败，降级为 noop", zap.Error(err))
+	}
+	return reg
+}
+
+// GetRegistry 获取全局 Registry
+func GetRegistry() Registry {
+	return globalRegistry
+}
+
+// SetRegistry 设置 Registry（测试用）
+func SetRegistry(r Registry) {
+	globalRegistry = r
+}
+
+// Backend 配置的后端名称，默认 nacos
+func Backend(cfg *config.Config) string {
+	if cfg == nil || cfg.Plugins.Rpc.Registry == "" {
+		return Nacos
+	}
+	return cfg.Plugins.Rpc.Registry
+}
+
+// Group 服务分组，各后端共用 plugins.nacos.group
+func Group(cfg *config.Config) string {
+	if cfg != nil && cfg.Plugins.Nacos.Group != "" {
+		return cfg.Plugins.Nacos.Group
+	}
+	return DefaultGroup
+}
+
+// RegisterHTTPEnabled 当前后端是否开启了 HTTP 实例注册
+func RegisterHTTPEnabled(cfg *config.Config) bool {
+	if cfg == nil {
+		return false
+	}
+	if Backend(cfg) == Nacos {
+		return cfg.Plugins.Nacos.RegisterHTTP
+	}
+	return cfg.Plugins.Registry.RegisterHTTP
+}
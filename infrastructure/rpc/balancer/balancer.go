@@ -0,0 +1,267 @@
+// Package balancer 基于注册中心权重与实例元数据的 gRPC 负载均衡策略。
+//
+// 通过 plugins.rpc.client.loadBalancer / routing 按服务选择：
+// round_robin、weighted_round_robin、consistent_hash、least_request，均支持按实例元数据（version / zone 等）路由。
+package balancer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+// 配置中使用的策略名
+const (
+	RoundRobin         = "round_robin"
+	WeightedRoundRobin = "weighted_round_robin"
+	ConsistentHash     = "consistent_hash"
+	LeastRequest       = "least_request"
+)
+
+// 注册到 grpc 的名称加前缀，避免与 grpc 内置策略冲突
+const namePrefix = "muyi_"
+
+// Config 单个服务的负载均衡配置，序列化进 gRPC service config
+type Config struct {
+	serviceconfig.LoadBalancingConfig `json:"-"`
+
+	// HashKey consistent_hash 从 outgoing metadata 读取哈希键的 key（如 x-sso-id），WithHashKey 优先
+	HashKey string `json:"hashKey,omitempty"`
+	// Tags 实例元数据须全部匹配（如 version=v2），WithRouteTags 可按请求追加
+	Tags map[string]string `json:"tags,omitempty"`
+	// TagFallback 无匹配实例时退回全部实例，否则返回 Unavailable
+	TagFallback bool `json:"tagFallback,omitempty"`
+}
+
+var registerOnce sync.Once
+
+// Register 注册全部策略，可重复调用
+func Register() {
+	registerOnce.Do(func() {
+		balancer.Register(newBuilder(RoundRobin, newRoundRobinPicker))
+		balancer.Register(newBuilder(WeightedRoundRobin, newWeightedPicker))
+		balancer.Register(newBuilder(ConsistentHash, newHashPicker))
+		balancer.Register(newBuilder(LeastRequest, newLeastRequestPicker))
+	})
+}
+
+// Supported 是否为支持的策略名
+func Supported(policy string) bool {
+	switch policy {
+	case RoundRobin, WeightedRoundRobin, ConsistentHash, LeastRequest:
+		return true
+	}
+	return false
+}
+
+// ServiceConfigJSON 生成 grpc.WithDefaultServiceConfig 使用的 JSON
+func ServiceConfigJSON(policy string, cfg Config) (string, error) {
+	if policy == "" {
+		policy = RoundRobin
+	}
+	if !Supported(policy) {
+		return "", fmt.Errorf("不支持的负载均衡策略: %s", policy)
+	}
+	body, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`{"loadBalancingConfig":[{%q:%s}]}`, namePrefix+policy, body), nil
+}
+
+// pickerFactory 由就绪实例构建 picker；实例已按 Tags 过滤
+type pickerFactory func(cfg *Config, ready []*endpoint) balancer.Picker
+
+type builder struct {
+	name      string
+	newPicker pickerFactory
+}
+
+func newBuilder(policy string, newPicker pickerFactory) balancer.Builder {
+	return &builder{name: namePrefix + policy, newPicker: newPicker}
+}
+
+func (b *builder) Name() string { return b.name }
+
+func (b *builder) ParseConfig(raw json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	cfg := &Config{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, cfg); err != nil {
+			return nil, fmt.Errorf("%s: 解析配置失败: %w", b.name, err)
+		}
+	}
+	return cfg, nil
+}
+
+func (b *builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	w := &configurableBalancer{cfg: &Config{}, instances: map[string]Instance{}, inflight: map[balancer.SubConn]*int64{}}
+	pb := pickerBuilderFunc(func(info base.PickerBuildInfo) balancer.Picker {
+		if len(info.ReadySCs) == 0 {
+			return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+		}
+		cfg, instances := w.snapshot()
+		counters := w.inflightCounters(info.ReadySCs)
+		ready := make([]*endpoint, 0, len(info.ReadySCs))
+		for sc, sci := range info.ReadySCs {
+			inst, ok := instances[sci.Address.Addr]
+			if !ok {
+				inst = InstanceFromAddress(sci.Address)
+			}
+			ready = append(ready, &endpoint{sc: sc, addr: sci.Address.Addr, inst: inst, inflight: counters[sc]})
+		}
+		sortEndpoints(ready)
+		return &routingPicker{cfg: cfg, all: ready, newPicker: b.newPicker, routes: map[string]balancer.Picker{}, pickers: map[string]balancer.Picker{}}
+	})
+	w.Balancer = base.NewBalancerBuilder(b.name, pb, base.Config{HealthCheck: true}).Build(cc, opts)
+	return w
+}
+
+type pickerBuilderFunc func(info base.PickerBuildInfo) balancer.Picker
+
+func (f pickerBuilderFunc) Build(info base.PickerBuildInfo) balancer.Picker { return f(info) }
+
+// configurableBalancer 记录最新配置与实例信息；base 不会因权重变化重建 SubConn，故按地址取最新值
+type configurableBalancer struct {
+	balancer.Balancer
+
+	mu        sync.RWMutex
+	cfg       *Config
+	instances map[string]Instance
+	inflight  map[balancer.SubConn]*int64
+}
+
+func (w *configurableBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	instances := make(map[string]Instance, len(s.ResolverState.Addresses))
+	for _, addr := range s.ResolverState.Addresses {
+		instances[addr.Addr] = InstanceFromAddress(addr)
+	}
+	w.mu.Lock()
+	if cfg, ok := s.BalancerConfig.(*Config); ok && cfg != nil {
+		w.cfg = cfg
+	}
+	w.instances = instances
+	w.mu.Unlock()
+	return w.Balancer.UpdateClientConnState(s)
+}
+
+func (w *configurableBalancer) snapshot() (*Config, map[string]Instance) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.cfg, w.instances
+}
+
+// inflightCounters 为就绪 SubConn 分配计数器，移除已下线的
+func (w *configurableBalancer) inflightCounters(ready map[balancer.SubConn]base.SubConnInfo) map[balancer.SubConn]*int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	counters := make(map[balancer.SubConn]*int64, len(ready))
+	for sc := range ready {
+		c, ok := w.inflight[sc]
+		if !ok {
+			c = new(int64)
+		}
+		counters[sc] = c
+	}
+	w.inflight = counters
+	return counters
+}
+
+// maxRouteCache 标签组合快速路径的上限；标签值来自请求，超过后清空重建
+const maxRouteCache = 256
+
+// routingPicker 按配置与请求级 Tags 过滤实例，再交给具体策略。
+// picker 按命中的实例集合复用（数量受当前地址集合约束，同一集合的不同标签组合共享轮询与计数状态），
+// routes 仅作为标签组合到 picker 的有界快速路径
+type routingPicker struct {
+	cfg       *Config
+	all       []*endpoint
+	newPicker pickerFactory
+
+	mu      sync.Mutex
+	routes  map[string]balancer.Picker
+	pickers map[string]balancer.Picker
+}
+
+func (p *routingPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	tags := mergeTags(p.cfg.Tags, RouteTagsFromContext(info.Ctx))
+	key := tagsKey(tags)
+
+	p.mu.Lock()
+	picker, ok := p.routes[key]
+	if !ok {
+		picker = p.pickerFor(tags)
+		if len(p.routes) >= maxRouteCache {
+			p.routes = make(map[string]balancer.Picker, maxRouteCache)
+		}
+		p.routes[key] = picker
+	}
+	p.mu.Unlock()
+	return picker.Pick(info)
+}
+
+// pickerFor 调用方须持有 mu
+func (p *routingPicker) pickerFor(tags map[string]string) balancer.Picker {
+	candidates := filterByTags(p.all, tags)
+	if len(candidates) == 0 && p.cfg.TagFallback {
+		candidates = p.all
+	}
+	if len(candidates) == 0 {
+		return base.NewErrPicker(errNoMatchingInstance(tags))
+	}
+	addrs := make([]string, len(candidates))
+	for i, ep := range candidates {
+		addrs[i] = ep.addr
+	}
+	setKey := strings.Join(addrs, ",")
+	picker, ok := p.pickers[setKey]
+	if !ok {
+		picker = p.newPicker(p.cfg, candidates)
+		p.pickers[setKey] = picker
+	}
+	return picker
+}
+
+func mergeTags(static, dynamic map[string]string) map[string]string {
+	if len(dynamic) == 0 {
+		return static
+	}
+	out := make(map[string]string, len(static)+len(dynamic))
+	for k, v := range static {
+		out[k] = v
+	}
+	for k, v := range dynamic {
+		out[k] = v
+	}
+	return out
+}
+
+func filterByTags(all []*endpoint, tags map[string]string) []*endpoint {
+	if len(tags) == 0 {
+		return all
+	}
+	out := make([]*endpoint, 0, len(all))
+	for _, ep := range all {
+		if ep.inst.Matches(tags) {
+			out = append(out, ep)
+		}
+	}
+	return out
+}
+
+func tagsKey(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(tags))
+	for k, v := range tags {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
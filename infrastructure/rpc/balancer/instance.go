@@ -0,0 +1,114 @@
+package balancer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+
+	grpcbalancer "google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultWeight 注册中心未提供权重时的默认值
+const DefaultWeight = 100
+
+type instanceKey struct{}
+
+// Instance 实例权重与元数据，由 resolver 写入 resolver.Address.BalancerAttributes
+type Instance struct {
+	// Weight 相对权重，0 表示不分配流量（全部为 0 时视为等权）
+	Weight   int
+	Metadata map[string]string
+}
+
+// Equal 供 attributes.Equal 比较（map 不可直接比较）
+func (i Instance) Equal(o any) bool {
+	other, ok := o.(Instance)
+	return ok && i.Weight == other.Weight && maps.Equal(i.Metadata, other.Metadata)
+}
+
+// Matches 元数据是否包含全部 tags
+func (i Instance) Matches(tags map[string]string) bool {
+	for k, v := range tags {
+		if i.Metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// WithInstance 将实例信息写入地址
+func WithInstance(addr resolver.Address, inst Instance) resolver.Address {
+	addr.BalancerAttributes = addr.BalancerAttributes.WithValue(instanceKey{}, inst)
+	return addr
+}
+
+// InstanceFromAddress 读取实例信息，未设置时返回默认权重
+func InstanceFromAddress(addr resolver.Address) Instance {
+	if inst, ok := addr.BalancerAttributes.Value(instanceKey{}).(Instance); ok {
+		return inst
+	}
+	return Instance{Weight: DefaultWeight}
+}
+
+// NacosWeight Nacos 权重（默认 1.0）换算为整数权重
+func NacosWeight(w float64) int {
+	if w <= 0 {
+		return 0
+	}
+	if n := int(w * DefaultWeight); n > 0 {
+		return n
+	}
+	return 1
+}
+
+type hashKeyCtxKey struct{}
+type routeTagsCtxKey struct{}
+
+// WithHashKey 指定本次调用的一致性哈希键（如用户 ID），优先于配置的 hashKey
+func WithHashKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKeyCtxKey{}, key)
+}
+
+// HashKeyFromContext 读取 WithHashKey 写入的哈希键
+func HashKeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	key, _ := ctx.Value(hashKeyCtxKey{}).(string)
+	return key
+}
+
+// WithRouteTags 本次调用仅路由到元数据匹配的实例（如灰度流量 version=v2），与配置的 tags 合并
+func WithRouteTags(ctx context.Context, tags map[string]string) context.Context {
+	return context.WithValue(ctx, routeTagsCtxKey{}, tags)
+}
+
+// RouteTagsFromContext 读取 WithRouteTags 写入的标签
+func RouteTagsFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	tags, _ := ctx.Value(routeTagsCtxKey{}).(map[string]string)
+	return tags
+}
+
+// endpoint 就绪实例
+type endpoint struct {
+	sc   grpcbalancer.SubConn
+	addr string
+	inst Instance
+	// inflight 进行中的请求数，picker 重建后沿用
+	inflight *int64
+}
+
+func sortEndpoints(eps []*endpoint) {
+	sort.Slice(eps, func(i, j int) bool { return eps[i].addr < eps[j].addr })
+}
+
+func errNoMatchingInstance(tags map[string]string) error {
+	return status.Error(codes.Unavailable, fmt.Sprintf("没有匹配路由标签 %v 的可用实例", tags))
+}
@@ -0,0 +1,150 @@
+package balancer
+
+import (
+	"hash/crc32"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/metadata"
+)
+
+// roundRobinPicker 等权轮询
+type roundRobinPicker struct {
+	eps  []*endpoint
+	next uint32
+}
+
+func newRoundRobinPicker(_ *Config, ready []*endpoint) balancer.Picker {
+	return &roundRobinPicker{eps: ready, next: uint32(rand.Intn(len(ready)))}
+}
+
+func (p *roundRobinPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	n := atomic.AddUint32(&p.next, 1)
+	return balancer.PickResult{SubConn: p.eps[int(n)%len(p.eps)].sc}, nil
+}
+
+// weightedPicker 平滑加权轮询（同 nginx），权重为 0 的实例不分配流量
+type weightedPicker struct {
+	mu      sync.Mutex
+	eps     []*endpoint
+	weights []int
+	current []int
+	total   int
+}
+
+func newWeightedPicker(_ *Config, ready []*endpoint) balancer.Picker {
+	p := &weightedPicker{}
+	for _, ep := range ready {
+		if ep.inst.Weight > 0 {
+			p.eps = append(p.eps, ep)
+			p.weights = append(p.weights, ep.inst.Weight)
+			p.total += ep.inst.Weight
+		}
+	}
+	if len(p.eps) == 0 {
+		return newRoundRobinPicker(nil, ready)
+	}
+	p.current = make([]int, len(p.eps))
+	return p
+}
+
+func (p *weightedPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	best := 0
+	for i := range p.eps {
+		p.current[i] += p.weights[i]
+		if p.current[i] > p.current[best] {
+			best = i
+		}
+	}
+	p.current[best] -= p.total
+	return balancer.PickResult{SubConn: p.eps[best].sc}, nil
+}
+
+// virtualNodesPerWeight 每单位默认权重（100）对应的虚拟节点数
+const virtualNodesPerWeight = 100
+
+// hashPicker 一致性哈希环，虚拟节点数与权重成正比；无哈希键时随机选择
+type hashPicker struct {
+	hashKey string
+	ring    []uint32
+	owners  map[uint32]*endpoint
+	eps     []*endpoint
+}
+
+func newHashPicker(cfg *Config, ready []*endpoint) balancer.Picker {
+	p := &hashPicker{hashKey: cfg.HashKey, owners: map[uint32]*endpoint{}}
+	for _, ep := range ready {
+		if ep.inst.Weight <= 0 {
+			continue
+		}
+		p.eps = append(p.eps, ep)
+		replicas := ep.inst.Weight * virtualNodesPerWeight / DefaultWeight
+		if replicas < 1 {
+			replicas = 1
+		}
+		for i := 0; i < replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(ep.addr + "#" + strconv.Itoa(i)))
+			if _, exists := p.owners[h]; exists {
+				continue
+			}
+			p.owners[h] = ep
+			p.ring = append(p.ring, h)
+		}
+	}
+	if len(p.eps) == 0 {
+		return newRoundRobinPicker(nil, ready)
+	}
+	sort.Slice(p.ring, func(i, j int) bool { return p.ring[i] < p.ring[j] })
+	return p
+}
+
+func (p *hashPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	key := HashKeyFromContext(info.Ctx)
+	if key == "" && p.hashKey != "" {
+		if md, ok := metadata.FromOutgoingContext(info.Ctx); ok {
+			if vals := md.Get(p.hashKey); len(vals) > 0 {
+				key = vals[0]
+			}
+		}
+	}
+	if key == "" {
+		return balancer.PickResult{SubConn: p.eps[rand.Intn(len(p.eps))].sc}, nil
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(p.ring), func(i int) bool { return p.ring[i] >= h })
+	if i == len(p.ring) {
+		i = 0
+	}
+	return balancer.PickResult{SubConn: p.owners[p.ring[i]].sc}, nil
+}
+
+// leastRequestPicker 随机取两个实例，选进行中请求较少者（power of two choices）
+type leastRequestPicker struct {
+	eps []*endpoint
+}
+
+func newLeastRequestPicker(_ *Config, ready []*endpoint) balancer.Picker {
+	return &leastRequestPicker{eps: ready}
+}
+
+func (p *leastRequestPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	chosen := p.eps[rand.Intn(len(p.eps))]
+	if len(p.eps) > 1 {
+		other := p.eps[rand.Intn(len(p.eps))]
+		if atomic.LoadInt64(other.inflight) < atomic.LoadInt64(chosen.inflight) {
+			chosen = other
+		}
+	}
+	counter := chosen.inflight
+	atomic.AddInt64(counter, 1)
+	return balancer.PickResult{
+		SubConn: chosen.sc,
+		Done:    func(balancer.DoneInfo) { atomic.AddInt64(counter, -1) },
+	}, nil
+}
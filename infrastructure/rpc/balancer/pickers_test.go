@@ -0,0 +1,203 @@
+package balancer
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc/balancer"
+)
+
+type fakeSubConn struct {
+	balancer.SubConn
+	name string
+}
+
+func testEndpoints(weights ...int) []*endpoint {
+	eps := make([]*endpoint, len(weights))
+	for i, w := range weights {
+		name := string(rune('a' + i))
+		eps[i] = &endpoint{
+			sc:       &fakeSubConn{name: name},
+			addr:     "10.0.0." + strconv.Itoa(i+1) + ":9081",
+			inst:     Instance{Weight: w},
+			inflight: new(int64),
+		}
+	}
+	return eps
+}
+
+func pickName(t *testing.T, p balancer.Picker, ctx context.Context) string {
+	t.Helper()
+	res, err := p.Pick(balancer.PickInfo{Ctx: ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.SubConn.(*fakeSubConn).name
+}
+
+func TestWeightedPickerSequence(t *testing.T) {
+	cases := []struct {
+		name    string
+		weights []int
+		want    string
+	}{
+		// 平滑加权：高权重实例的流量被打散而不是连续命中
+		{"smooth", []int{500, 100, 100}, "aabacaa"},
+		{"zero weight excluded", []int{0, 100, 300}, "cbcccbccc"},
+		{"all zero falls back to round robin", []int{0, 0}, ""},
+	}
+	for _, c := range cases {
+		p := newWeightedPicker(nil, testEndpoints(c.weights...))
+		if c.want == "" {
+			seen := map[string]int{}
+			for i := 0; i < 10; i++ {
+				seen[pickName(t, p, context.Background())]++
+			}
+			if seen["a"] != 5 || seen["b"] != 5 {
+				t.Errorf("%s: distribution = %v", c.name, seen)
+			}
+			continue
+		}
+		var got strings.Builder
+		for range c.want {
+			got.WriteString(pickName(t, p, context.Background()))
+		}
+		if got.String() != c.want {
+			t.Errorf("%s: sequence = %s, want %s", c.name, got.String(), c.want)
+		}
+	}
+}
+
+func TestHashPickerStability(t *testing.T) {
+	route := func(p balancer.Picker) map[string]string {
+		owners := make(map[string]string, 1000)
+		for i := 0; i < 1000; i++ {
+			key := "user-" + strconv.Itoa(i)
+			owners[key] = pickName(t, p, WithHashKey(context.Background(), key))
+		}
+		return owners
+	}
+	eps := testEndpoints(100, 100, 100, 100)
+	base := route(newHashPicker(&Config{}, eps[:3]))
+	if again := route(newHashPicker(&Config{}, eps[:3])); len(again) != len(base) {
+		t.Fatal("unexpected key count")
+	} else {
+		for key, owner := range base {
+			if again[key] != owner {
+				t.Fatalf("%s moved between identical rings", key)
+			}
+		}
+	}
+
+	// 新增实例：只有迁往新实例的键变化
+	added := route(newHashPicker(&Config{}, eps))
+	moved := 0
+	for key, owner := range base {
+		if added[key] != owner {
+			moved++
+			if added[key] != "d" {
+				t.Fatalf("%s moved %s -> %s, not to the added endpoint", key, owner, added[key])
+			}
+		}
+	}
+	if moved == 0 || moved > 400 {
+		t.Errorf("%d of 1000 keys moved after adding one of four endpoints", moved)
+	}
+
+	// 移除实例：只有原属于该实例的键变化
+	removed := route(newHashPicker(&Config{}, []*endpoint{eps[0], eps[2]}))
+	for key, owner := range base {
+		if owner != "b" && removed[key] != owner {
+			t.Fatalf("%s moved %s -> %s although its endpoint stayed", key, owner, removed[key])
+		}
+	}
+
+	// metadata 中的哈希键与 WithHashKey 效果一致，权重为 0 的实例不在环上
+	p := newHashPicker(&Config{}, testEndpoints(0, 100))
+	for i := 0; i < 20; i++ {
+		if name := pickName(t, p, WithHashKey(context.Background(), strconv.Itoa(i))); name != "b" {
+			t.Fatalf("weight-0 endpoint picked: %s", name)
+		}
+	}
+}
+
+func TestLeastRequestPickerDone(t *testing.T) {
+	eps := testEndpoints(100, 100)
+	p := newLeastRequestPicker(nil, eps)
+
+	var results []balancer.PickResult
+	for i := 0; i < 4; i++ {
+		res, err := p.Pick(balancer.PickInfo{Ctx: context.Background()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, res)
+	}
+	if total := atomic.LoadInt64(eps[0].inflight) + atomic.LoadInt64(eps[1].inflight); total != 4 {
+		t.Fatalf("inflight = %d, want 4", total)
+	}
+	for _, res := range results {
+		res.Done(balancer.DoneInfo{})
+	}
+	if a, b := atomic.LoadInt64(eps[0].inflight), atomic.LoadInt64(eps[1].inflight); a != 0 || b != 0 {
+		t.Fatalf("inflight after Done = %d, %d", a, b)
+	}
+
+	// 两个候选不同时总选进行中请求较少者，因此繁忙实例最多在两次都抽中它时被选中
+	atomic.StoreInt64(eps[0].inflight, 1000)
+	busy := 0
+	for i := 0; i < 200; i++ {
+		res, _ := p.Pick(balancer.PickInfo{Ctx: context.Background()})
+		if res.SubConn.(*fakeSubConn).name == "a" {
+			busy++
+		}
+		res.Done(balancer.DoneInfo{})
+	}
+	if busy > 100 {
+		t.Errorf("busy endpoint picked %d of 200 times", busy)
+	}
+}
+
+func TestRoutingPickerBounded(t *testing.T) {
+	eps := testEndpoints(100, 100, 100)
+	eps[0].inst.Metadata = map[string]string{"version": "v1"}
+	eps[1].inst.Metadata = map[string]string{"version": "v2"}
+	eps[2].inst.Metadata = map[string]string{"version": "v2"}
+	built := 0
+	p := &routingPicker{
+		cfg: &Config{TagFallback: true},
+		all: eps,
+		newPicker: func(cfg *Config, ready []*endpoint) balancer.Picker {
+			built++
+			return newRoundRobinPicker(cfg, ready)
+		},
+		routes:  map[string]balancer.Picker{},
+		pickers: map[string]balancer.Picker{},
+	}
+	pick := func(tags map[string]string) string {
+		return pickName(t, p, WithRouteTags(context.Background(), tags))
+	}
+
+	if got := pick(map[string]string{"version": "v1"}); got != "a" {
+		t.Fatalf("v1 routed to %s", got)
+	}
+	// 请求携带的任意标签值不会让 picker 无限增长：未命中的回退到全部实例，共用同一个 picker
+	for i := 0; i < 5*maxRouteCache; i++ {
+		pick(map[string]string{"version": "v1", "user": strconv.Itoa(i)})
+	}
+	if len(p.routes) > maxRouteCache {
+		t.Fatalf("routes = %d, want <= %d", len(p.routes), maxRouteCache)
+	}
+	if len(p.pickers) != 2 || built != 2 {
+		t.Fatalf("pickers = %d, built = %d, want 2", len(p.pickers), built)
+	}
+
+	// 命中同一实例集合的标签组合共享轮询状态
+	seq := pick(map[string]string{"version": "v2"}) + pick(map[string]string{"version": "v2", "zone": ""})
+	if seq != "bc" && seq != "cb" {
+		t.Fatalf("v2 sequence = %s", seq)
+	}
+}
@@ -0,0 +1,286 @@
+package rpc
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const CodeServiceUnavailable = "platform.service_unavailable"
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// callPolicy 单次调用生效的超时与重试策略
+type callPolicy struct {
+	timeout    time.Duration
+	maxRetry   int
+	idempotent bool
+}
+
+// callPolicies 按 serviceKey + 方法解析 plugins.rpc.client.methods
+type callPolicies struct {
+	defaultTimeout time.Duration
+	maxRetry       int
+	retryable      map[codes.Code]bool
+	backoff        time.Duration
+	maxBackoff     time.Duration
+	methods        []config.RpcMethodConfig
+}
+
+func newCallPolicies(cfg config.RpcClientConfig, defaultTimeout time.Duration) *callPolicies {
+	p := &callPolicies{
+		defaultTimeout: defaultTimeout,
+		maxRetry:       cfg.MaxRetry,
+		retryable:      make(map[codes.Code]bool),
+		backoff:        time.Duration(cfg.RetryBackoffMs) * time.Millisecond,
+		maxBackoff:     time.Duration(cfg.RetryMaxBackoffMs) * time.Millisecond,
+		methods:        cfg.Methods,
+	}
+	names := cfg.RetryableCodes
+	if len(names) == 0 {
+		names = []string{"UNAVAILABLE"}
+	}
+	for _, name := range names {
+		var c codes.Code
+		if err := c.UnmarshalJSON([]byte(`"` + strings.ToUpper(strings.TrimSpace(name)) + `"`)); err != nil {
+			logger.Warn("忽略无效的 retryableCodes 配置", zap.String("code", name))
+			continue
+		}
+		p.retryable[c] = true
+	}
+	if p.backoff <= 0 {
+		p.backoff = 100 * time.Millisecond
+	}
+	if p.maxBackoff < p.backoff {
+		p.maxBackoff = p.backoff
+	}
+	return p
+}
+
+func (p *callPolicies) resolve(serviceKey, method string) callPolicy {
+	policy := callPolicy{timeout: p.defaultTimeout, maxRetry: p.maxRetry}
+	for _, m := range p.methods {
+		if m.Service != "" && m.Service != serviceKey {
+			continue
+		}
+		if !matchMethod(m.Method, method) {
+			continue
+		}
+		if m.TimeoutMs > 0 {
+			policy.timeout = time.Duration(m.TimeoutMs) * time.Millisecond
+		}
+		if m.MaxRetry > 0 {
+			policy.maxRetry = m.MaxRetry
+		}
+		policy.idempotent = m.Idempotent
+		break
+	}
+	return policy
+}
+
+// matchMethod 支持精确匹配与 /pkg.Service/* 通配
+func matchMethod(pattern, method string) bool {
+	if pattern == "" || pattern == "*" || pattern == method {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(method, prefix)
+	}
+	return false
+}
+
+// backoffFor 指数退避，叠加 ±20% 抖动
+func (p *callPolicies) backoffFor(attempt int) time.Duration {
+	d := p.backoff << attempt
+	if d <= 0 || d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/5 + 1))
+	if rand.Intn(2) == 0 {
+		return d - jitter
+	}
+	return d + jitter
+}
+
+// unaryInterceptor 超时、熔断、重试；须位于 ClientErrorDecode 内侧以读取原始 gRPC 状态码。
+// 超时仅对未设置 deadline 的调用逐次生效，调用方自带的 deadline 原样沿用（覆盖全部重试）
+func (p *callPolicies) unaryInterceptor(serviceKey string, breaker *circuitBreaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy := p.resolve(serviceKey, method)
+		if _, ok := ctx.Deadline(); ok {
+			policy.timeout = 0
+		}
+		attempts := 1
+		if policy.idempotent && policy.maxRetry > 0 {
+			attempts += policy.maxRetry
+		}
+
+		var err error
+		for attempt := 0; attempt < attempts; attempt++ {
+			if attempt > 0 {
+				timer := time.NewTimer(p.backoffFor(attempt - 1))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return err
+				case <-timer.C:
+				}
+			}
+			if !breaker.allow() {
+				return myException.NewBizError(CodeServiceUnavailable, map[string]string{"service": serviceKey})
+			}
+
+			callCtx, cancel := ctx, context.CancelFunc(func() {})
+			if policy.timeout > 0 {
+				callCtx, cancel = context.WithTimeout(ctx, policy.timeout)
+			}
+			err = invoker(callCtx, method, req, reply, cc, opts...)
+			cancel()
+			breaker.record(err)
+
+			if err == nil || ctx.Err() != nil || !p.retryable[status.Code(err)] {
+				return err
+			}
+			if attempt+1 < attempts {
+				logger.Warn("gRPC 调用失败，准备重试",
+					zap.String("serviceKey", serviceKey),
+					zap.String("method", method),
+					zap.Int("attempt", attempt+1),
+					zap.Error(err),
+				)
+			}
+		}
+		return err
+	}
+}
+
+// streamInterceptor 流式调用仅在建流时做熔断判断，不设超时也不重试
+func (p *callPolicies) streamInterceptor(serviceKey string, breaker *circuitBreaker) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if !breaker.allow() {
+			return nil, myException.NewBizError(CodeServiceUnavailable, map[string]string{"service": serviceKey})
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		breaker.record(err)
+		return cs, err
+	}
+}
+
+// circuitBreaker 连续失败计数熔断；Open 到期后进入 HalfOpen 放行单个探测请求
+type circuitBreaker struct {
+	enabled   bool
+	threshold int
+	openFor   time.Duration
+	name      string
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(name string, cfg config.RpcBreakerConfig) *circuitBreaker {
+	b := &circuitBreaker{
+		enabled:   cfg.Enabled,
+		threshold: cfg.FailureThreshold,
+		openFor:   time.Duration(cfg.OpenSeconds) * time.Second,
+		name:      name,
+		state:     BreakerClosed,
+	}
+	if b.threshold <= 0 {
+		b.threshold = 5
+	}
+	if b.openFor <= 0 {
+		b.openFor = 10 * time.Second
+	}
+	return b
+}
+
+func (b *circuitBreaker) allow() bool {
+	if !b.enabled {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.openFor {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record 仅 Unavailable / DeadlineExceeded 计为失败，业务错误不影响熔断；
+// 调用方取消（Canceled）不代表下游状态，不计入结果，半开时仅释放探测名额
+func (b *circuitBreaker) record(err error) {
+	if !b.enabled {
+		return
+	}
+	code := status.Code(err)
+	failed := code == codes.Unavailable || code == codes.DeadlineExceeded
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if code == codes.Canceled {
+		if b.state == BreakerHalfOpen {
+			b.probing = false
+		}
+		return
+	}
+	prev := b.state
+	if b.state == BreakerHalfOpen {
+		b.probing = false
+	}
+	if !failed {
+		b.failures = 0
+		b.state = BreakerClosed
+	} else {
+		b.failures++
+		if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+			b.state = BreakerOpen
+			b.openedAt = time.Now()
+		}
+	}
+	if prev != b.state {
+		logger.Warn("gRPC 熔断器状态变更",
+			zap.String("serviceKey", b.name),
+			zap.String("from", prev),
+			zap.String("to", b.state),
+		)
+	}
+}
+
+func (b *circuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.openFor {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,186 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "down")
+	type step struct {
+		name      string
+		expire    bool  // 熔断时间已到
+		err       error // allow 通过后记录的结果
+		wantAllow bool
+		wantState string
+	}
+	steps := []step{
+		{name: "first failure", err: unavailable, wantAllow: true, wantState: BreakerClosed},
+		{name: "business error resets", err: status.Error(codes.InvalidArgument, "bad"), wantAllow: true, wantState: BreakerClosed},
+		{name: "failure 1", err: unavailable, wantAllow: true, wantState: BreakerClosed},
+		{name: "threshold opens", err: status.Error(codes.DeadlineExceeded, "slow"), wantAllow: true, wantState: BreakerOpen},
+		{name: "open rejects", wantAllow: false, wantState: BreakerOpen},
+		{name: "probe fails reopens", expire: true, err: unavailable, wantAllow: true, wantState: BreakerOpen},
+		{name: "reopened rejects", wantAllow: false, wantState: BreakerOpen},
+		{name: "cancelled probe ignored", expire: true, err: status.Error(codes.Canceled, "caller gone"), wantAllow: true, wantState: BreakerHalfOpen},
+		{name: "next probe allowed", err: unavailable, wantAllow: true, wantState: BreakerOpen},
+		{name: "probe succeeds closes", expire: true, err: nil, wantAllow: true, wantState: BreakerClosed},
+		{name: "closed allows", err: nil, wantAllow: true, wantState: BreakerClosed},
+	}
+
+	b := newCircuitBreaker("user", config.RpcBreakerConfig{Enabled: true, FailureThreshold: 2, OpenSeconds: 60})
+	for _, s := range steps {
+		if s.expire {
+			b.mu.Lock()
+			b.openedAt = time.Now().Add(-b.openFor)
+			b.mu.Unlock()
+			if got := b.State(); got != BreakerHalfOpen {
+				t.Fatalf("%s: state before probe = %s", s.name, got)
+			}
+		}
+		allowed := b.allow()
+		if allowed != s.wantAllow {
+			t.Fatalf("%s: allow = %v", s.name, allowed)
+		}
+		if allowed && s.expire && b.allow() {
+			t.Fatalf("%s: half-open allowed a second concurrent probe", s.name)
+		}
+		if allowed {
+			b.record(s.err)
+		}
+		if got := b.State(); got != s.wantState {
+			t.Fatalf("%s: state = %s, want %s", s.name, got, s.wantState)
+		}
+	}
+
+	disabled := newCircuitBreaker("user", config.RpcBreakerConfig{FailureThreshold: 1})
+	disabled.record(unavailable)
+	if !disabled.allow() {
+		t.Error("disabled breaker should always allow")
+	}
+}
+
+func TestCallPolicyResolve(t *testing.T) {
+	p := newCallPolicies(config.RpcClientConfig{
+		MaxRetry: 1,
+		Methods: []config.RpcMethodConfig{
+			{Service: "user", Method: "/user.User/GetUser", TimeoutMs: 500, MaxRetry: 3, Idempotent: true},
+			{Service: "user", Method: "/user.User/*", TimeoutMs: 800},
+			{Method: "/order.Order/Get*", Idempotent: true},
+			{Service: "user", Method: "/user.User/GetUser", TimeoutMs: 1},
+		},
+	}, 2*time.Second)
+
+	cases := []struct {
+		service, method string
+		want            callPolicy
+	}{
+		{"user", "/user.User/GetUser", callPolicy{timeout: 500 * time.Millisecond, maxRetry: 3, idempotent: true}},
+		{"user", "/user.User/CreateUser", callPolicy{timeout: 800 * time.Millisecond, maxRetry: 1}},
+		{"order", "/order.Order/GetOrder", callPolicy{timeout: 2 * time.Second, maxRetry: 1, idempotent: true}},
+		{"billing", "/order.Order/GetOrder", callPolicy{timeout: 2 * time.Second, maxRetry: 1, idempotent: true}},
+		{"order", "/order.Order/CreateOrder", callPolicy{timeout: 2 * time.Second, maxRetry: 1}},
+		{"billing", "/user.User/GetUser", callPolicy{timeout: 2 * time.Second, maxRetry: 1}},
+	}
+	for _, c := range cases {
+		if got := p.resolve(c.service, c.method); got != c.want {
+			t.Errorf("%s %s = %+v, want %+v", c.service, c.method, got, c.want)
+		}
+	}
+}
+
+func TestCallPolicyTimeout(t *testing.T) {
+	p := newCallPolicies(config.RpcClientConfig{
+		Methods: []config.RpcMethodConfig{{Method: "/demo.Demo/Slow", TimeoutMs: 100}},
+	}, 3*time.Second)
+	breaker := newCircuitBreaker("demo", config.RpcBreakerConfig{})
+	var remain time.Duration
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatal("no deadline applied")
+		}
+		remain = time.Until(deadline)
+		return nil
+	}
+	call := func(ctx context.Context, method string) time.Duration {
+		t.Helper()
+		if err := p.unaryInterceptor("demo", breaker)(ctx, method, nil, nil, nil, invoker); err != nil {
+			t.Fatal(err)
+		}
+		return remain
+	}
+
+	if d := call(context.Background(), "/demo.Demo/Get"); d > 3*time.Second || d < 2*time.Second {
+		t.Errorf("default timeout = %v, want 3s", d)
+	}
+	if d := call(context.Background(), "/demo.Demo/Slow"); d > 100*time.Millisecond {
+		t.Errorf("method timeout = %v, want 100ms", d)
+	}
+	// 调用方自带的 deadline 不被默认或方法超时缩短
+	for _, method := range []string{"/demo.Demo/Get", "/demo.Demo/Slow"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if d := call(ctx, method); d < 9*time.Second {
+			t.Errorf("%s: caller deadline cut to %v", method, d)
+		}
+		cancel()
+	}
+}
+
+func TestCallPolicyRetries(t *testing.T) {
+	p := newCallPolicies(config.RpcClientConfig{
+		MaxRetry:       2,
+		RetryBackoffMs: 1,
+		Methods: []config.RpcMethodConfig{
+			{Method: "/demo.Demo/Get", Idempotent: true},
+		},
+	}, time.Second)
+
+	cases := []struct {
+		name      string
+		method    string
+		err       error
+		wantCalls int
+	}{
+		{"idempotent retried on unavailable", "/demo.Demo/Get", status.Error(codes.Unavailable, "down"), 3},
+		{"non-idempotent not retried", "/demo.Demo/Create", status.Error(codes.Unavailable, "down"), 1},
+		{"non-retryable code", "/demo.Demo/Get", status.Error(codes.InvalidArgument, "bad"), 1},
+		{"success", "/demo.Demo/Get", nil, 1},
+	}
+	for _, c := range cases {
+		calls := 0
+		invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			calls++
+			return c.err
+		}
+		breaker := newCircuitBreaker("demo", config.RpcBreakerConfig{})
+		err := p.unaryInterceptor("demo", breaker)(context.Background(), c.method, nil, nil, nil, invoker)
+		if calls != c.wantCalls {
+			t.Errorf("%s: calls = %d, want %d", c.name, calls, c.wantCalls)
+		}
+		if status.Code(err) != status.Code(c.err) {
+			t.Errorf("%s: err = %v", c.name, err)
+		}
+	}
+
+	// 熔断打开后直接返回服务不可用，不再调用下游
+	breaker := newCircuitBreaker("demo", config.RpcBreakerConfig{Enabled: true, FailureThreshold: 1, OpenSeconds: 60})
+	calls := 0
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.Unavailable, "down")
+	}
+	err := p.unaryInterceptor("demo", breaker)(context.Background(), "/demo.Demo/Get", nil, nil, nil, invoker)
+	var bizErr *myException.BizError
+	if calls != 1 || !errors.As(err, &bizErr) || bizErr.Code != CodeServiceUnavailable {
+		t.Fatalf("open breaker: calls = %d, err = %v", calls, err)
+	}
+}
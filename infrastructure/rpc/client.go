@@ -5,12 +5,13 @@ import (
 	"time"
 
 	"github.com/muyi-zcy/tech-muyi-base-go/config"
-	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/metrics"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/tracing"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
+	rpcbalancer "github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/balancer"
 	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/interceptor"
 )
 
@@ -21,8 +22,11 @@ type ClientManager struct {
 	staticAddrs    map[string]string
 	sourceService  string
 	defaultTimeout time.Duration
+	policies       *callPolicies
 	mu             sync.RWMutex
 	conns          map[string]*grpc.ClientConn
+	breakers       map[string]*circuitBreaker
+	stubs          map[stubKey]any
 }
 
 func newClientManager(cfg *config.Config, rpcCfg config.RpcConfig) *ClientManager {
@@ -44,7 +48,10 @@ func newClientManager(cfg *config.Config, rpcCfg config.RpcConfig) *ClientManage
 		staticAddrs:    rpcCfg.Static,
 		sourceService:  sourceService,
 		defaultTimeout: timeout,
+		policies:       newCallPolicies(rpcCfg.Client, timeout),
 		conns:          make(map[string]*grpc.ClientConn),
+		breakers:       make(map[string]*circuitBreaker),
+		stubs:          make(map[stubKey]any),
 	}
 }
 
@@ -68,30 +75,70 @@ func (m *ClientManager) GetConn(serviceKey string) (*grpc.ClientConn, error) {
 		return nil, err
 	}
 
+	creds, err := clientCredentials(m.cfg.TLS, serviceKey)
+	if err != nil {
+		return nil, err
+	}
+	breaker := newCircuitBreaker(serviceKey, m.cfg.Breaker)
+	unaryChain := []grpc.UnaryClientInterceptor{
+		tracing.UnaryClientInterceptor(serviceKey),
+		interceptor.ContextInject(m.sourceService),
+		interceptor.ClientLogging(),
+		interceptor.ClientErrorDecode(),
+	}
+	streamChain := []grpc.StreamClientInterceptor{
+		tracing.StreamClientInterceptor(serviceKey),
+		interceptor.StreamContextInject(m.sourceService),
+		interceptor.StreamClientLogging(),
+		interceptor.StreamClientErrorDecode(),
+	}
+	// 指标位于 ClientErrorDecode 内侧以读取原始状态码，位于调用策略外侧以统计含重试的整体耗时与熔断拒绝
+	if metrics.Enabled() {
+		unaryChain = append(unaryChain, metrics.UnaryClientInterceptor(serviceKey))
+		streamChain = append(streamChain, metrics.StreamClientInterceptor(serviceKey))
+	}
+	unaryChain = append(unaryChain, m.policies.unaryInterceptor(serviceKey, breaker))
+	streamChain = append(streamChain, m.policies.streamInterceptor(serviceKey, breaker))
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithChainUnaryInterceptor(
-			interceptor.ContextInject(m.sourceService),
-			interceptor.ClientLogging(),
-			interceptor.ClientErrorDecode(),
-		),
-		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[{"round_robin":{}}]}`),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(unaryChain...),
+		grpc.WithChainStreamInterceptor(streamChain...),
 	}
+	serviceConfig, err := m.serviceConfig(serviceKey)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpc.WithDefaultServiceConfig(serviceConfig))
 
 	conn, err := grpc.NewClient(target, opts...)
 	if err != nil {
 		return nil, errors.Wrapf(err, "连接 gRPC 服务 %s 失败", serviceKey)
 	}
 	m.conns[serviceKey] = conn
-	myLogger.Info("gRPC 客户端已连接", zap.String("serviceKey", serviceKey), zap.String("target", target))
+	m.breakers[serviceKey] = breaker
+	logger.Info("gRPC 客户端已连接", zap.String("serviceKey", serviceKey), zap.String("target", target))
 	return conn, nil
 }
 
-// DefaultTimeout 默认 RPC 超时
+// DefaultTimeout 默认 RPC 超时（未设置 deadline 的调用已自动应用，无需再手动包装）
 func (m *ClientManager) DefaultTimeout() time.Duration {
 	return m.defaultTimeout
 }
 
+// BreakerStates 已建立连接的服务熔断器状态（serviceKey → closed / open / half_open）
+func (m *ClientManager) BreakerStates() map[string]string {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	states := make(map[string]string, len(m.breakers))
+	for key, b := range m.breakers {
+		states[key] = b.State()
+	}
+	return states
+}
+
 // Close 关闭所有连接
 func (m *ClientManager) Close() error {
 	m.mu.Lock()
@@ -100,13 +147,41 @@ func (m *ClientManager) Close() error {
 	for key, conn := range m.conns {
 		if err := conn.Close(); err != nil {
 			lastErr = err
-			myLogger.Warn("关闭 gRPC 连接失败", zap.String("serviceKey", key), zap.Error(err))
+			logger.Warn("关闭 gRPC 连接失败", zap.String("serviceKey", key), zap.Error(err))
 		}
 	}
 	m.conns = make(map[string]*grpc.ClientConn)
+	m.breakers = make(map[string]*circuitBreaker)
+	m.stubs = make(map[stubKey]any)
 	return lastErr
 }
 
+// serviceConfig 按 plugins.rpc.client.routing 生成负载均衡配置
+func (m *ClientManager) serviceConfig(serviceKey string) (string, error) {
+	policy := m.cfg.LoadBalancer
+	routing := m.cfg.Routing[serviceKey]
+	if routing.LoadBalancer != "" {
+		policy = routing.LoadBalancer
+	}
+	sc, err := rpcbalancer.ServiceConfigJSON(policy, rpcbalancer.Config{
+		HashKey:     routing.HashKey,
+		Tags:        routing.Tags,
+		TagFallback: routing.TagFallback,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "服务 %s 负载均衡配置无效", serviceKey)
+	}
+	return sc, nil
+}
+
+// setStaticAddrs 热更新 static 地址表，仅影响之后新建连接的 target 选择
+func (m *ClientManager) setStaticAddrs(addrs map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.staticAddrs = addrs
+}
+
+// buildTarget 调用方须持有 m.mu
 func (m *ClientManager) buildTarget(serviceKey string) (string, error) {
 	serviceName := serviceKey
 	if mapped, ok := m.cfg.Services[serviceKey]; ok && mapped != "" {
@@ -115,17 +190,17 @@ func (m *ClientManager) buildTarget(serviceKey string) (string, error) {
 
 	switch m.registry {
 	case "static":
-		addr := serviceName
-		if mapped, ok := m.staticAddrs[serviceKey]; ok && mapped != "" {
-			addr = mapped
-		} else if mapped, ok := m.staticAddrs[serviceName]; ok && mapped != "" {
-			addr = mapped
+		// 以配置键为 target，static resolver 按键查地址，便于热更新
+		key := serviceName
+		if m.staticAddrs[serviceKey] != "" {
+			key = serviceKey
 		}
-		if addr == "" {
+		if key == "" {
 			return "", errors.Errorf("static 模式未配置服务 %s 的地址", serviceKey)
 		}
-		return "static:///" + addr, nil
+		return "static:///" + key, nil
 	default:
-		return "nacos:///" + serviceName, nil
+		// 与注册中心同名的 resolver scheme：nacos / etcd / consul / file
+		return m.registry + ":///" + serviceName, nil
 	}
 }
@@ -0,0 +1,339 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
+	rpcbalancer "github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/balancer"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/tracing"
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// HTTPClient 按服务名发现 HTTP 实例的客户端：注册中心或 static 地址按权重随机选择，
+// 透传 traceId / token / 来源服务，并将 MyResult 失败响应还原为 BizError。
+type HTTPClient struct {
+	serviceKey    string
+	serviceName   string
+	group         string
+	naming        registry.NamingClient
+	static        []string
+	sourceService string
+	client        *http.Client
+
+	mu         sync.RWMutex
+	instances  []registry.ServiceInstance
+	subscribed bool
+	// subscribing 合并并发的首次查询与订阅，网络调用不持有 mu
+	subscribing singleflight.Group
+}
+
+var (
+	httpClients   = map[string]*HTTPClient{}
+	httpClientsMu sync.Mutex
+)
+
+// GetHTTPClient 获取 serviceKey 对应的 HTTPClient（按 serviceKey 缓存）
+func GetHTTPClient(serviceKey string) *HTTPClient {
+	httpClientsMu.Lock()
+	defer httpClientsMu.Unlock()
+	if c, ok := httpClients[serviceKey]; ok {
+		return c
+	}
+	c := newHTTPClient(config.GetConfig(), registry.GetRegistry(), serviceKey)
+	httpClients[serviceKey] = c
+	return c
+}
+
+func newHTTPClient(appCfg *config.Config, reg registry.Registry, serviceKey string) *HTTPClient {
+	var rpcCfg config.RpcConfig
+	sourceService := ""
+	if appCfg != nil {
+		rpcCfg = appCfg.Plugins.Rpc
+		sourceService = appCfg.Plugins.Nacos.ServiceName
+		if sourceService == "" {
+			sourceService = appCfg.AppName
+		}
+	}
+	httpCfg := rpcCfg.Client.HTTP
+
+	timeoutMs := httpCfg.TimeoutMs
+	if timeoutMs <= 0 {
+		timeoutMs = rpcCfg.Client.DefaultTimeoutMs
+	}
+	if timeoutMs <= 0 {
+		timeoutMs = 3000
+	}
+
+	serviceName := httpCfg.Services[serviceKey]
+	if serviceName == "" {
+		base := rpcCfg.Client.Services[serviceKey]
+		if base == "" {
+			base = serviceKey
+		}
+		serviceName = base + registry.HTTPServiceSuffix
+	}
+	c := &HTTPClient{
+		serviceKey:    serviceKey,
+		serviceName:   serviceName,
+		group:         registry.Group(appCfg),
+		sourceService: sourceService,
+		client: &http.Client{
+			Timeout:   time.Duration(timeoutMs) * time.Millisecond,
+			Transport: tracing.Transport(nil),
+		},
+	}
+	if static := httpCfg.Static[serviceKey]; static != "" {
+		for _, addr := range strings.Split(static, ",") {
+			if addr = strings.TrimRight(strings.TrimSpace(addr), "/"); addr != "" {
+				if !strings.Contains(addr, "://") {
+					addr = "http://" + addr
+				}
+				c.static = append(c.static, addr)
+			}
+		}
+	} else if reg != nil && reg.Enabled() {
+		c.naming = reg.NamingClient()
+	}
+	return c
+}
+
+// ServiceName 解析使用的注册中心服务名
+func (c *HTTPClient) ServiceName() string { return c.serviceName }
+
+// Do 将 req 的 URL 解析到选中的实例并注入上下文 header；req.URL 只需 path 与 query
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	base, err := c.pick()
+	if err != nil {
+		return nil, err
+	}
+	target, err := http.NewRequestWithContext(req.Context(), req.Method, base+req.URL.RequestURI(), req.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "构建 HTTP 请求失败")
+	}
+	target.Header = req.Header.Clone()
+	if target.Header == nil {
+		target.Header = http.Header{}
+	}
+	target.ContentLength = req.ContentLength
+	c.injectHeaders(req.Context(), target.Header)
+	return c.client.Do(target)
+}
+
+// Get 发起 GET 并将 MyResult.data 解码到 out
+func (c *HTTPClient) Get(ctx context.Context, path string, out any) error {
+	return c.Call(ctx, http.MethodGet, path, nil, out)
+}
+
+// Post 以 JSON 发送 body 并将 MyResult.data 解码到 out
+func (c *HTTPClient) Post(ctx context.Context, path string, body, out any) error {
+	return c.Call(ctx, http.MethodPost, path, body, out)
+}
+
+// Call 发起 JSON 请求；响应为 MyResult 且 success=false 时返回 BizError，out 为 nil 时忽略 data
+func (c *HTTPClient) Call(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "序列化请求体失败")
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, path, reader)
+	if err != nil {
+		return errors.Wrap(err, "构建 HTTP 请求失败")
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	start := time.Now()
+	resp, err := c.Do(req)
+	fields := []zap.Field{
+		zap.String("service", c.serviceName),
+		zap.String("method", method),
+		zap.String("path", path),
+		zap.String("traceId", myContext.TryGetTraceId(ctx)),
+		zap.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		// 返回给调用方的是统一的服务不可用错误码，传输层原因只保留在日志中
+		logger.Warn("HTTP client 调用失败", append(fields, zap.Error(err))...)
+		return myException.NewBizError(CodeServiceUnavailable, map[string]string{"service": c.serviceKey})
+	}
+	logger.Info("HTTP client", fields...)
+	defer resp.Body.Close()
+	return decodeResult(resp, c.serviceKey, out)
+}
+
+// decodeResult 解析 MyResult 信封；非信封响应按 HTTP 状态码映射
+func decodeResult(resp *http.Response, serviceKey string, out any) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "读取 HTTP 响应失败")
+	}
+	var envelope struct {
+		Code    string          `json:"code"`
+		Success *bool           `json:"success"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Success == nil {
+		if resp.StatusCode >= 400 {
+			return httpStatusError(resp.StatusCode, serviceKey, string(data))
+		}
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+		return errors.Wrap(json.Unmarshal(data, out), "解析 HTTP 响应失败")
+	}
+	if !*envelope.Success {
+		var args map[string]string
+		if envelope.Message != "" {
+			args = map[string]string{"message": envelope.Message}
+		}
+		return myException.NewBizError(envelope.Code, args)
+	}
+	if out == nil || len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		return nil
+	}
+	return errors.Wrap(json.Unmarshal(envelope.Data, out), "解析 MyResult.data 失败")
+}
+
+func httpStatusError(code int, serviceKey, body string) error {
+	args := map[string]string{"message": body}
+	switch {
+	case code == http.StatusUnauthorized:
+		return myException.NewBizError("platform.unauthorized", args)
+	case code == http.StatusForbidden:
+		return myException.NewBizError("platform.forbidden", args)
+	case code == http.StatusNotFound:
+		return myException.NewBizError("platform.route.not_found", nil)
+	case code == http.StatusTooManyRequests:
+		return myException.NewBizError("platform.rate_limited", args)
+	case code == http.StatusBadGateway || code == http.StatusServiceUnavailable:
+		return myException.NewBizError(CodeServiceUnavailable, map[string]string{"service": serviceKey})
+	default:
+		return myException.NewBizError("platform.internal_error", args)
+	}
+}
+
+func (c *HTTPClient) injectHeaders(ctx context.Context, h http.Header) {
+	if traceId := myContext.TryGetTraceId(ctx); traceId != "" {
+		h.Set(myContext.HeaderTraceId, traceId)
+	}
+	if token := myContext.TryGetToken(ctx); token != "" {
+		h.Set(myContext.HeaderToken, token)
+	}
+	if c.sourceService != "" {
+		h.Set(myContext.HeaderSourceService, c.sourceService)
+	}
+}
+
+// pick 按实例权重随机选择，返回 scheme://host:port
+func (c *HTTPClient) pick() (string, error) {
+	if len(c.static) > 0 {
+		return c.static[rand.Intn(len(c.static))], nil
+	}
+	instances, err := c.resolve()
+	if err != nil {
+		return "", err
+	}
+	total := 0
+	for _, inst := range instances {
+		total += rpcbalancer.NacosWeight(inst.Weight)
+	}
+	chosen := instances[rand.Intn(len(instances))]
+	if total > 0 {
+		n := rand.Intn(total)
+		for _, inst := range instances {
+			if n -= rpcbalancer.NacosWeight(inst.Weight); n < 0 {
+				chosen = inst
+				break
+			}
+		}
+	}
+	scheme := "http"
+	if chosen.Metadata["scheme"] == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + chosen.IP + ":" + strconv.FormatUint(chosen.Port, 10), nil
+}
+
+// resolve 首次查询并订阅注册中心，之后使用推送更新的实例列表
+func (c *HTTPClient) resolve() ([]registry.ServiceInstance, error) {
+	if c.naming == nil {
+		return nil, myException.NewBizError(CodeServiceUnavailable, map[string]string{"service": c.serviceKey})
+	}
+	c.mu.RLock()
+	instances, subscribed := c.instances, c.subscribed
+	c.mu.RUnlock()
+	if !subscribed {
+		v, err, _ := c.subscribing.Do(c.serviceName, func() (interface{}, error) {
+			return c.subscribe()
+		})
+		if err != nil {
+			return nil, err
+		}
+		instances = v.([]registry.ServiceInstance)
+	}
+	if len(instances) == 0 {
+		return nil, myException.NewBizError(CodeServiceUnavailable, map[string]string{"service": c.serviceKey})
+	}
+	return instances, nil
+}
+
+// subscribe 查询实例并订阅变更，SelectInstances / Subscribe 均在锁外调用；订阅失败时下次 resolve 重试
+func (c *HTTPClient) subscribe() ([]registry.ServiceInstance, error) {
+	c.mu.RLock()
+	instances, subscribed := c.instances, c.subscribed
+	c.mu.RUnlock()
+	if subscribed {
+		return instances, nil
+	}
+	fetched, err := c.naming.SelectInstances(c.serviceName, c.group)
+	if err != nil {
+		logger.Warn("查询 HTTP 服务实例失败", zap.String("service", c.serviceName), zap.Error(err))
+		return nil, myException.NewBizError(CodeServiceUnavailable, map[string]string{"service": c.serviceKey})
+	}
+	c.mu.Lock()
+	c.instances = healthyInstances(fetched)
+	c.mu.Unlock()
+	err = c.naming.Subscribe(c.serviceName, c.group, func(updated []registry.ServiceInstance) {
+		c.mu.Lock()
+		c.instances = healthyInstances(updated)
+		c.mu.Unlock()
+	})
+	if err != nil {
+		logger.Warn("订阅 HTTP 服务实例失败", zap.String("service", c.serviceName), zap.Error(err))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribed = err == nil
+	return c.instances, nil
+}
+
+func healthyInstances(instances []registry.ServiceInstance) []registry.ServiceInstance {
+	out := make([]registry.ServiceInstance, 0, len(instances))
+	for _, inst := range instances {
+		if inst.Healthy {
+			out = append(out, inst)
+		}
+	}
+	return out
+}
@@ -0,0 +1,212 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+)
+
+// fakeRegistry 内存注册中心：按服务名返回实例，push 模拟注册中心推送
+type fakeRegistry struct {
+	mu        sync.Mutex
+	instances map[string][]registry.ServiceInstance
+	subs      map[string]func(instances []registry.ServiceInstance)
+	selects   int
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		instances: map[string][]registry.ServiceInstance{},
+		subs:      map[string]func(instances []registry.ServiceInstance){},
+	}
+}
+
+func (r *fakeRegistry) Enabled() bool                                          { return true }
+func (r *fakeRegistry) Register(context.Context, int, map[string]string) error { return nil }
+func (r *fakeRegistry) Deregister(context.Context) error                       { return nil }
+func (r *fakeRegistry) NamingClient() registry.NamingClient                    { return r }
+
+func (r *fakeRegistry) SelectInstances(serviceName, _ string) ([]registry.ServiceInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.selects++
+	return r.instances[serviceName], nil
+}
+
+func (r *fakeRegistry) Subscribe(serviceName, _ string, callback func(instances []registry.ServiceInstance)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs[serviceName] = callback
+	return nil
+}
+
+func (r *fakeRegistry) Unsubscribe(serviceName, _ string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subs, serviceName)
+	return nil
+}
+
+func (r *fakeRegistry) push(serviceName string, instances []registry.ServiceInstance) {
+	r.mu.Lock()
+	callback := r.subs[serviceName]
+	r.mu.Unlock()
+	if callback != nil {
+		callback(instances)
+	}
+}
+
+func serverInstance(t *testing.T, srv *httptest.Server, healthy bool) registry.ServiceInstance {
+	t.Helper()
+	host, portStr, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.ParseUint(portStr, 10, 16)
+	return registry.ServiceInstance{IP: host, Port: port, Weight: 1, Healthy: healthy}
+}
+
+func TestHTTPClientDiscovery(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	handler := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[name]++
+			mu.Unlock()
+			if r.Header.Get(myContext.HeaderTraceId) != "trace-1" || r.Header.Get(myContext.HeaderToken) != "tok" ||
+				r.Header.Get(myContext.HeaderSourceService) != "order-service" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"code":"0","success":true,"data":{"name":"` + name + `"}}`))
+		}
+	}
+	a := httptest.NewServer(handler("a"))
+	defer a.Close()
+	b := httptest.NewServer(handler("b"))
+	defer b.Close()
+
+	reg := newFakeRegistry()
+	// user 映射为 user-service，HTTP 服务名默认追加 -http；不健康实例不参与选择
+	reg.instances["user-service-http"] = []registry.ServiceInstance{serverInstance(t, a, true), serverInstance(t, b, false)}
+	cfg := &config.Config{AppName: "order-service"}
+	cfg.Plugins.Rpc.Client.Services = map[string]string{"user": "user-service"}
+	c := newHTTPClient(cfg, reg, "user")
+	if c.ServiceName() != "user-service-http" {
+		t.Fatalf("service name = %s", c.ServiceName())
+	}
+
+	ctx := myContext.WithToken(myContext.WithTraceId(context.Background(), "trace-1"), "tok")
+	var out struct{ Name string }
+	for i := 0; i < 5; i++ {
+		if err := c.Get(ctx, "/v1/user/1", &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.Name != "a" {
+			t.Fatalf("picked %s, want healthy instance a", out.Name)
+		}
+	}
+	if reg.selects != 1 {
+		t.Errorf("instances queried %d times, want 1 then subscription", reg.selects)
+	}
+
+	reg.push("user-service-http", []registry.ServiceInstance{serverInstance(t, b, true)})
+	if err := c.Get(ctx, "/v1/user/1", &out); err != nil || out.Name != "b" {
+		t.Fatalf("after push picked %q, err %v", out.Name, err)
+	}
+
+	reg.push("user-service-http", nil)
+	var bizErr *myException.BizError
+	if err := c.Get(ctx, "/v1/user/1", &out); !errors.As(err, &bizErr) || bizErr.Code != CodeServiceUnavailable {
+		t.Fatalf("no instances err = %v", err)
+	}
+}
+
+// syncPushRegistry 在 Subscribe 内同步回调当前实例，与部分注册中心 SDK 的行为一致
+type syncPushRegistry struct {
+	*fakeRegistry
+}
+
+func (r syncPushRegistry) NamingClient() registry.NamingClient { return r }
+
+func (r syncPushRegistry) Subscribe(serviceName, group string, callback func(instances []registry.ServiceInstance)) error {
+	if err := r.fakeRegistry.Subscribe(serviceName, group, callback); err != nil {
+		return err
+	}
+	r.push(serviceName, r.instances[serviceName])
+	return nil
+}
+
+func TestHTTPClientConcurrentSubscribe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":"0","success":true}`))
+	}))
+	defer srv.Close()
+
+	reg := syncPushRegistry{newFakeRegistry()}
+	reg.instances["user-http"] = []registry.ServiceInstance{serverInstance(t, srv, true)}
+	c := newHTTPClient(&config.Config{}, reg, "user")
+
+	// 订阅回调在 Subscribe 返回前触发时不能死锁；并发的首次调用只查询一次
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.Get(context.Background(), "/v1/user/1", nil)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reg.selects != 1 {
+		t.Errorf("instances queried %d times, want 1", reg.selects)
+	}
+}
+
+func TestHTTPClientDecodeResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fail":
+			_, _ = w.Write([]byte(`{"code":"order.not_found","success":false,"message":"订单不存在"}`))
+		case "/plain":
+			_, _ = w.Write([]byte(`{"id":7}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{}
+	cfg.Plugins.Rpc.Client.HTTP.Static = map[string]string{"order": srv.Listener.Addr().String() + "/"}
+	c := newHTTPClient(cfg, nil, "order")
+	ctx := context.Background()
+
+	var bizErr *myException.BizError
+	if err := c.Get(ctx, "/fail", nil); !errors.As(err, &bizErr) || bizErr.Code != "order.not_found" || bizErr.Args["message"] != "订单不存在" {
+		t.Errorf("MyResult failure = %v", err)
+	}
+	var plain struct{ ID int }
+	if err := c.Get(ctx, "/plain", &plain); err != nil || plain.ID != 7 {
+		t.Errorf("plain body = %+v, %v", plain, err)
+	}
+	if err := c.Get(ctx, "/forbidden", nil); !errors.As(err, &bizErr) || bizErr.Code != "platform.forbidden" {
+		t.Errorf("403 = %v", err)
+	}
+}
@@ -8,40 +8,88 @@ import (
 	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 )
 
+// logger 命名日志，级别按 log.levels 中的 rpc.interceptor 或上级 rpc 调整
+var logger = myLogger.Named("rpc.interceptor")
+
 // ContextInject Client：context → outgoing metadata
 func ContextInject(sourceService string) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		pairs := make([]string, 0, 8)
-		if traceId := myContext.TryGetTraceId(ctx); traceId != "" {
-			pairs = append(pairs, myContext.HeaderTraceId, traceId)
-		}
-		if ssoId := myContext.TryGetSsoId(ctx); ssoId != "" {
-			pairs = append(pairs, myContext.HeaderSsoId, ssoId)
-		}
-		if token := myContext.TryGetToken(ctx); token != "" {
-			pairs = append(pairs, myContext.HeaderToken, token)
-		}
-		if sourceService != "" {
-			pairs = append(pairs, myContext.HeaderSourceService, sourceService)
-		}
-		if len(pairs) > 0 {
-			ctx = metadata.AppendToOutgoingContext(ctx, pairs...)
-		}
-		return invoker(ctx, method, req, reply, cc, opts...)
+		return invoker(injectContext(ctx, sourceService), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamContextInject ContextInject 的流式版本
+func StreamContextInject(sourceService string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(injectContext(ctx, sourceService), desc, cc, method, opts...)
+	}
+}
+
+func injectContext(ctx context.Context, sourceService string) context.Context {
+	pairs := make([]string, 0, 8)
+	if traceId := myContext.TryGetTraceId(ctx); traceId != "" {
+		pairs = append(pairs, myContext.HeaderTraceId, traceId)
+	}
+	if ssoId := myContext.TryGetSsoId(ctx); ssoId != "" {
+		pairs = append(pairs, myContext.HeaderSsoId, ssoId)
+	}
+	if token := myContext.TryGetToken(ctx); token != "" {
+		pairs = append(pairs, myContext.HeaderToken, token)
+	}
+	if sourceService != "" {
+		pairs = append(pairs, myContext.HeaderSourceService, sourceService)
 	}
+	if len(pairs) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, pairs...)
+	}
+	return ctx
 }
 
 // ContextExtract Server：incoming metadata → context
 func ContextExtract() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		if md, ok := metadata.FromIncomingContext(ctx); ok {
-			ctx = myContext.WithMetadata(ctx, md)
-		}
-		return handler(ctx, req)
+		return handler(extractContext(ctx, info.FullMethod), req)
+	}
+}
+
+// StreamContextExtract ContextExtract 的流式版本，handler 通过 stream.Context() 读取
+func StreamContextExtract() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, WrapServerStream(ss, extractContext(ss.Context(), info.FullMethod)))
+	}
+}
+
+// extractContext 恢复上下文并为本次调用的日志绑定方法名与来源服务，traceId 等由日志提取器在输出时读取
+func extractContext(ctx context.Context, fullMethod string) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = myContext.WithMetadata(ctx, md)
+	}
+	if id, ok := PeerIdentity(ctx); ok {
+		ctx = myContext.WithPeerIdentity(ctx, id)
 	}
+	fields := []zap.Field{zap.String("grpcMethod", fullMethod)}
+	if source := myContext.TryGetSourceService(ctx); source != "" {
+		fields = append(fields, zap.String(myContext.SourceService, source))
+	}
+	return myLogger.With(ctx, fields...)
+}
+
+// PeerIdentity 读取 mTLS 对端证书身份，仅信任校验通过的证书链
+func PeerIdentity(ctx context.Context) (myContext.PeerIdentity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return myContext.PeerIdentity{}, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return myContext.PeerIdentity{}, false
+	}
+	return myContext.PeerIdentityFromCertificate(info.State.VerifiedChains[0][0]), true
 }
 
 // Logging Server 侧请求日志
@@ -49,9 +97,7 @@ func Logging() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		start := time.Now()
 		resp, err := handler(ctx, req)
-		myLogger.Info("gRPC request",
-			zap.String("method", info.FullMethod),
-			zap.String("traceId", myContext.TryGetTraceId(ctx)),
+		logger.InfoCtx(ctx, "gRPC request",
 			zap.Duration("duration", time.Since(start)),
 			zap.Error(err),
 		)
@@ -59,17 +105,43 @@ func Logging() grpc.UnaryServerInterceptor {
 	}
 }
 
+// StreamLogging Server 侧流式请求日志，流结束时记录
+func StreamLogging() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logger.InfoCtx(ss.Context(), "gRPC stream",
+			zap.Duration("duration", time.Since(start)),
+			zap.Error(err),
+		)
+		return err
+	}
+}
+
 // ClientLogging Client 侧请求日志
 func ClientLogging() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		start := time.Now()
 		err := invoker(ctx, method, req, reply, cc, opts...)
-		myLogger.Info("gRPC client",
+		logger.InfoCtx(ctx, "gRPC client",
 			zap.String("method", method),
-			zap.String("traceId", myContext.TryGetTraceId(ctx)),
 			zap.Duration("duration", time.Since(start)),
 			zap.Error(err),
 		)
 		return err
 	}
 }
+
+// StreamClientLogging Client 侧流式日志，仅记录建流结果
+func StreamClientLogging() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		logger.InfoCtx(ctx, "gRPC client stream",
+			zap.String("method", method),
+			zap.Duration("duration", time.Since(start)),
+			zap.Error(err),
+		)
+		return cs, err
+	}
+}
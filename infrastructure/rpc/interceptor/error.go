@@ -7,7 +7,6 @@ import (
 
 	"github.com/muyi-zcy/tech-muyi-base-go/myException"
 	"github.com/muyi-zcy/tech-muyi-base-go/myLocale"
-	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -19,7 +18,7 @@ func Recovery() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				myLogger.Error("gRPC panic recovered",
+				logger.Error("gRPC panic recovered",
 					zap.Any("panic", r),
 					zap.String("method", info.FullMethod),
 					zap.String("stack", string(debug.Stack())),
@@ -31,6 +30,23 @@ func Recovery() grpc.UnaryServerInterceptor {
 	}
 }
 
+// StreamRecovery Recovery 的流式版本
+func StreamRecovery() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("gRPC stream panic recovered",
+					zap.Any("panic", r),
+					zap.String("method", info.FullMethod),
+					zap.String("stack", string(debug.Stack())),
+				)
+				err = status.Error(codes.Internal, myException.EncodeRpcError("platform.internal_error", nil))
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
 // ErrorMapping Server：业务异常 → gRPC Status
 func ErrorMapping() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
@@ -42,6 +58,13 @@ func ErrorMapping() grpc.UnaryServerInterceptor {
 	}
 }
 
+// StreamErrorMapping ErrorMapping 的流式版本
+func StreamErrorMapping() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return ToGrpcStatus(handler(srv, ss))
+	}
+}
+
 // ClientErrorDecode Client：gRPC Status → 业务异常
 func ClientErrorDecode() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
@@ -53,6 +76,17 @@ func ClientErrorDecode() grpc.UnaryClientInterceptor {
 	}
 }
 
+// StreamClientErrorDecode ClientErrorDecode 的流式版本，建流及 Send/Recv 错误均还原为业务异常
+func StreamClientErrorDecode() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, FromGrpcStatus(err)
+		}
+		return &decodingClientStream{ClientStream: cs}, nil
+	}
+}
+
 // ToGrpcStatus 将 error 转为 gRPC status
 func ToGrpcStatus(err error) error {
 	if err == nil {
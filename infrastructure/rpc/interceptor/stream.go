@@ -0,0 +1,51 @@
+package interceptor
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+)
+
+// wrappedServerStream 替换 Context()，使流式 handler 读到拦截器写入的 myContext 值
+type wrappedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedServerStream) Context() context.Context { return s.ctx }
+
+// WrapServerStream 用 ctx 包装 ServerStream；ctx 未变化时原样返回
+func WrapServerStream(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	if ctx == nil || ctx == ss.Context() {
+		return ss
+	}
+	if w, ok := ss.(*wrappedServerStream); ok {
+		return &wrappedServerStream{ServerStream: w.ServerStream, ctx: ctx}
+	}
+	return &wrappedServerStream{ServerStream: ss, ctx: ctx}
+}
+
+// decodingClientStream Client 流上的 gRPC Status 还原为业务异常（io.EOF 原样返回）
+type decodingClientStream struct {
+	grpc.ClientStream
+}
+
+func (s *decodingClientStream) SendMsg(m any) error {
+	return decodeStreamErr(s.ClientStream.SendMsg(m))
+}
+
+func (s *decodingClientStream) RecvMsg(m any) error {
+	return decodeStreamErr(s.ClientStream.RecvMsg(m))
+}
+
+func (s *decodingClientStream) CloseSend() error {
+	return decodeStreamErr(s.ClientStream.CloseSend())
+}
+
+func decodeStreamErr(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	return FromGrpcStatus(err)
+}
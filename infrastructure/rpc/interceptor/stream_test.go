@@ -0,0 +1,106 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	recvErr error
+}
+
+func (s *fakeClientStream) RecvMsg(any) error { return s.recvErr }
+
+var streamInfo = &grpc.StreamServerInfo{FullMethod: "/demo.Demo/Watch", IsServerStream: true}
+
+func TestStreamContextPropagation(t *testing.T) {
+	ctx := myContext.WithToken(myContext.WithTraceId(context.Background(), "trace-1"), "tok")
+	var outgoing metadata.MD
+	streamer := func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return &fakeClientStream{}, nil
+	}
+	if _, err := StreamContextInject("order-service")(ctx, &grpc.StreamDesc{}, nil, streamInfo.FullMethod, streamer); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		myContext.HeaderTraceId:       "trace-1",
+		myContext.HeaderToken:         "tok",
+		myContext.HeaderSourceService: "order-service",
+	} {
+		if got := outgoing.Get(key); len(got) != 1 || got[0] != want {
+			t.Errorf("outgoing %s = %v, want %s", key, got, want)
+		}
+	}
+
+	// 服务端：同一份 metadata 经 StreamContextExtract 后在 stream.Context() 中可读
+	ss := &fakeServerStream{ctx: metadata.NewIncomingContext(context.Background(), outgoing)}
+	err := StreamContextExtract()(nil, ss, streamInfo, func(_ any, stream grpc.ServerStream) error {
+		if got := myContext.TryGetTraceId(stream.Context()); got != "trace-1" {
+			t.Errorf("traceId = %q", got)
+		}
+		if got := myContext.TryGetSourceService(stream.Context()); got != "order-service" {
+			t.Errorf("sourceService = %q", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStreamErrorParity(t *testing.T) {
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	err := StreamRecovery()(nil, ss, streamInfo, func(any, grpc.ServerStream) error { panic("boom") })
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("recovered panic = %v", err)
+	}
+
+	bizErr := myException.NewBizError("platform.forbidden", map[string]string{"reason": "tenant"})
+	err = StreamErrorMapping()(nil, ss, streamInfo, func(any, grpc.ServerStream) error { return bizErr })
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("mapped code = %v", status.Code(err))
+	}
+
+	// 客户端：Recv 错误还原为业务异常，io.EOF 原样返回
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &fakeClientStream{recvErr: err}, nil
+	}
+	cs, err := StreamClientErrorDecode()(context.Background(), &grpc.StreamDesc{}, nil, streamInfo.FullMethod, streamer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded *myException.BizError
+	if err := cs.RecvMsg(nil); !errors.As(err, &decoded) || decoded.Code != "platform.forbidden" || decoded.Args["reason"] != "tenant" {
+		t.Fatalf("decoded = %v", err)
+	}
+	eof := &decodingClientStream{ClientStream: &fakeClientStream{recvErr: io.EOF}}
+	if err := eof.RecvMsg(nil); err != io.EOF {
+		t.Fatalf("EOF = %v", err)
+	}
+
+	failing := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return nil, status.Error(codes.Unauthenticated, "expired")
+	}
+	if _, err := StreamClientErrorDecode()(context.Background(), &grpc.StreamDesc{}, nil, streamInfo.FullMethod, failing); !errors.As(err, &decoded) || decoded.Code != "platform.unauthorized" {
+		t.Fatalf("stream open err = %v", err)
+	}
+}
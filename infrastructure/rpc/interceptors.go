@@ -7,8 +7,9 @@ import (
 )
 
 var (
-	extraUnaryServerInterceptors []grpc.UnaryServerInterceptor
-	extraInterceptorMu           sync.RWMutex
+	extraUnaryServerInterceptors  []grpc.UnaryServerInterceptor
+	extraStreamServerInterceptors []grpc.StreamServerInterceptor
+	extraInterceptorMu            sync.RWMutex
 )
 
 // RegisterUnaryServerInterceptor 注册额外 Unary 拦截器。
@@ -33,3 +34,26 @@ func appendExtraUnaryServerInterceptors(chain []grpc.UnaryServerInterceptor) []g
 	out = append(out, extraUnaryServerInterceptors...)
 	return out
 }
+
+// RegisterStreamServerInterceptor 注册额外 Stream 拦截器，时机与位置同 RegisterUnaryServerInterceptor。
+// 需要替换 context 时用 interceptor.WrapServerStream 包装 ServerStream。
+func RegisterStreamServerInterceptor(i grpc.StreamServerInterceptor) {
+	if i == nil {
+		return
+	}
+	extraInterceptorMu.Lock()
+	defer extraInterceptorMu.Unlock()
+	extraStreamServerInterceptors = append(extraStreamServerInterceptors, i)
+}
+
+func appendExtraStreamServerInterceptors(chain []grpc.StreamServerInterceptor) []grpc.StreamServerInterceptor {
+	extraInterceptorMu.RLock()
+	defer extraInterceptorMu.RUnlock()
+	if len(extraStreamServerInterceptors) == 0 {
+		return chain
+	}
+	out := make([]grpc.StreamServerInterceptor, 0, len(chain)+len(extraStreamServerInterceptors))
+	out = append(out, chain...)
+	out = append(out, extraStreamServerInterceptors...)
+	return out
+}
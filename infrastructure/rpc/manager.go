@@ -2,15 +2,20 @@ package rpc
 
 import (
 	"context"
+	"maps"
 	"net"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/muyi-zcy/tech-muyi-base-go/config"
-	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/nacos"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/metrics"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
+	rpcbalancer "github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/balancer"
 	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/interceptor"
 	rpcresolver "github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/resolver"
-	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/tracing"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -28,14 +33,16 @@ type grpcManager struct {
 	registrars  []ServiceRegistrar
 	healthSrv   *health.Server
 	listener    net.Listener
+	running     atomic.Bool
 	buildOnce   sync.Once
 	startOnce   sync.Once
+	buildErr    error
 }
 
 var resolversRegistered sync.Once
 
-func newGrpcManager(appCfg *config.Config, registry nacos.Registry, rpcCfg config.RpcConfig) *grpcManager {
-	registerResolversOnce(registry, rpcCfg)
+func newGrpcManager(appCfg *config.Config, reg registry.Registry, rpcCfg config.RpcConfig) *grpcManager {
+	registerResolversOnce(appCfg, reg, rpcCfg)
 
 	if rpcCfg.Server.Port <= 0 {
 		rpcCfg.Server.Port = 9080
@@ -51,8 +58,9 @@ func newGrpcManager(appCfg *config.Config, registry nacos.Registry, rpcCfg confi
 		maxRecvSize: maxRecv,
 		client:      newClientManager(appCfg, rpcCfg),
 	}
+	config.OnChange(mgr.onConfigChange)
 
-	myLogger.Info("RPC 插件已启用",
+	logger.Info("RPC 插件已启用",
 		zap.Int("grpcPort", rpcCfg.Server.Port),
 		zap.String("registry", rpcCfg.Registry),
 	)
@@ -64,6 +72,17 @@ func (m *grpcManager) buildServer() {
 		chain := []grpc.UnaryServerInterceptor{
 			interceptor.Recovery(),
 			interceptor.ContextExtract(),
+			tracing.UnaryServerInterceptor(),
+		}
+		streamChain := []grpc.StreamServerInterceptor{
+			interceptor.StreamRecovery(),
+			interceptor.StreamContextExtract(),
+			tracing.StreamServerInterceptor(),
+		}
+		// 指标位于 Recovery 外层，panic 恢复后的 Internal 也计入
+		if metrics.Enabled() {
+			chain = append([]grpc.UnaryServerInterceptor{metrics.UnaryServerInterceptor()}, chain...)
+			streamChain = append([]grpc.StreamServerInterceptor{metrics.StreamServerInterceptor()}, streamChain...)
 		}
 		chain = appendExtraUnaryServerInterceptors(chain)
 		chain = append(chain,
@@ -71,15 +90,31 @@ func (m *grpcManager) buildServer() {
 			interceptor.ErrorMapping(),
 		)
 
+		streamChain = appendExtraStreamServerInterceptors(streamChain)
+		streamChain = append(streamChain,
+			interceptor.StreamLogging(),
+			interceptor.StreamErrorMapping(),
+		)
+
 		opts := []grpc.ServerOption{
 			grpc.ChainUnaryInterceptor(chain...),
+			grpc.ChainStreamInterceptor(streamChain...),
 			grpc.MaxRecvMsgSize(m.maxRecvSize),
 		}
+		// TLS 配置错误时仍构建 Server 以便注册服务，但 Start 拒绝以明文启动
+		creds, err := serverCredentials(m.cfg.Server.TLS)
+		if err != nil {
+			m.buildErr = errors.Wrap(err, "gRPC Server TLS 配置无效")
+			logger.Error("gRPC Server TLS 配置无效", zap.Error(err))
+		} else if creds != nil {
+			opts = append(opts, grpc.Creds(creds))
+		}
 
 		srv := grpc.NewServer(opts...)
+		// 启动后由 core 按就绪检查结果同步为 SERVING / NOT_SERVING
 		healthSrv := health.NewServer()
 		healthpb.RegisterHealthServer(srv, healthSrv)
-		healthSrv.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		healthSrv.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
 
 		if m.cfg.Server.EnableReflection {
 			reflection.Register(srv)
@@ -90,21 +125,33 @@ func (m *grpcManager) buildServer() {
 	})
 }
 
-func registerResolversOnce(registry nacos.Registry, rpcCfg config.RpcConfig) {
+func registerResolversOnce(appCfg *config.Config, reg registry.Registry, rpcCfg config.RpcConfig) {
 	resolversRegistered.Do(func() {
-		rpcresolver.RegisterStatic(rpcCfg.Static)
-		if rpcCfg.Registry != "static" {
-			if naming := registry.NamingClient(); naming != nil {
-				group := config.GetNacosConfig().Group
-				if group == "" {
-					group = "XI_PLATFORM"
-				}
-				rpcresolver.RegisterNacos(naming, group)
+		rpcbalancer.Register()
+		interval := time.Duration(rpcCfg.StaticRefreshSeconds) * time.Second
+		if interval <= 0 {
+			interval = rpcresolver.DefaultRefreshInterval
+		}
+		rpcresolver.RegisterStaticWithInterval(rpcCfg.Static, interval)
+		if rpcCfg.Registry != registry.Static {
+			if naming := reg.NamingClient(); naming != nil {
+				rpcresolver.RegisterNaming(rpcCfg.Registry, naming, registry.Group(appCfg))
 			}
 		}
 	})
 }
 
+// onConfigChange plugins.rpc.static 变更后更新 resolver，已建立的连接通过 UpdateState 切换地址
+func (m *grpcManager) onConfigChange(oldCfg, newCfg *config.Config) {
+	static := newCfg.Plugins.Rpc.Static
+	if oldCfg != nil && maps.Equal(oldCfg.Plugins.Rpc.Static, static) {
+		return
+	}
+	m.client.setStaticAddrs(static)
+	rpcresolver.UpdateStatic(static)
+	logger.Info("plugins.rpc.static 已热更新", zap.Int("services", len(static)))
+}
+
 func (m *grpcManager) Enabled() bool { return true }
 
 func (m *grpcManager) Server() *grpc.Server {
@@ -124,14 +171,24 @@ func (m *grpcManager) Start(_ context.Context, lis net.Listener) error {
 	var startErr error
 	m.startOnce.Do(func() {
 		m.buildServer()
+		if m.buildErr != nil {
+			startErr = m.buildErr
+			return
+		}
+		if err := m.client.buildRegisteredClients(); err != nil {
+			startErr = err
+			return
+		}
 		for _, reg := range m.registrars {
 			reg(m.server)
 		}
 		m.listener = lis
+		m.running.Store(true)
 		go func() {
-			myLogger.Info("gRPC Server 启动", zap.String("addr", lis.Addr().String()))
+			defer m.running.Store(false)
+			logger.Info("gRPC Server 启动", zap.String("addr", lis.Addr().String()), zap.Bool("tls", m.cfg.Server.TLS.Enabled))
 			if err := m.server.Serve(lis); err != nil {
-				myLogger.Error("gRPC Server 异常退出", zap.Error(err))
+				logger.Error("gRPC Server 异常退出", zap.Error(err))
 			}
 		}()
 	})
@@ -144,8 +201,35 @@ func (m *grpcManager) GracefulStop() {
 	}
 }
 
-func (m *grpcManager) Shutdown(_ context.Context) error {
-	m.GracefulStop()
+func (m *grpcManager) Running() bool { return m.running.Load() }
+
+func (m *grpcManager) SetServingStatus(serving bool) {
+	m.buildServer()
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	m.healthSrv.SetServingStatus("", status)
+}
+
+// Shutdown 优雅停止，ctx 到期后强制关闭仍未结束的请求
+func (m *grpcManager) Shutdown(ctx context.Context) error {
+	if m.healthSrv != nil {
+		m.healthSrv.Shutdown()
+	}
+	if m.server != nil {
+		done := make(chan struct{})
+		go func() {
+			m.server.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			logger.Warn("gRPC Server 优雅关闭超时，强制停止")
+			m.server.Stop()
+		}
+	}
 	if m.client != nil {
 		return m.client.Close()
 	}
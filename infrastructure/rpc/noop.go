@@ -26,3 +26,7 @@ func (n *noopManager) Start(_ context.Context, _ net.Listener) error { return ni
 func (n *noopManager) GracefulStop() {}
 
 func (n *noopManager) Shutdown(_ context.Context) error { return nil }
+
+func (n *noopManager) Running() bool { return false }
+
+func (n *noopManager) SetServingStatus(_ bool) {}
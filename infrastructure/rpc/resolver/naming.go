@@ -0,0 +1,129 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
+	rpcbalancer "github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/balancer"
+	"google.golang.org/grpc/resolver"
+)
+
+// NamingBuilder {scheme}:///serviceName，scheme 与注册中心名称一致（nacos / etcd / consul / file）
+type NamingBuilder struct {
+	// Name resolver scheme
+	Name   string
+	Naming registry.NamingClient
+	Group  string
+}
+
+func NewNamingBuilder(scheme string, naming registry.NamingClient, group string) *NamingBuilder {
+	return &NamingBuilder{Name: scheme, Naming: naming, Group: group}
+}
+
+// NacosBuilder nacos:///serviceName
+type NacosBuilder = NamingBuilder
+
+func NewNacosBuilder(naming registry.NamingClient, group string) *NacosBuilder {
+	return NewNamingBuilder(registry.Nacos, naming, group)
+}
+
+func (b *NamingBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	if b.Naming == nil {
+		return nil, fmt.Errorf("%s resolver: naming client is nil", b.Name)
+	}
+	serviceName := target.URL.Host
+	if serviceName == "" {
+		serviceName = stringsTrimPrefix(target.Endpoint(), "/")
+	}
+	r := &namingResolver{
+		cc:          cc,
+		naming:      b.Naming,
+		group:       b.Group,
+		serviceName: serviceName,
+	}
+	if err := r.start(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (b *NamingBuilder) Scheme() string { return b.Name }
+
+type namingResolver struct {
+	cc          resolver.ClientConn
+	naming      registry.NamingClient
+	group       string
+	serviceName string
+	cancel      context.CancelFunc
+	mu          sync.Mutex
+}
+
+func (r *namingResolver) start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	instances, err := r.naming.SelectInstances(r.serviceName, r.group)
+	if err != nil {
+		return err
+	}
+	r.updateAddresses(instances)
+
+	return r.naming.Subscribe(r.serviceName, r.group, func(instances []registry.ServiceInstance) {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			r.updateAddresses(instances)
+		}
+	})
+}
+
+func (r *namingResolver) updateAddresses(instances []registry.ServiceInstance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	addrs := make([]resolver.Address, 0, len(instances))
+	for _, inst := range instances {
+		if !inst.Healthy {
+			continue
+		}
+		addr := resolver.Address{Addr: fmt.Sprintf("%s:%d", inst.IP, inst.Port)}
+		addrs = append(addrs, rpcbalancer.WithInstance(addr, rpcbalancer.Instance{
+			Weight:   rpcbalancer.NacosWeight(inst.Weight),
+			Metadata: inst.Metadata,
+		}))
+	}
+	_ = r.cc.UpdateState(resolver.State{Addresses: addrs})
+}
+
+func (r *namingResolver) ResolveNow(resolver.ResolveNowOptions) {
+	instances, err := r.naming.SelectInstances(r.serviceName, r.group)
+	if err == nil {
+		r.updateAddresses(instances)
+	}
+}
+
+func (r *namingResolver) Close() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	_ = r.naming.Unsubscribe(r.serviceName, r.group)
+}
+
+// RegisterNaming 注册 scheme 对应的注册中心 resolver
+func RegisterNaming(scheme string, naming registry.NamingClient, group string) {
+	resolver.Register(NewNamingBuilder(scheme, naming, group))
+}
+
+// RegisterNacos 注册 nacos resolver
+func RegisterNacos(naming registry.NamingClient, group string) {
+	RegisterNaming(registry.Nacos, naming, group)
+}
+
+func stringsTrimPrefix(s, prefix string) string {
+	if len(s) >= len(prefix) && s[:len(prefix)] == prefix {
+		return s[len(prefix):]
+	}
+	return s
+}
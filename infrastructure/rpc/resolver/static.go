@@ -1,63 +1,293 @@
 package resolver
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/resolver"
 )
 
+// logger 命名日志，级别按 log.levels 中的 rpc.resolver 或上级 rpc 调整
+var logger = myLogger.Named("rpc.resolver")
+
 const staticScheme = "static"
 
-// StaticBuilder static:///host:port 或 static:///serviceKey（从 map 查地址）
+// DefaultRefreshInterval 含主机名时重新解析 DNS 的默认间隔
+const DefaultRefreshInterval = 30 * time.Second
+
+// StaticBuilder static:///host:port[,host:port] 或 static:///serviceKey（从 map 查地址，逗号分隔多个）。
+// 主机名按 RefreshInterval 重新解析；Update 替换地址表后已建立的连接随之更新。
 type StaticBuilder struct {
-	Addresses map[string]string
+	RefreshInterval time.Duration
+	// resolveHost 主机名解析，默认 net.DefaultResolver.LookupHost
+	resolveHost func(ctx context.Context, host string) ([]string, error)
+
+	mu        sync.RWMutex
+	addresses map[string]string
+	resolvers map[*staticResolver]struct{}
 }
 
 func NewStaticBuilder(addresses map[string]string) *StaticBuilder {
-	return &StaticBuilder{Addresses: addresses}
+	return &StaticBuilder{
+		RefreshInterval: DefaultRefreshInterval,
+		resolveHost:     net.DefaultResolver.LookupHost,
+		addresses:       addresses,
+		resolvers:       map[*staticResolver]struct{}{},
+	}
 }
 
 func (b *StaticBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
-	addr := target.URL.Host
-	if addr == "" {
-		addr = strings.TrimPrefix(target.URL.Path, "/")
+	key := target.URL.Host
+	if key == "" {
+		key = strings.TrimPrefix(target.URL.Path, "/")
 	}
-	if addr == "" {
-		addr = strings.TrimPrefix(target.Endpoint(), "/")
+	if key == "" {
+		key = strings.TrimPrefix(target.Endpoint(), "/")
 	}
-	if mapped, ok := b.Addresses[addr]; ok {
-		addr = mapped
-	}
-	if !strings.Contains(addr, ":") {
-		return nil, fmt.Errorf("static resolver: invalid address %q", addr)
+	spec := b.lookup(key)
+	if _, err := parseStaticAddrs(spec); err != nil {
+		return nil, fmt.Errorf("static resolver: %w", err)
 	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	r := &staticResolver{
-		cc:   cc,
-		addr: addr,
+		cc:       cc,
+		builder:  b,
+		key:      key,
+		spec:     spec,
+		ctx:      ctx,
+		cancel:   cancel,
+		resolved: map[string][]string{},
+		now:      make(chan struct{}, 1),
 	}
-	r.start()
+	b.mu.Lock()
+	b.resolvers[r] = struct{}{}
+	b.mu.Unlock()
+
+	r.resolve()
+	go r.run()
 	return r, nil
 }
 
 func (b *StaticBuilder) Scheme() string { return staticScheme }
 
+// lookup key 为已配置的 serviceKey 时返回其地址，否则视为地址本身
+func (b *StaticBuilder) lookup(key string) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if mapped, ok := b.addresses[key]; ok && mapped != "" {
+		return mapped
+	}
+	return key
+}
+
+// Update 替换地址表，地址有变化的 resolver 立即重新解析并推送
+func (b *StaticBuilder) Update(addresses map[string]string) {
+	b.mu.Lock()
+	b.addresses = addresses
+	resolvers := make([]*staticResolver, 0, len(b.resolvers))
+	for r := range b.resolvers {
+		resolvers = append(resolvers, r)
+	}
+	b.mu.Unlock()
+
+	for _, r := range resolvers {
+		r.setSpec(b.lookup(r.key))
+	}
+}
+
+func (b *StaticBuilder) remove(r *staticResolver) {
+	b.mu.Lock()
+	delete(b.resolvers, r)
+	b.mu.Unlock()
+}
+
+func (b *StaticBuilder) refreshInterval() time.Duration {
+	if b.RefreshInterval > 0 {
+		return b.RefreshInterval
+	}
+	return DefaultRefreshInterval
+}
+
+type staticAddr struct {
+	host string
+	port string
+}
+
+// parseStaticAddrs 解析逗号分隔的 host:port 列表
+func parseStaticAddrs(spec string) ([]staticAddr, error) {
+	var addrs []staticAddr
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		host, port, err := net.SplitHostPort(item)
+		if err != nil || host == "" || port == "" {
+			return nil, fmt.Errorf("invalid address %q", item)
+		}
+		addrs = append(addrs, staticAddr{host: host, port: port})
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("invalid address %q", spec)
+	}
+	return addrs, nil
+}
+
 type staticResolver struct {
-	cc   resolver.ClientConn
-	addr string
+	cc      resolver.ClientConn
+	builder *StaticBuilder
+	key     string
+	ctx     context.Context
+	cancel  context.CancelFunc
+	now     chan struct{}
+
+	mu   sync.Mutex
+	spec string
+	// resolved 主机名最近一次解析成功的 IP，解析失败时沿用
+	resolved map[string][]string
+	hasHost  bool
+}
+
+func (r *staticResolver) run() {
+	ticker := time.NewTicker(r.builder.refreshInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			r.mu.Lock()
+			hasHost := r.hasHost
+			r.mu.Unlock()
+			if hasHost {
+				r.resolve()
+			}
+		case <-r.now:
+			r.resolve()
+		}
+	}
+}
+
+func (r *staticResolver) setSpec(spec string) {
+	r.mu.Lock()
+	changed := r.spec != spec
+	r.spec = spec
+	r.mu.Unlock()
+	if changed {
+		logger.Info("static 地址已更新", zap.String("service", r.key), zap.String("addrs", spec))
+		r.resolve()
+	}
+}
+
+// resolve 解析当前地址列表并推送；IP 直接使用，主机名解析为 IP（同时有 IPv4 时仅用 IPv4）
+func (r *staticResolver) resolve() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	addrs, err := parseStaticAddrs(r.spec)
+	if err != nil {
+		r.cc.ReportError(fmt.Errorf("static resolver: %w", err))
+		return
+	}
+
+	var state []resolver.Address
+	seen := map[string]bool{}
+	resolved := map[string][]string{}
+	r.hasHost = false
+	for _, a := range addrs {
+		ips := []string{a.host}
+		serverName := ""
+		if net.ParseIP(a.host) == nil {
+			r.hasHost = true
+			serverName = a.host
+			ips = r.lookupHost(a.host)
+			resolved[a.host] = ips
+		}
+		for _, ip := range ips {
+			addr := net.JoinHostPort(ip, a.port)
+			if seen[addr] {
+				continue
+			}
+			seen[addr] = true
+			state = append(state, resolver.Address{Addr: addr, ServerName: serverName})
+		}
+	}
+	r.resolved = resolved
+	if len(state) == 0 {
+		r.cc.ReportError(fmt.Errorf("static resolver: no address resolved for %q", r.spec))
+		return
+	}
+	sort.Slice(state, func(i, j int) bool { return state[i].Addr < state[j].Addr })
+	_ = r.cc.UpdateState(resolver.State{Addresses: state})
 }
 
-func (r *staticResolver) start() {
-	_ = r.cc.UpdateState(resolver.State{
-		Addresses: []resolver.Address{{Addr: r.addr}},
-	})
+// lookupHost 调用方须持有 r.mu；解析失败时返回上次结果
+func (r *staticResolver) lookupHost(host string) []string {
+	ctx, cancel := context.WithTimeout(r.ctx, 5*time.Second)
+	defer cancel()
+	ips, err := r.builder.resolveHost(ctx, host)
+	if err != nil || len(ips) == 0 {
+		logger.Warn("static 地址 DNS 解析失败，沿用上次结果", zap.String("host", host), zap.Error(err))
+		return r.resolved[host]
+	}
+	var v4 []string
+	for _, ip := range ips {
+		if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() != nil {
+			v4 = append(v4, ip)
+		}
+	}
+	if len(v4) > 0 {
+		return v4
+	}
+	return ips
 }
 
-func (r *staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+// ResolveNow 连接失败时由 gRPC 触发，异步重新解析
+func (r *staticResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.now <- struct{}{}:
+	default:
+	}
+}
 
-func (r *staticResolver) Close() {}
+func (r *staticResolver) Close() {
+	r.cancel()
+	r.builder.remove(r)
+}
+
+var (
+	staticBuilder   *StaticBuilder
+	staticBuilderMu sync.Mutex
+)
 
 // RegisterStatic 注册 static resolver
 func RegisterStatic(addresses map[string]string) {
-	resolver.Register(NewStaticBuilder(addresses))
+	RegisterStaticWithInterval(addresses, DefaultRefreshInterval)
+}
+
+// RegisterStaticWithInterval 注册 static resolver 并指定 DNS 重新解析间隔
+func RegisterStaticWithInterval(addresses map[string]string, interval time.Duration) {
+	b := NewStaticBuilder(addresses)
+	b.RefreshInterval = interval
+	staticBuilderMu.Lock()
+	staticBuilder = b
+	staticBuilderMu.Unlock()
+	resolver.Register(b)
+}
+
+// UpdateStatic 热更新已注册 static resolver 的地址表
+func UpdateStatic(addresses map[string]string) {
+	staticBuilderMu.Lock()
+	b := staticBuilder
+	staticBuilderMu.Unlock()
+	if b != nil {
+		b.Update(addresses)
+	}
 }
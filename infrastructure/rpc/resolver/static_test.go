@@ -0,0 +1,170 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// fakeClientConn 记录 resolver 推送的地址
+type fakeClientConn struct {
+	resolver.ClientConn
+	states chan []resolver.Address
+	errs   chan error
+}
+
+func newFakeClientConn() *fakeClientConn {
+	return &fakeClientConn{states: make(chan []resolver.Address, 16), errs: make(chan error, 16)}
+}
+
+func (c *fakeClientConn) UpdateState(s resolver.State) error {
+	c.states <- s.Addresses
+	return nil
+}
+
+func (c *fakeClientConn) ReportError(err error) { c.errs <- err }
+
+func (c *fakeClientConn) next(t *testing.T) []resolver.Address {
+	t.Helper()
+	select {
+	case addrs := <-c.states:
+		return addrs
+	case <-time.After(2 * time.Second):
+		t.Fatal("no state pushed")
+		return nil
+	}
+}
+
+// fakeDNS 可在测试中修改解析结果，err 非空时解析失败
+type fakeDNS struct {
+	mu    sync.Mutex
+	hosts map[string][]string
+	err   error
+}
+
+func (d *fakeDNS) set(host string, ips []string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.hosts[host] = ips
+	d.err = err
+}
+
+func (d *fakeDNS) lookup(_ context.Context, host string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return nil, d.err
+	}
+	return d.hosts[host], nil
+}
+
+func addrList(addrs []resolver.Address) string {
+	parts := make([]string, len(addrs))
+	for i, a := range addrs {
+		parts[i] = a.Addr
+		if a.ServerName != "" {
+			parts[i] += "(" + a.ServerName + ")"
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func buildStatic(t *testing.T, b *StaticBuilder, key string) (*fakeClientConn, resolver.Resolver) {
+	t.Helper()
+	cc := newFakeClientConn()
+	r, err := b.Build(resolver.Target{URL: url.URL{Scheme: staticScheme, Path: "/" + key}}, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(r.Close)
+	return cc, r
+}
+
+func TestStaticResolverAddresses(t *testing.T) {
+	dns := &fakeDNS{hosts: map[string][]string{"user.svc": {"fd00::1", "10.0.1.2", "10.0.1.1"}}}
+	b := NewStaticBuilder(map[string]string{"user": "10.0.0.2:9081, user.svc:9081 ,10.0.0.2:9081"})
+	b.resolveHost = dns.lookup
+
+	cc, _ := buildStatic(t, b, "user")
+	// 去重、排序，主机名只取 IPv4 并保留 ServerName 供 TLS 校验
+	if got := addrList(cc.next(t)); got != "10.0.0.2:9081,10.0.1.1:9081(user.svc),10.0.1.2:9081(user.svc)" {
+		t.Fatalf("addresses = %s", got)
+	}
+
+	// 未配置的 key 视为地址本身
+	direct, _ := buildStatic(t, b, "127.0.0.1:9090")
+	if got := addrList(direct.next(t)); got != "127.0.0.1:9090" {
+		t.Fatalf("direct = %s", got)
+	}
+
+	if _, err := b.Build(resolver.Target{URL: url.URL{Scheme: staticScheme, Path: "/no-port"}}, newFakeClientConn(), resolver.BuildOptions{}); err == nil {
+		t.Fatal("expected error for address without port")
+	}
+}
+
+func TestStaticResolverDNSRefresh(t *testing.T) {
+	dns := &fakeDNS{hosts: map[string][]string{"user.svc": {"10.0.1.1"}}}
+	b := NewStaticBuilder(map[string]string{"user": "user.svc:9081"})
+	b.RefreshInterval = 10 * time.Millisecond
+	b.resolveHost = dns.lookup
+
+	cc, _ := buildStatic(t, b, "user")
+	if got := addrList(cc.next(t)); got != "10.0.1.1:9081(user.svc)" {
+		t.Fatalf("initial = %s", got)
+	}
+
+	dns.set("user.svc", []string{"10.0.1.3"}, nil)
+	deadline := time.After(2 * time.Second)
+	for {
+		var got string
+		select {
+		case addrs := <-cc.states:
+			got = addrList(addrs)
+		case <-deadline:
+			t.Fatal("re-resolved address not pushed")
+		}
+		if got == "10.0.1.3:9081(user.svc)" {
+			break
+		}
+	}
+
+	// 解析失败时沿用上次结果，不推送空列表
+	dns.set("user.svc", nil, errors.New("SERVFAIL"))
+	for i := 0; i < 3; i++ {
+		if got := addrList(cc.next(t)); got != "10.0.1.3:9081(user.svc)" {
+			t.Fatalf("after lookup failure = %s", got)
+		}
+	}
+}
+
+func TestStaticResolverUpdate(t *testing.T) {
+	b := NewStaticBuilder(map[string]string{"user": "10.0.0.1:9081", "order": "10.0.0.5:9081"})
+	b.RefreshInterval = time.Hour
+	user, _ := buildStatic(t, b, "user")
+	order, _ := buildStatic(t, b, "order")
+	user.next(t)
+	order.next(t)
+
+	b.Update(map[string]string{"user": "10.0.0.1:9081,10.0.0.2:9081", "order": "10.0.0.5:9081"})
+	if got := addrList(user.next(t)); got != "10.0.0.1:9081,10.0.0.2:9081" {
+		t.Fatalf("updated = %s", got)
+	}
+	select {
+	case addrs := <-order.states:
+		t.Fatalf("unchanged service re-pushed: %s", addrList(addrs))
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	b.Update(map[string]string{"user": "bad", "order": "10.0.0.5:9081"})
+	select {
+	case <-user.errs:
+	case <-time.After(2 * time.Second):
+		t.Fatal("invalid update not reported")
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"net"
 
 	"github.com/muyi-zcy/tech-muyi-base-go/config"
-	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/nacos"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/registry"
 	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
 
+// logger 命名日志，级别可由 log.levels.rpc 单独调整
+var logger = myLogger.Named("rpc")
+
 // ServiceRegistrar 业务服务 gRPC 注册回调
 type ServiceRegistrar func(*grpc.Server)
 
@@ -24,20 +28,24 @@ type Manager interface {
 	Start(ctx context.Context, lis net.Listener) error
 	GracefulStop()
 	Shutdown(ctx context.Context) error
+	// Running gRPC Server 是否在监听
+	Running() bool
+	// SetServingStatus 设置 grpc.health.v1 的整体服务状态，随 HTTP 就绪状态同步
+	SetServingStatus(serving bool)
 }
 
 var globalManager Manager = &noopManager{}
 
 // Init 根据配置初始化 RPC Manager
-func Init(cfg *config.Config, registry nacos.Registry) Manager {
-	m := initManager(cfg, registry)
+func Init(cfg *config.Config, reg registry.Registry) Manager {
+	m := initManager(cfg, reg)
 	globalManager = m
 	return m
 }
 
 // InitFromConfig 从全局配置初始化
-func InitFromConfig(registry nacos.Registry) Manager {
-	return Init(config.GetConfig(), registry)
+func InitFromConfig(reg registry.Registry) Manager {
+	return Init(config.GetConfig(), reg)
 }
 
 // GetManager 获取全局 Manager
@@ -50,14 +58,15 @@ func SetManager(m Manager) {
 	globalManager = m
 }
 
-func initManager(cfg *config.Config, registry nacos.Registry) Manager {
+func initManager(cfg *config.Config, reg registry.Registry) Manager {
 	if cfg == nil || !cfg.Plugins.Rpc.Enabled {
 		return &noopManager{}
 	}
 	rpcCfg := cfg.Plugins.Rpc
-	if rpcCfg.Registry == "nacos" && !registry.Enabled() {
-		myLogger.Warn("Nacos 未启用或初始化失败，RPC 自动降级为 static 模式")
-		rpcCfg.Registry = "static"
+	rpcCfg.Registry = registry.Backend(cfg)
+	if rpcCfg.Registry != registry.Static && !reg.Enabled() {
+		logger.Warn("注册中心未启用或初始化失败，RPC 自动降级为 static 模式", zap.String("registry", rpcCfg.Registry))
+		rpcCfg.Registry = registry.Static
 	}
-	return newGrpcManager(cfg, registry, rpcCfg)
+	return newGrpcManager(cfg, reg, rpcCfg)
 }
@@ -0,0 +1,281 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const defaultTLSReloadInterval = 30 * time.Second
+
+// certReloader 持有当前证书与 CA，握手时按间隔检查文件修改时间，变更则重新加载；加载失败保留旧证书。
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	interval time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  map[string]time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(cfg config.RpcTLSConfig) (*certReloader, error) {
+	interval := time.Duration(cfg.ReloadIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultTLSReloadInterval
+	}
+	r := &certReloader{
+		certFile: strings.TrimSpace(cfg.CertFile),
+		keyFile:  strings.TrimSpace(cfg.KeyFile),
+		caFile:   strings.TrimSpace(cfg.CAFile),
+		interval: interval,
+	}
+	if (r.certFile == "") != (r.keyFile == "") {
+		return nil, errors.New("TLS certFile 与 keyFile 须同时配置")
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) files() []string {
+	var files []string
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func (r *certReloader) load() error {
+	modTimes := make(map[string]time.Time, 3)
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return errors.Wrapf(err, "读取 TLS 文件 %s 失败", f)
+		}
+		modTimes[f] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return errors.Wrap(err, "加载 TLS 证书失败")
+		}
+		cert = &c
+	}
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		data, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return errors.Wrapf(err, "读取 CA 文件 %s 失败", r.caFile)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return errors.Errorf("CA 文件 %s 不包含有效证书", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.pool, r.modTimes, r.checkedAt = cert, pool, modTimes, time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// maybeReload 距上次检查超过间隔且文件修改时间变化时重新加载。
+func (r *certReloader) maybeReload() {
+	r.mu.RLock()
+	due := time.Since(r.checkedAt) >= r.interval
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	changed := false
+	r.mu.Lock()
+	r.checkedAt = time.Now()
+	for _, f := range r.files() {
+		if info, err := os.Stat(f); err == nil && !info.ModTime().Equal(r.modTimes[f]) {
+			changed = true
+		}
+	}
+	r.mu.Unlock()
+	if !changed {
+		return
+	}
+	if err := r.load(); err != nil {
+		logger.Warn("TLS 证书重新加载失败，继续使用旧证书", zap.Error(err))
+		return
+	}
+	logger.Info("TLS 证书已重新加载", zap.String("certFile", r.certFile), zap.String("caFile", r.caFile))
+}
+
+func (r *certReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.maybeReload()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, r.pool
+}
+
+// serverTLSConfig Server 侧 TLS；每次握手通过 GetConfigForClient 取最新证书与客户端 CA。
+func serverTLSConfig(cfg config.RpcTLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, errors.New("gRPC Server TLS 须配置 certFile / keyFile")
+	}
+	r, err := newCertReloader(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, err := parseClientAuth(cfg.ClientAuth, cfg.CAFile != "")
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientAuth:   clientAuth,
+				ClientCAs:    pool,
+				NextProtos:   []string{"h2"},
+			}, nil
+		},
+	}, nil
+}
+
+// clientTLSConfig Client 侧 TLS；CA 支持热更新，故关闭内置校验改在 VerifyConnection 中按当前 CA 校验。
+func clientTLSConfig(cfg config.RpcTLSConfig) (*tls.Config, error) {
+	r, err := newCertReloader(cfg)
+	if err != nil {
+		return nil, err
+	}
+	skipVerify := cfg.InsecureSkipVerify
+	serverName := cfg.ServerName
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if skipVerify {
+				return nil
+			}
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("服务端未提供证书")
+			}
+			_, pool := r.current()
+			name := serverName
+			if name == "" {
+				name = cs.ServerName
+			}
+			opts := x509.VerifyOptions{
+				Roots:         pool,
+				DNSName:       name,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, c := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(c)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		},
+	}, nil
+}
+
+func parseClientAuth(mode string, hasCA bool) (tls.ClientAuthType, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "":
+		if hasCA {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.NoClientCert, nil
+	case "none":
+		return tls.NoClientCert, nil
+	case "request":
+		if !hasCA {
+			return 0, errors.New("clientAuth=request 须配置 caFile")
+		}
+		return tls.VerifyClientCertIfGiven, nil
+	case "require":
+		if !hasCA {
+			return 0, errors.New("clientAuth=require 须配置 caFile")
+		}
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, errors.Errorf("不支持的 clientAuth: %s（none / request / require）", mode)
+	}
+}
+
+// serverCredentials 未启用 TLS 时返回 nil（grpc 默认明文）。
+func serverCredentials(cfg config.RpcTLSConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	tlsCfg, err := serverTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+// clientCredentials 按 serviceKey 合并覆盖项后构建传输凭证。
+func clientCredentials(cfg config.RpcClientTLSConfig, serviceKey string) (credentials.TransportCredentials, error) {
+	effective := cfg.RpcTLSConfig
+	if override, ok := cfg.Services[serviceKey]; ok {
+		effective = mergeTLSConfig(effective, override)
+	}
+	if !effective.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+	tlsCfg, err := clientTLSConfig(effective)
+	if err != nil {
+		return nil, errors.Wrapf(err, "构建服务 %s 的 TLS 配置失败", serviceKey)
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+// mergeTLSConfig 非空字段覆盖默认值；任一方启用即启用。
+func mergeTLSConfig(base, override config.RpcTLSConfig) config.RpcTLSConfig {
+	out := base
+	out.Enabled = base.Enabled || override.Enabled
+	if override.CertFile != "" {
+		out.CertFile = override.CertFile
+		out.KeyFile = override.KeyFile
+	}
+	if override.CAFile != "" {
+		out.CAFile = override.CAFile
+	}
+	if override.ServerName != "" {
+		out.ServerName = override.ServerName
+	}
+	if override.InsecureSkipVerify {
+		out.InsecureSkipVerify = true
+	}
+	if override.ReloadIntervalSeconds > 0 {
+		out.ReloadIntervalSeconds = override.ReloadIntervalSeconds
+	}
+	return out
+}
@@ -0,0 +1,227 @@
+package rpc
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ClientOption 类型化客户端的调用默认值
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	timeout     time.Duration
+	metadata    []string
+	callOptions []grpc.CallOption
+}
+
+// WithCallTimeout 调用方未设置 deadline 时使用的超时，优先于 plugins.rpc.client 配置的超时
+func WithCallTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeout = d
+	}
+}
+
+// WithCallMetadata 每次调用附加的 outgoing metadata（key, value 成对）
+func WithCallMetadata(kv ...string) ClientOption {
+	return func(o *clientOptions) {
+		o.metadata = append(o.metadata, kv...)
+	}
+}
+
+// WithCallOptions 每次调用附加的 grpc.CallOption
+func WithCallOptions(opts ...grpc.CallOption) ClientOption {
+	return func(o *clientOptions) {
+		o.callOptions = append(o.callOptions, opts...)
+	}
+}
+
+type stubKey struct {
+	serviceKey string
+	typ        reflect.Type
+}
+
+type clientRegistration struct {
+	serviceKey string
+	build      func(m *ClientManager) error
+}
+
+var (
+	registeredClients   []clientRegistration
+	registeredOptions   = map[stubKey][]ClientOption{}
+	registeredClientsMu sync.Mutex
+)
+
+// Client 返回 serviceKey 对应的类型化 stub。未传 ClientOption 时首次调用后按 (serviceKey, T) 缓存，
+// 使用 RegisterClient 登记的选项；传入 ClientOption 时每次在登记的选项之上叠加这组选项新建 stub
+// （复用已缓存的连接，开销很小），调用方宜自行持有。
+// 连接失败或 RPC 未启用时返回的 stub 每次调用均返回该错误。
+//
+//	echo := rpc.Client("example_producer", pb.NewEchoServiceClient)
+//	resp, err := echo.Ping(ctx, &pb.PingRequest{})
+func Client[T any](serviceKey string, factory func(grpc.ClientConnInterface) T, opts ...ClientOption) T {
+	m := GetManager().Client()
+	if m == nil {
+		return factory(errConn{err: errors.Errorf("RPC 未启用，无法调用服务 %s", serviceKey)})
+	}
+	stub, err := typedStub(m, serviceKey, factory, opts)
+	if err != nil {
+		return factory(errConn{err: err})
+	}
+	return stub
+}
+
+// RegisterClient 启动时登记类型化客户端，Manager.Start 时统一建立；
+// serviceKey 缺少映射等配置错误会使启动失败，而不是延迟到首次调用。
+// opts 作为该 (serviceKey, T) 的默认选项，之后 Client 获取的 stub 均会应用。
+func RegisterClient[T any](serviceKey string, factory func(grpc.ClientConnInterface) T, opts ...ClientOption) {
+	registeredClientsMu.Lock()
+	defer registeredClientsMu.Unlock()
+	registeredOptions[newStubKey[T](serviceKey)] = append([]ClientOption(nil), opts...)
+	registeredClients = append(registeredClients, clientRegistration{
+		serviceKey: serviceKey,
+		build: func(m *ClientManager) error {
+			_, err := typedStub(m, serviceKey, factory, nil)
+			return err
+		},
+	})
+}
+
+func newStubKey[T any](serviceKey string) stubKey {
+	return stubKey{serviceKey: serviceKey, typ: reflect.TypeOf((*T)(nil)).Elem()}
+}
+
+// optionsFor RegisterClient 登记的选项在前，调用方传入的选项在后：超时以后者为准，metadata 与 CallOption 叠加
+func optionsFor(key stubKey, opts []ClientOption) clientOptions {
+	registeredClientsMu.Lock()
+	base := registeredOptions[key]
+	registeredClientsMu.Unlock()
+	options := clientOptions{}
+	for _, opt := range base {
+		opt(&options)
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// buildRegisteredClients 建立所有 RegisterClient 登记的 stub
+func (m *ClientManager) buildRegisteredClients() error {
+	registeredClientsMu.Lock()
+	regs := append([]clientRegistration(nil), registeredClients...)
+	registeredClientsMu.Unlock()
+	for _, reg := range regs {
+		if err := m.checkMapping(reg.serviceKey); err != nil {
+			return err
+		}
+		if err := reg.build(m); err != nil {
+			return errors.Wrapf(err, "初始化 gRPC 客户端 %s 失败", reg.serviceKey)
+		}
+	}
+	return nil
+}
+
+// checkMapping 登记的客户端须显式配置映射，不回退为以 serviceKey 直接寻址
+func (m *ClientManager) checkMapping(serviceKey string) error {
+	serviceName := m.cfg.Services[serviceKey]
+	if m.registry == "static" {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		if m.staticAddrs[serviceKey] == "" && (serviceName == "" || m.staticAddrs[serviceName] == "") {
+			return errors.Errorf("gRPC 客户端 %s 未在 plugins.rpc.static 中配置地址", serviceKey)
+		}
+		return nil
+	}
+	if serviceName == "" {
+		return errors.Errorf("gRPC 客户端 %s 未在 plugins.rpc.client.services 中配置服务名", serviceKey)
+	}
+	return nil
+}
+
+// typedStub 缓存只保存不带调用方选项的 stub（仅应用登记的选项）：ClientOption 无法比较，若参与缓存，
+// 同一 (serviceKey, T) 以不同选项获取时会拿到首个调用方的选项
+func typedStub[T any](m *ClientManager, serviceKey string, factory func(grpc.ClientConnInterface) T, opts []ClientOption) (T, error) {
+	key := newStubKey[T](serviceKey)
+	if len(opts) == 0 {
+		m.mu.RLock()
+		cached, ok := m.stubs[key]
+		m.mu.RUnlock()
+		if ok {
+			return cached.(T), nil
+		}
+	}
+
+	conn, err := m.GetConn(serviceKey)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	stub := factory(&defaultsConn{conn: conn, opts: optionsFor(key, opts)})
+	if len(opts) > 0 {
+		return stub, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cached, ok := m.stubs[key]; ok {
+		return cached.(T), nil
+	}
+	m.stubs[key] = stub
+	return stub, nil
+}
+
+// defaultsConn 在每次调用前应用 ClientOption 默认值
+type defaultsConn struct {
+	conn *grpc.ClientConn
+	opts clientOptions
+}
+
+func (c *defaultsConn) prepare(ctx context.Context, callOpts []grpc.CallOption) (context.Context, context.CancelFunc, []grpc.CallOption) {
+	cancel := context.CancelFunc(func() {})
+	if _, has := ctx.Deadline(); !has && c.opts.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.opts.timeout)
+	}
+	if len(c.opts.metadata) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, c.opts.metadata...)
+	}
+	if len(c.opts.callOptions) > 0 {
+		callOpts = append(append([]grpc.CallOption(nil), c.opts.callOptions...), callOpts...)
+	}
+	return ctx, cancel, callOpts
+}
+
+func (c *defaultsConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	ctx, cancel, opts := c.prepare(ctx, opts)
+	defer cancel()
+	return c.conn.Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream 流的生命周期由调用方控制，故不应用默认超时
+func (c *defaultsConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if len(c.opts.metadata) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, c.opts.metadata...)
+	}
+	if len(c.opts.callOptions) > 0 {
+		opts = append(append([]grpc.CallOption(nil), c.opts.callOptions...), opts...)
+	}
+	return c.conn.NewStream(ctx, desc, method, opts...)
+}
+
+// errConn 连接不可用时的占位实现
+type errConn struct {
+	err error
+}
+
+func (c errConn) Invoke(context.Context, string, any, any, ...grpc.CallOption) error {
+	return c.err
+}
+
+func (c errConn) NewStream(context.Context, *grpc.StreamDesc, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, c.err
+}
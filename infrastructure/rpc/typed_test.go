@@ -0,0 +1,148 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	rpcbalancer "github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/balancer"
+	rpcresolver "github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/resolver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type fakeStub struct {
+	cc grpc.ClientConnInterface
+}
+
+func newFakeStub(cc grpc.ClientConnInterface) *fakeStub { return &fakeStub{cc: cc} }
+
+func stubOptions(t *testing.T, s *fakeStub) clientOptions {
+	t.Helper()
+	conn, ok := s.cc.(*defaultsConn)
+	if !ok {
+		t.Fatalf("unexpected conn %T", s.cc)
+	}
+	return conn.opts
+}
+
+func TestTypedStubOptions(t *testing.T) {
+	rpcbalancer.Register()
+	m := newClientManager(&config.Config{}, config.RpcConfig{
+		Registry: "static",
+		Static:   map[string]string{"echo": "127.0.0.1:1"},
+	})
+	defer m.Close()
+
+	plain, err := typedStub(m, "echo", newFakeStub, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := typedStub(m, "echo", newFakeStub, nil)
+	if plain != again {
+		t.Error("stub without options should be cached")
+	}
+
+	fast, _ := typedStub(m, "echo", newFakeStub, []ClientOption{WithCallTimeout(time.Second), WithCallMetadata("x-tier", "web")})
+	slow, _ := typedStub(m, "echo", newFakeStub, []ClientOption{WithCallTimeout(5 * time.Second)})
+	if got := stubOptions(t, fast); got.timeout != time.Second || len(got.metadata) != 2 {
+		t.Errorf("fast options = %+v", got)
+	}
+	if got := stubOptions(t, slow); got.timeout != 5*time.Second || len(got.metadata) != 0 {
+		t.Errorf("slow options leaked from another caller: %+v", got)
+	}
+	if got := stubOptions(t, plain); got.timeout != 0 || len(got.metadata) != 0 {
+		t.Errorf("cached stub picked up options: %+v", got)
+	}
+	if cached, _ := typedStub(m, "echo", newFakeStub, nil); cached != plain {
+		t.Error("stub with options replaced the cached stub")
+	}
+	if fast.cc.(*defaultsConn).conn != plain.cc.(*defaultsConn).conn {
+		t.Error("stubs should share the connection")
+	}
+}
+
+// echoStub 以 emptypb 调用任意方法的最小 stub
+type echoStub struct {
+	cc grpc.ClientConnInterface
+}
+
+func newEchoStub(cc grpc.ClientConnInterface) *echoStub { return &echoStub{cc: cc} }
+
+func (s *echoStub) Call(ctx context.Context) error {
+	return s.cc.Invoke(ctx, "/demo.Echo/Call", &emptypb.Empty{}, &emptypb.Empty{})
+}
+
+func TestRegisteredClientOptions(t *testing.T) {
+	rpcbalancer.Register()
+	type seen struct {
+		tier     string
+		deadline time.Duration
+	}
+	calls := make(chan seen, 4)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		var got seen
+		if md, ok := metadata.FromIncomingContext(stream.Context()); ok && len(md.Get("x-tier")) > 0 {
+			got.tier = strings.Join(md.Get("x-tier"), ",")
+		}
+		if d, ok := stream.Context().Deadline(); ok {
+			got.deadline = time.Until(d)
+		}
+		calls <- got
+		if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+			return err
+		}
+		return stream.SendMsg(&emptypb.Empty{})
+	}))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	registeredClientsMu.Lock()
+	prevClients, prevOptions := registeredClients, registeredOptions
+	registeredClients, registeredOptions = nil, map[stubKey][]ClientOption{}
+	registeredClientsMu.Unlock()
+	defer func() {
+		registeredClientsMu.Lock()
+		registeredClients, registeredOptions = prevClients, prevOptions
+		registeredClientsMu.Unlock()
+	}()
+
+	rpcresolver.RegisterStatic(map[string]string{"echo": lis.Addr().String()})
+	RegisterClient("echo", newEchoStub, WithCallMetadata("x-tier", "web"), WithCallTimeout(30*time.Second))
+	m := newClientManager(&config.Config{}, config.RpcConfig{
+		Registry: "static",
+		Static:   map[string]string{"echo": lis.Addr().String()},
+	})
+	defer m.Close()
+	if err := m.buildRegisteredClients(); err != nil {
+		t.Fatal(err)
+	}
+
+	stub, err := typedStub(m, "echo", newEchoStub, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stub.Call(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-calls; got.tier != "web" || got.deadline < 25*time.Second {
+		t.Fatalf("registered options not applied: %+v", got)
+	}
+
+	// 调用方选项叠加在登记的选项之上
+	override, _ := typedStub(m, "echo", newEchoStub, []ClientOption{WithCallTimeout(10 * time.Second)})
+	if err := override.Call(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-calls; got.tier != "web" || got.deadline > 10*time.Second {
+		t.Fatalf("override options = %+v", got)
+	}
+}
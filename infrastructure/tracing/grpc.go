@@ -0,0 +1,170 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/interceptor"
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor gRPC Server Span，须位于 ContextExtract 之后以读取 x-trace-id
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if !Enabled() {
+			return handler(ctx, req)
+		}
+		ctx, span := startServerSpan(ctx, info.FullMethod)
+		defer func() { endServerSpan(span, err, recover()) }()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 流式版本，Span 覆盖整个流
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		if !Enabled() {
+			return handler(srv, ss)
+		}
+		ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+		defer func() { endServerSpan(span, err, recover()) }()
+		return handler(srv, interceptor.WrapServerStream(ss, ctx))
+	}
+}
+
+// UnaryClientInterceptor gRPC Client Span，须位于 ContextInject 之前，
+// 无 traceId 的调用（如定时任务）由本拦截器补充后再由 ContextInject 透传 x-trace-id
+func UnaryClientInterceptor(serviceKey string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !Enabled() {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		ctx, span := startClientSpan(ctx, serviceKey, method)
+		defer span.End()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		recordClientError(span, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor 流式版本，仅覆盖建流
+func StreamClientInterceptor(serviceKey string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if !Enabled() {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		ctx, span := startClientSpan(ctx, serviceKey, method)
+		defer span.End()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		recordClientError(span, err)
+		return cs, err
+	}
+}
+
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = propagator.Extract(ctx, metadataCarrier(md))
+	ctx, span, _ := startSpan(ctx, myContext.TryGetTraceId(ctx), spanName(fullMethod),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(rpcAttributes(fullMethod)...),
+	)
+	return ctx, span
+}
+
+// endServerSpan 仅服务端故障类状态码标记为错误；panic 记录后继续抛给 Recovery
+func endServerSpan(span trace.Span, err error, recovered any) {
+	defer span.End()
+	if recovered != nil {
+		span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(grpccodes.Internal)))
+		span.SetStatus(otelcodes.Error, "panic")
+		panic(recovered)
+	}
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	switch code {
+	case grpccodes.Unknown, grpccodes.DeadlineExceeded, grpccodes.Unimplemented,
+		grpccodes.Internal, grpccodes.Unavailable, grpccodes.DataLoss:
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+}
+
+func startClientSpan(ctx context.Context, serviceKey, fullMethod string) (context.Context, trace.Span) {
+	attrs := append(rpcAttributes(fullMethod), semconv.PeerService(serviceKey))
+	ctx, span, _ := startSpan(ctx, myContext.TryGetTraceId(ctx), spanName(fullMethod),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	carrier := metadataCarrier{}
+	propagator.Inject(ctx, carrier)
+	pairs := make([]string, 0, 2*len(carrier))
+	for k, vals := range carrier {
+		for _, v := range vals {
+			pairs = append(pairs, k, v)
+		}
+	}
+	if len(pairs) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, pairs...)
+	}
+	return ctx, span
+}
+
+// recordClientError ClientErrorDecode 已将业务错误还原为 BizError，此时记录业务码
+func recordClientError(span trace.Span, err error) {
+	if err == nil {
+		span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(grpccodes.OK)))
+		return
+	}
+	if s, ok := status.FromError(err); ok {
+		span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(s.Code())))
+	} else {
+		span.SetAttributes(AttrBizCode.String(myException.GetErrorCode(err)))
+	}
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, err.Error())
+}
+
+// spanName /pkg.Service/Method → pkg.Service/Method
+func spanName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.RPCSystemGRPC}
+	name := spanName(fullMethod)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		attrs = append(attrs, semconv.RPCService(name[:i]), semconv.RPCMethod(name[i+1:]))
+	}
+	return attrs
+}
+
+// metadataCarrier gRPC metadata 适配 TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if vals := metadata.MD(c).Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
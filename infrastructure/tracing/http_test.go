@@ -0,0 +1,164 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const upstreamTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+type seen struct {
+	traceId string
+	spanTid string
+}
+
+func newTracingEngine(t *testing.T) (*gin.Engine, *seen) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Plugins.Tracing.Enabled = true
+	cfg.Plugins.Tracing.Exporter = ExporterNone
+	if err := Init(cfg); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	got := &seen{}
+	engine := gin.New()
+	engine.Use(GinMiddleware())
+	engine.GET("/trace", func(c *gin.Context) {
+		got.traceId = myContext.TryGetTraceId(c.Request.Context())
+		got.spanTid = TraceIdOf(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	return engine, got
+}
+
+func serve(engine *gin.Engine, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/trace", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+// responseTraceId 取响应头 traceparent 中的 trace id
+func responseTraceId(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	ctx := propagator.Extract(context.Background(), propagation.HeaderCarrier(w.Header()))
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		t.Fatalf("响应缺少有效 traceparent: %q", w.Header().Get("traceparent"))
+	}
+	return sc.TraceID().String()
+}
+
+func TestGinMiddlewarePropagation(t *testing.T) {
+	engine, got := newTracingEngine(t)
+	const upstreamTid = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	t.Run("traceparent", func(t *testing.T) {
+		w := serve(engine, map[string]string{"traceparent": upstreamTraceparent})
+		if got.spanTid != upstreamTid || got.traceId != upstreamTid {
+			t.Fatalf("span=%s traceId=%s, want %s", got.spanTid, got.traceId, upstreamTid)
+		}
+		if tid := responseTraceId(t, w); tid != upstreamTid {
+			t.Fatalf("response traceparent trace id = %s", tid)
+		}
+	})
+
+	t.Run("traceparent wins over mismatched x-trace-id", func(t *testing.T) {
+		serve(engine, map[string]string{"traceparent": upstreamTraceparent, myContext.HeaderTraceId: "legacy-1"})
+		if got.spanTid != upstreamTid || got.traceId != upstreamTid {
+			t.Fatalf("span=%s traceId=%s, want %s", got.spanTid, got.traceId, upstreamTid)
+		}
+	})
+
+	t.Run("legacy x-trace-id", func(t *testing.T) {
+		const legacy = "order-20240101-0001"
+		w := serve(engine, map[string]string{myContext.HeaderTraceId: legacy})
+		if got.traceId != legacy {
+			t.Fatalf("traceId = %s, want %s", got.traceId, legacy)
+		}
+		want := TraceIDFromString(legacy).String()
+		if got.spanTid != want || responseTraceId(t, w) != want {
+			t.Fatalf("span trace id = %s, want %s", got.spanTid, want)
+		}
+		serve(engine, map[string]string{myContext.HeaderTraceId: legacy})
+		if got.spanTid != want {
+			t.Fatalf("同一 x-trace-id 映射不稳定: %s != %s", got.spanTid, want)
+		}
+	})
+
+	t.Run("uuid x-trace-id", func(t *testing.T) {
+		const legacy = "4BF92F35-77B3-4DA6-A3CE-929D0E0E4736"
+		serve(engine, map[string]string{myContext.HeaderTraceId: legacy})
+		if got.traceId != legacy || got.spanTid != upstreamTid {
+			t.Fatalf("traceId=%s span=%s", got.traceId, got.spanTid)
+		}
+	})
+
+	t.Run("no headers", func(t *testing.T) {
+		w := serve(engine, nil)
+		if got.spanTid == "" || got.traceId != got.spanTid {
+			t.Fatalf("traceId=%s span=%s", got.traceId, got.spanTid)
+		}
+		if responseTraceId(t, w) != got.spanTid {
+			t.Fatalf("response traceparent 与 Span 不一致")
+		}
+	})
+}
+
+func TestTransportPropagation(t *testing.T) {
+	newTracingEngine(t)
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: Transport(nil)}
+
+	call := func(ctx context.Context, extra map[string]string) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		for k, v := range extra {
+			req.Header.Set(k, v)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		resp.Body.Close()
+	}
+	extract := func() trace.SpanContext {
+		return trace.SpanContextFromContext(propagator.Extract(context.Background(), propagation.HeaderCarrier(header)))
+	}
+
+	const legacy = "order-20240101-0002"
+	call(myContext.WithTraceId(context.Background(), legacy), nil)
+	if sc := extract(); !sc.IsValid() || sc.TraceID() != TraceIDFromString(legacy) {
+		t.Fatalf("traceparent = %q", header.Get("traceparent"))
+	}
+	if v := header.Get(myContext.HeaderTraceId); v != legacy {
+		t.Fatalf("x-trace-id = %q, want %s", v, legacy)
+	}
+
+	call(myContext.WithTraceId(context.Background(), legacy), map[string]string{myContext.HeaderTraceId: "caller-set"})
+	if v := header.Get(myContext.HeaderTraceId); v != "caller-set" {
+		t.Fatalf("调用方显式设置的 x-trace-id 被覆盖: %q", v)
+	}
+
+	call(context.Background(), nil)
+	sc := extract()
+	if !sc.IsValid() || header.Get(myContext.HeaderTraceId) != sc.TraceID().String() {
+		t.Fatalf("traceparent=%q x-trace-id=%q", header.Get("traceparent"), header.Get(myContext.HeaderTraceId))
+	}
+}
@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	mrand "math/rand"
+	"strings"
+	"sync"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type presetTraceKey struct{}
+
+// idGenerator 默认随机生成；上游只传了 x-trace-id 时沿用其映射出的 trace id，
+// 使老服务的 traceId 与新链路保持一致。
+type idGenerator struct {
+	mu   sync.Mutex
+	rand *mrand.Rand
+}
+
+var _ sdktrace.IDGenerator = (*idGenerator)(nil)
+
+func newIDGenerator() *idGenerator {
+	var seed int64
+	_ = binary.Read(rand.Reader, binary.LittleEndian, &seed)
+	return &idGenerator{rand: mrand.New(mrand.NewSource(seed))}
+}
+
+func (g *idGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	tid, ok := ctx.Value(presetTraceKey{}).(trace.TraceID)
+	if !ok || !tid.IsValid() {
+		for !tid.IsValid() {
+			_, _ = g.rand.Read(tid[:])
+		}
+	}
+	return tid, g.newSpanID()
+}
+
+func (g *idGenerator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.newSpanID()
+}
+
+func (g *idGenerator) newSpanID() trace.SpanID {
+	var sid trace.SpanID
+	for !sid.IsValid() {
+		_, _ = g.rand.Read(sid[:])
+	}
+	return sid
+}
+
+// TraceIDFromString 将 x-trace-id 映射为 OTel trace id：32 位十六进制（含 UUID 去掉连字符）直接解析，
+// 其他格式取 SHA-256 前 16 字节，同一 x-trace-id 在各服务映射结果一致。
+func TraceIDFromString(traceId string) trace.TraceID {
+	hexId := strings.ToLower(strings.ReplaceAll(traceId, "-", ""))
+	if len(hexId) == 32 {
+		if tid, err := trace.TraceIDFromHex(hexId); err == nil {
+			return tid
+		}
+	}
+	var tid trace.TraceID
+	sum := sha256.Sum256([]byte(traceId))
+	copy(tid[:], sum[:len(tid)])
+	return tid
+}
+
+// startSpan 创建 Span 并确定 myContext 中的 traceId。
+// ctx 中已有上游 Span（traceparent）时作为其子 Span，否则以 legacyTraceId 映射出的 trace id 新建根 Span；
+// 返回的 traceId 在 legacyTraceId 与 Span 属于同一链路时沿用 legacyTraceId，否则为 OTel trace id。
+func startSpan(ctx context.Context, legacyTraceId, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span, string) {
+	if legacyTraceId != "" && !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = context.WithValue(ctx, presetTraceKey{}, TraceIDFromString(legacyTraceId))
+	}
+	ctx, span := tracer.Start(ctx, name, opts...)
+	tid := span.SpanContext().TraceID()
+	traceId := legacyTraceId
+	if traceId == "" || TraceIDFromString(traceId) != tid {
+		traceId = tid.String()
+	}
+	return myContext.WithTraceId(ctx, traceId), span, traceId
+}
+
+// TraceIdOf 返回 ctx 中当前 Span 的 trace id（32 位十六进制），无 Span 时为空
+func TraceIdOf(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// logFields 日志提取器：输出当前 Span 的 spanId，便于从日志跳转到对应 Span
+func logFields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zap.Field{zap.String("spanId", sc.SpanID().String())}
+}
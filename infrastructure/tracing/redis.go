@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type redisSpanKey struct{}
+
+// redisHook 每条命令 / 每个 pipeline 一个 Client Span，只记录命令名，不记录参数
+type redisHook struct{}
+
+var _ redis.Hook = redisHook{}
+
+// InstrumentRedis 为 client 添加命令 Span；未启用时不做任何事
+func InstrumentRedis(client *redis.Client) {
+	if !Enabled() || client == nil {
+		return
+	}
+	client.AddHook(redisHook{})
+}
+
+func (redisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return startRedisSpan(ctx, "redis."+cmd.Name(), semconv.DBOperationName(cmd.Name())), nil
+}
+
+func (redisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endRedisSpan(ctx, cmd.Err())
+	return nil
+}
+
+func (redisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return startRedisSpan(ctx, "redis.pipeline",
+		semconv.DBOperationName("pipeline"),
+		attribute.Int("db.redis.pipeline_length", len(cmds)),
+	), nil
+}
+
+func (redisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			err = cmdErr
+			break
+		}
+	}
+	endRedisSpan(ctx, err)
+	return nil
+}
+
+func startRedisSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) context.Context {
+	if !Enabled() {
+		return ctx
+	}
+	ctx, span := tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, semconv.DBSystemRedis)...),
+	)
+	return context.WithValue(ctx, redisSpanKey{}, span)
+}
+
+// endRedisSpan 只结束本 hook 创建的 Span；redis.Nil（键不存在）不视为错误
+func endRedisSpan(ctx context.Context, err error) {
+	span, ok := ctx.Value(redisSpanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+}
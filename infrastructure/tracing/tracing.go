@@ -0,0 +1,236 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterFile   = "file"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+
+	instrumentationName = "github.com/muyi-zcy/tech-muyi-base-go"
+)
+
+var (
+	enabled atomic.Bool
+
+	mu           sync.Mutex
+	provider     *sdktrace.TracerProvider
+	output       io.Closer
+	excludePaths map[string]struct{}
+
+	tracer     trace.Tracer = otel.Tracer(instrumentationName)
+	propagator              = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+)
+
+// Init 按 plugins.tracing 启用链路追踪（enabled=false 时各埋点为空操作）；重复调用仅首次生效。
+// 启用后同时设置 otel 全局 TracerProvider 与 Propagator，业务自行创建的 Span 会挂到同一条链路上。
+func Init(appCfg *config.Config) error {
+	if appCfg == nil || !appCfg.Plugins.Tracing.Enabled {
+		return nil
+	}
+	cfg := appCfg.Plugins.Tracing
+	mu.Lock()
+	defer mu.Unlock()
+	if enabled.Load() {
+		return nil
+	}
+
+	ratio := 1.0
+	if cfg.SampleRatio != nil {
+		if *cfg.SampleRatio < 0 || *cfg.SampleRatio > 1 {
+			return errors.Errorf("plugins.tracing.sampleRatio 须在 [0, 1] 内: %v", *cfg.SampleRatio)
+		}
+		ratio = *cfg.SampleRatio
+	}
+
+	exporter, closer, err := newExporter(cfg)
+	if err != nil {
+		return err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName(appCfg))))
+	if err != nil {
+		return errors.Wrap(err, "构建链路追踪 Resource 失败")
+	}
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithIDGenerator(newIDGenerator()),
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	provider = sdktrace.NewTracerProvider(opts...)
+	output = closer
+	excludePaths = make(map[string]struct{}, len(cfg.ExcludePaths))
+	for _, path := range cfg.ExcludePaths {
+		excludePaths[path] = struct{}{}
+	}
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	tracer = provider.Tracer(instrumentationName)
+	enabled.Store(true)
+	myLogger.RegisterFieldsExtractor("tracing", logFields)
+	myLogger.Info("链路追踪已启用",
+		zap.String("exporter", exporterName(cfg)),
+		zap.Float64("sampleRatio", ratio),
+	)
+	return nil
+}
+
+// Enabled 是否已启用链路追踪
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Tracer 框架使用的 Tracer，未启用时为 otel 全局的空实现
+func Tracer() trace.Tracer {
+	if !Enabled() {
+		return otel.Tracer(instrumentationName)
+	}
+	return tracer
+}
+
+// Propagator W3C traceparent / tracestate 与 baggage
+func Propagator() propagation.TextMapPropagator {
+	return propagator
+}
+
+// Shutdown 上报剩余 Span 并关闭导出器（App.Shutdown 调用）
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if provider == nil {
+		return nil
+	}
+	err := provider.Shutdown(ctx)
+	if output != nil {
+		if closeErr := output.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		output = nil
+	}
+	provider = nil
+	return err
+}
+
+func newExporter(cfg config.TracingConfig) (sdktrace.SpanExporter, io.Closer, error) {
+	switch exporterName(cfg) {
+	case ExporterOTLP:
+		exporter, err := newOTLPExporter(cfg.OTLP)
+		return exporter, nil, err
+	case ExporterFile:
+		path := cfg.File.Path
+		if path == "" {
+			return nil, nil, errors.New("plugins.tracing.file.path 不能为空")
+		}
+		if dir := filepath.Dir(path); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return nil, nil, errors.Wrapf(err, "创建链路追踪输出目录失败: %s", dir)
+			}
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "打开链路追踪输出文件失败: %s", path)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, errors.Wrap(err, "创建 file 导出器失败")
+		}
+		return exporter, f, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, nil, errors.Wrap(err, "创建 stdout 导出器失败")
+	case ExporterNone:
+		return nil, nil, nil
+	default:
+		return nil, nil, errors.Errorf("未知的 plugins.tracing.exporter: %s", cfg.Exporter)
+	}
+}
+
+func newOTLPExporter(cfg config.OTLPConfig) (sdktrace.SpanExporter, error) {
+	timeout := time.Duration(cfg.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	// 创建导出器不会阻塞等待连接，Collector 暂不可用时 Span 在后台重试上报
+	ctx := context.Background()
+	switch strings.ToLower(cfg.Protocol) {
+	case "", "grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithTimeout(timeout)}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(cfg.Headers))
+		}
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		return exporter, errors.Wrap(err, "创建 OTLP gRPC 导出器失败")
+	case "http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithTimeout(timeout)}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		return exporter, errors.Wrap(err, "创建 OTLP HTTP 导出器失败")
+	default:
+		return nil, errors.Errorf("未知的 plugins.tracing.otlp.protocol: %s", cfg.Protocol)
+	}
+}
+
+func exporterName(cfg config.TracingConfig) string {
+	if cfg.Exporter == "" {
+		return ExporterOTLP
+	}
+	return strings.ToLower(cfg.Exporter)
+}
+
+func serviceName(appCfg *config.Config) string {
+	if name := appCfg.Plugins.Tracing.ServiceName; name != "" {
+		return name
+	}
+	if name := appCfg.Plugins.Nacos.ServiceName; name != "" {
+		return name
+	}
+	return appCfg.AppName
+}
+
+// excluded 仅在 Enabled 后调用，excludePaths 初始化后只读
+func excluded(route string) bool {
+	_, ok := excludePaths[route]
+	return ok
+}
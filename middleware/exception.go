@@ -18,7 +18,7 @@ func NotFoundHandler() gin.HandlerFunc {
 		)
 
 		err := myException.NewBizError("platform.route.not_found", nil)
-		c.JSON(200, buildErrorResult(c, err))
+		myResult.JSON(c, buildErrorResult(c, err))
 		c.Abort()
 	}
 }
@@ -33,7 +33,7 @@ func MethodNotAllowedHandler() gin.HandlerFunc {
 		)
 
 		err := myException.NewBizError("platform.method.not_allowed", nil)
-		c.JSON(200, buildErrorResult(c, err))
+		myResult.JSON(c, buildErrorResult(c, err))
 		c.Abort()
 	}
 }
@@ -62,7 +62,7 @@ func ExceptionHandler() gin.HandlerFunc {
 					zap.String("message", result.Message),
 				)
 
-				c.JSON(200, result)
+				myResult.JSON(c, result)
 				c.Abort()
 				return
 			}
@@ -82,7 +82,7 @@ func ExceptionHandler() gin.HandlerFunc {
 				}()),
 			)
 
-			c.JSON(200, buildErrorResult(c, lastErr))
+			myResult.JSON(c, buildErrorResult(c, lastErr))
 			c.Abort()
 		}
 	}
@@ -2,8 +2,10 @@ package middleware
 
 import (
 	"bytes"
+	"github.com/muyi-zcy/tech-muyi-base-go/myAuth"
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
 	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
-	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,12 +17,27 @@ const (
 	maxResponseBodySize = 10 * 1024
 )
 
-// 敏感路径后缀列表，这些路径的响应体不记录日志（兼容 /api/{appCode} 前缀）
-var skipBodyLogSuffixes = []string{
-	"/v1/user/login",
-	"/v1/user/register",
-	"/v1/file/upload",
-	"/v1/file/download",
+// defaultSkipBodyPaths 未配置 log.redact.skip_body_paths 时不记录响应体的路由（兼容 /api/{appCode} 前缀）
+var defaultSkipBodyPaths = []string{
+	"/**/v1/user/login",
+	"/**/v1/user/register",
+	"/**/v1/file/upload",
+	"/**/v1/file/download",
+}
+
+var skipBodyMatcher atomic.Pointer[myAuth.WhiteListMatcher]
+
+func init() {
+	SetSkipBodyPaths(nil)
+}
+
+// SetSkipBodyPaths 设置不记录响应体的路由（WhiteListMatcher 语法，支持 * / **），为空时恢复默认列表；
+// 其余路由的响应体经 myLogger.RedactJSON 脱敏后记录
+func SetSkipBodyPaths(paths []string) {
+	if len(paths) == 0 {
+		paths = defaultSkipBodyPaths
+	}
+	skipBodyMatcher.Store(myAuth.NewWhiteListMatcher(paths))
 }
 
 type bodyLogWriter struct {
@@ -33,6 +50,24 @@ func (w bodyLogWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// bindLogFields 将请求级字段一次性绑定到 request context，业务代码经 *Ctx 方法输出的日志自动携带；
+// ssoId / tenantId 由鉴权中间件稍后写入，由日志提取器在输出时读取
+func bindLogFields(c *gin.Context) {
+	fields := []zap.Field{
+		zap.String("httpMethod", c.Request.Method),
+		zap.String("httpPath", c.Request.URL.Path),
+		zap.String("remoteIp", c.ClientIP()),
+		zap.String(myContext.LocaleKey, myContext.GetLocaleFromGinCtx(c)),
+	}
+	if route := c.FullPath(); route != "" {
+		fields = append(fields, zap.String("route", route))
+	}
+	if source := c.GetHeader(myContext.HeaderSourceService); source != "" {
+		fields = append(fields, zap.String(myContext.SourceService, source))
+	}
+	myContext.AttachContext(c, myLogger.With(c.Request.Context(), fields...))
+}
+
 // Logger 日志中间件 - 统一记录请求开始和结束日志
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -41,52 +76,36 @@ func Logger() gin.HandlerFunc {
 		blw := &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
 		c.Writer = blw
 		// 获取请求信息
-		method := c.Request.Method
 		path := c.Request.URL.Path
-		remoteIP := c.ClientIP()
-		userAgent := c.Request.UserAgent()
+		bindLogFields(c)
 
-		// 记录请求开始日志（自动获取traceId）
+		// 记录请求开始日志（自动携带 traceId 与请求级字段）
 		myLogger.InfoCtx(c.Request.Context(), "Request started",
-			zap.String("httpMethod", method),
-			zap.String("httpPath", path),
-			zap.String("remoteIp", remoteIP),
-			zap.String("userAgent", userAgent),
+			zap.String("userAgent", c.Request.UserAgent()),
 		)
 
 		// 处理请求
 		c.Next()
 
-		// 记录请求结束日志（自动获取traceId）
+		// 记录请求结束日志
 		end := time.Now()
 		duration := end.Sub(start)
 		statusCode := c.Writer.Status()
 
-		// 判断是否需要记录响应体
+		// 判断是否需要记录响应体，记录时先脱敏
 		var responseBody string
-		shouldSkipBody := false
-		for _, suffix := range skipBodyLogSuffixes {
-			if strings.HasSuffix(path, suffix) {
-				shouldSkipBody = true
-				break
-			}
-		}
-
-		if shouldSkipBody {
+		if skipBodyMatcher.Load().Match(path) {
 			responseBody = "[REDACTED]"
 		} else if blw.body.Len() > maxResponseBodySize {
-			// 响应体过大，截断
-			responseBody = blw.body.String()[:maxResponseBodySize] + "...[truncated]"
+			// 响应体过大，截断（截断后非合法 JSON，按文本脱敏）
+			responseBody = myLogger.RedactText(blw.body.String()[:maxResponseBodySize]) + "...[truncated]"
 		} else {
-			responseBody = blw.body.String()
+			responseBody = myLogger.RedactJSON(blw.body.Bytes())
 		}
 
 		// 合并请求开始和结束信息
 		myLogger.InfoCtx(c.Request.Context(), "Request finished",
-			zap.String("httpMethod", method),
-			zap.String("httpPath", path),
-			zap.String("remoteIp", remoteIP),
-			zap.String("userAgent", userAgent),
+			zap.String("userAgent", c.Request.UserAgent()),
 			zap.Int("httpStatus", statusCode),
 			zap.String("result", responseBody),
 			zap.Int64("durationMs", duration.Milliseconds()),
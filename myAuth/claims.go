@@ -8,18 +8,26 @@ type Claims struct {
 	Username    string
 	DisplayName string
 	ExpireAt    time.Time
+	IssuedAt    time.Time
 	JTI         string
+
+	// Extras 外部 token 中未映射到标准字段的 claims，解析后写入 Session extras。
+	Extras map[string]any
 }
 
 func claimsToSession(claims *Claims) *Session {
 	if claims == nil {
 		return nil
 	}
-	return &Session{
+	sess := &Session{
 		UserID:      claims.UserID,
 		Username:    claims.Username,
 		DisplayName: claims.DisplayName,
 		ExpireAt:    claims.ExpireAt,
 		JTI:         claims.JTI,
 	}
+	for k, v := range claims.Extras {
+		sess.SetExtra(k, v)
+	}
+	return sess
 }
@@ -13,10 +13,34 @@ import (
 const (
 	ProviderEncryptedJWT = "encrypted_jwt"
 	ProviderRedisOpaque  = "redis_opaque"
+	ProviderSignedJWT    = "signed_jwt"
+	ProviderOIDC         = "oidc"
 
 	defaultTokenExpireSeconds = 28800
 	defaultSessionKeyPrefix   = "auth:session:"
 	defaultRevokeKeyPrefix    = "auth:revoked:"
+	defaultUserIndexPrefix    = "auth:user_sessions:"
+	defaultValidAfterPrefix   = "auth:valid_after:"
+
+	defaultRefreshExpireSeconds  = 604800
+	defaultRefreshKeyPrefix      = "auth:refresh:"
+	defaultRefreshFamilyPrefix   = "auth:refresh_family:"
+	defaultSlidingIntervalSecond = 60
+
+	defaultOIDCClaimUserID      = "sub"
+	defaultOIDCClaimUsername    = "preferred_username"
+	defaultOIDCClaimDisplayName = "name"
+	defaultOIDCExtrasPrefix     = "oidc."
+
+	defaultAPIKeyHeader         = "x-api-key"
+	defaultAPIKeyPrefix         = "auth:apikey:"
+	defaultHMACNoncePrefix      = "auth:hmac_nonce:"
+	defaultSignatureSkewSeconds = 300
+	defaultSignatureMaxBody     = 10 << 20
+
+	defaultRBACCacheSeconds        = 300
+	defaultRBACRolesExtraKey       = "roles"
+	defaultRBACPermissionsExtraKey = "permissions"
 )
 
 // Config myAuth 配置。
@@ -24,9 +48,16 @@ type Config struct {
 	Provider           string   `mapstructure:"provider"`
 	TokenExpireSeconds int      `mapstructure:"tokenExpireSeconds"`
 	WhiteList          []string `mapstructure:"whiteList"`
+	// GRPCWhiteList gRPC 免鉴权方法（/pkg.Service/Method，支持 *），健康检查与反射默认包含。
+	GRPCWhiteList []string `mapstructure:"grpcWhiteList"`
 
-	JWT     JWTConfig          `mapstructure:"jwt"`
-	Session SessionStoreConfig `mapstructure:"session"`
+	JWT       JWTConfig          `mapstructure:"jwt"`
+	SignedJWT SignedJWTConfig    `mapstructure:"signedJwt"`
+	OIDC      OIDCConfig         `mapstructure:"oidc"`
+	RBAC      RBACConfig         `mapstructure:"rbac"`
+	Service   ServiceAuthConfig  `mapstructure:"service"`
+	Session   SessionStoreConfig `mapstructure:"session"`
+	Refresh   RefreshConfig      `mapstructure:"refresh"`
 }
 
 // JWTConfig 加密 JWT（JWE）配置。
@@ -37,10 +68,115 @@ type JWTConfig struct {
 	Audience string `mapstructure:"audience"`
 }
 
+// SignedJWTConfig 非对称签名 JWT（JWS）配置；iss / aud 沿用 auth.jwt。
+// 未配置任何私钥时为仅验签模式（下游服务只需 JWKS）。
+type SignedJWTConfig struct {
+	// Algorithm 默认签名算法：RS256 / ES256 / EdDSA；为空时按密钥类型推断。
+	Algorithm string `mapstructure:"algorithm"`
+	// ActiveKid 签发使用的 kid；为空时取第一把含私钥的密钥。
+	ActiveKid string             `mapstructure:"activeKid"`
+	Keys      []SigningKeyConfig `mapstructure:"keys"`
+
+	JWKSFile           string `mapstructure:"jwksFile"`
+	JWKSURL            string `mapstructure:"jwksUrl"`
+	JWKSRefreshSeconds int    `mapstructure:"jwksRefreshSeconds"`
+	// PublishJWKS 为 true 时 RegisterJWKSRoute 暴露 /.well-known/jwks.json。
+	PublishJWKS bool `mapstructure:"publishJwks"`
+}
+
+// SigningKeyConfig 单把签名密钥；仅给公钥时只参与验签（轮换下线中的旧密钥）。
+type SigningKeyConfig struct {
+	Kid            string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"`
+	PrivateKey     string `mapstructure:"privateKey"`
+	PrivateKeyFile string `mapstructure:"privateKeyFile"`
+	PublicKey      string `mapstructure:"publicKey"`
+	PublicKeyFile  string `mapstructure:"publicKeyFile"`
+}
+
+// OIDCConfig 外部身份提供方（OIDC/OAuth2 资源服务器）配置，仅验签不签发。
+type OIDCConfig struct {
+	// Issuer IdP 的 iss，必须与 discovery 文档及 token 中的 iss 一致。
+	Issuer string `mapstructure:"issuer"`
+	// DiscoveryURL 为空时使用 Issuer + "/.well-known/openid-configuration"。
+	DiscoveryURL string `mapstructure:"discoveryUrl"`
+	// Audience 期望的 aud（通常为本服务在 IdP 的 client_id）；为空不校验。
+	Audience           string `mapstructure:"audience"`
+	JWKSRefreshSeconds int    `mapstructure:"jwksRefreshSeconds"`
+	// ClockSkewSeconds 校验 exp / nbf 时允许的时钟偏差。
+	ClockSkewSeconds int `mapstructure:"clockSkewSeconds"`
+
+	// ClaimUserID 映射到 Claims.UserID 的 claim，值须为整数或数字字符串。
+	ClaimUserID      string `mapstructure:"claimUserId"`
+	ClaimUsername    string `mapstructure:"claimUsername"`
+	ClaimDisplayName string `mapstructure:"claimDisplayName"`
+	// ExtrasPrefix 其余 claims 写入 Session extras 时的 key 前缀。
+	ExtrasPrefix string `mapstructure:"extrasPrefix"`
+}
+
+// RBACConfig 角色权限配置；Policies 为声明式路由 / gRPC 方法权限表。
+type RBACConfig struct {
+	// CacheSeconds 按会话（jti）缓存加载结果的时长。
+	CacheSeconds int `mapstructure:"cacheSeconds"`
+	// Roles 角色 → 权限，供默认 loader 展开角色。
+	Roles map[string][]string `mapstructure:"roles"`
+	// RolesExtraKey / PermissionsExtraKey 默认 loader 从 Session extras 读取的 key。
+	RolesExtraKey       string         `mapstructure:"rolesExtraKey"`
+	PermissionsExtraKey string         `mapstructure:"permissionsExtraKey"`
+	Policies            []PolicyConfig `mapstructure:"policies"`
+}
+
+// PolicyConfig 单条权限策略；按声明顺序匹配，首条命中生效。
+type PolicyConfig struct {
+	// Pattern 路由或 gRPC 全方法名（/pkg.Service/Method），语法同白名单。
+	Pattern string `mapstructure:"pattern"`
+	// Methods HTTP 方法，为空匹配全部；gRPC 忽略。
+	Methods     []string `mapstructure:"methods"`
+	Permissions []string `mapstructure:"permissions"`
+	// Any 为 true 时满足任一权限即可，默认需全部满足。
+	Any bool `mapstructure:"any"`
+}
+
+// ServiceAuthConfig 服务间调用凭证（api key / HMAC 签名）配置。
+type ServiceAuthConfig struct {
+	// APIKeyHeader 携带 api key 的请求头；也接受 Authorization: ApiKey <key>。
+	APIKeyHeader string `mapstructure:"apiKeyHeader"`
+	// APIKeyPrefix Redis api key 存储前缀（key 为 SHA-256 摘要）。
+	APIKeyPrefix string `mapstructure:"apiKeyPrefix"`
+	// NoncePrefix HMAC 防重放 nonce 缓存前缀。
+	NoncePrefix string `mapstructure:"noncePrefix"`
+	// SignatureSkewSeconds 签名时间戳允许的偏差，nonce 缓存 2 倍该时长。
+	SignatureSkewSeconds int `mapstructure:"signatureSkewSeconds"`
+	// MaxBodyBytes 参与签名的请求体上限。
+	MaxBodyBytes int64 `mapstructure:"maxBodyBytes"`
+	// HMACKeys 静态 HMAC 密钥，WithHMAC(nil) 时使用。
+	HMACKeys []HMACKey `mapstructure:"hmacKeys"`
+}
+
 // SessionStoreConfig Redis 会话存储配置（redis_opaque 及吊销）。
 type SessionStoreConfig struct {
 	KeyPrefix    string `mapstructure:"keyPrefix"`
 	RevokePrefix string `mapstructure:"revokePrefix"`
+
+	// Sliding 为 true 时 redis_opaque 会话在访问时自动续期（至 now + tokenExpireSeconds）。
+	Sliding bool `mapstructure:"sliding"`
+	// SlidingIntervalSeconds 两次续期的最小间隔，避免每个请求都写 Redis。
+	SlidingIntervalSeconds int `mapstructure:"slidingIntervalSeconds"`
+
+	// UserIndexPrefix 按用户维护的会话索引前缀（ListSessions / DestroyAllForUser）。
+	UserIndexPrefix string `mapstructure:"userIndexPrefix"`
+	// ValidAfterPrefix 用户级「此时间前签发的 token 全部失效」标记前缀。
+	ValidAfterPrefix string `mapstructure:"validAfterPrefix"`
+	// MaxConcurrent 单用户最大并发会话数，超出时淘汰最早的会话；0 表示不限制。
+	MaxConcurrent int `mapstructure:"maxConcurrent"`
+}
+
+// RefreshConfig 刷新令牌配置；启用后 Create 同时签发 refresh token。
+type RefreshConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	ExpireSeconds int    `mapstructure:"expireSeconds"`
+	KeyPrefix     string `mapstructure:"keyPrefix"`
+	FamilyPrefix  string `mapstructure:"familyPrefix"`
 }
 
 var (
@@ -89,6 +225,13 @@ func MustInitFromViper() {
 	}
 }
 
+// Initialized 是否已调用 Init。
+func Initialized() bool {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	return initialized
+}
+
 // CurrentConfig 返回当前配置副本。
 func CurrentConfig() Config {
 	cfgMu.RLock()
@@ -109,6 +252,60 @@ func (c Config) withDefaults() Config {
 	if c.Session.RevokePrefix == "" {
 		c.Session.RevokePrefix = defaultRevokeKeyPrefix
 	}
+	if c.Session.UserIndexPrefix == "" {
+		c.Session.UserIndexPrefix = defaultUserIndexPrefix
+	}
+	if c.Session.ValidAfterPrefix == "" {
+		c.Session.ValidAfterPrefix = defaultValidAfterPrefix
+	}
+	if c.Session.SlidingIntervalSeconds <= 0 {
+		c.Session.SlidingIntervalSeconds = defaultSlidingIntervalSecond
+	}
+	if c.Refresh.ExpireSeconds <= 0 {
+		c.Refresh.ExpireSeconds = defaultRefreshExpireSeconds
+	}
+	if c.Refresh.KeyPrefix == "" {
+		c.Refresh.KeyPrefix = defaultRefreshKeyPrefix
+	}
+	if c.Refresh.FamilyPrefix == "" {
+		c.Refresh.FamilyPrefix = defaultRefreshFamilyPrefix
+	}
+	if c.OIDC.ClaimUserID == "" {
+		c.OIDC.ClaimUserID = defaultOIDCClaimUserID
+	}
+	if c.OIDC.ClaimUsername == "" {
+		c.OIDC.ClaimUsername = defaultOIDCClaimUsername
+	}
+	if c.OIDC.ClaimDisplayName == "" {
+		c.OIDC.ClaimDisplayName = defaultOIDCClaimDisplayName
+	}
+	if c.OIDC.ExtrasPrefix == "" {
+		c.OIDC.ExtrasPrefix = defaultOIDCExtrasPrefix
+	}
+	if c.Service.APIKeyHeader == "" {
+		c.Service.APIKeyHeader = defaultAPIKeyHeader
+	}
+	if c.Service.APIKeyPrefix == "" {
+		c.Service.APIKeyPrefix = defaultAPIKeyPrefix
+	}
+	if c.Service.NoncePrefix == "" {
+		c.Service.NoncePrefix = defaultHMACNoncePrefix
+	}
+	if c.Service.SignatureSkewSeconds <= 0 {
+		c.Service.SignatureSkewSeconds = defaultSignatureSkewSeconds
+	}
+	if c.Service.MaxBodyBytes <= 0 {
+		c.Service.MaxBodyBytes = defaultSignatureMaxBody
+	}
+	if c.RBAC.CacheSeconds <= 0 {
+		c.RBAC.CacheSeconds = defaultRBACCacheSeconds
+	}
+	if c.RBAC.RolesExtraKey == "" {
+		c.RBAC.RolesExtraKey = defaultRBACRolesExtraKey
+	}
+	if c.RBAC.PermissionsExtraKey == "" {
+		c.RBAC.PermissionsExtraKey = defaultRBACPermissionsExtraKey
+	}
 	if c.JWT.Issuer == "" {
 		c.JWT.Issuer = "my-xi"
 	}
@@ -123,6 +320,14 @@ func (c Config) validate() error {
 		}
 	case ProviderRedisOpaque:
 		// Redis 在运行时由 infrastructure 提供。
+	case ProviderSignedJWT:
+		if len(c.SignedJWT.Keys) == 0 && c.SignedJWT.JWKSFile == "" && c.SignedJWT.JWKSURL == "" {
+			return fmt.Errorf("myAuth: auth.signedJwt requires keys, jwksFile or jwksUrl")
+		}
+	case ProviderOIDC:
+		if c.OIDC.Issuer == "" {
+			return fmt.Errorf("myAuth: auth.oidc.issuer is required")
+		}
 	default:
 		providerMu.RLock()
 		_, ok := providers[c.Provider]
@@ -138,6 +343,23 @@ func (c Config) tokenTTL() time.Duration {
 	return time.Duration(c.TokenExpireSeconds) * time.Second
 }
 
+func (c Config) refreshTTL() time.Duration {
+	return time.Duration(c.Refresh.ExpireSeconds) * time.Second
+}
+
+// validAfterTTL 用户级 valid-after 标记需覆盖该时刻前签发的所有令牌的剩余寿命。
+func (c Config) validAfterTTL() time.Duration {
+	ttl := c.tokenTTL()
+	if c.Refresh.Enabled && c.refreshTTL() > ttl {
+		ttl = c.refreshTTL()
+	}
+	return ttl
+}
+
+func (c Config) slidingInterval() time.Duration {
+	return time.Duration(c.Session.SlidingIntervalSeconds) * time.Second
+}
+
 func (c JWTConfig) decodeKey() ([]byte, error) {
 	raw := strings.TrimSpace(c.Key)
 	if raw == "" {
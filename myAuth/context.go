@@ -2,6 +2,7 @@ package myAuth
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -21,8 +22,9 @@ func bindAuthContext(c *gin.Context, sess *Session) {
 	}
 
 	myContext.BindScalars(c, myContext.ScalarBinding{
-		SsoId: strconv.FormatInt(sess.UserID, 10),
-		Token: sess.Token,
+		SsoId:    sessionSsoId(sess),
+		Token:    sess.Token,
+		TenantId: sessionTenantId(sess),
 	})
 	c.Set(sessionGinKey, sess)
 
@@ -34,11 +36,38 @@ func bindAuthContextGRPC(ctx context.Context, sess *Session) context.Context {
 	if ctx == nil || sess == nil {
 		return ctx
 	}
-	ctx = myContext.WithSsoId(ctx, strconv.FormatInt(sess.UserID, 10))
+	if ssoId := sessionSsoId(sess); ssoId != "" {
+		ctx = myContext.WithSsoId(ctx, ssoId)
+	}
 	ctx = myContext.WithToken(ctx, sess.Token)
+	ctx = myContext.WithTenantId(ctx, sessionTenantId(sess))
 	return context.WithValue(ctx, sessionKey, sess)
 }
 
+// sessionSsoId 服务主体没有用户身份，不写 ssoId。
+func sessionSsoId(sess *Session) string {
+	if sess.IsService() {
+		return ""
+	}
+	return strconv.FormatInt(sess.UserID, 10)
+}
+
+// sessionTenantId 读取 extras 中的租户 ID，数字型 claim 按十进制输出。
+func sessionTenantId(sess *Session) string {
+	v, ok := sess.Extra(ExtraTenantId)
+	if !ok || v == nil {
+		return ""
+	}
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
 // GetSession 从 gin 上下文读取平台 Session。
 func GetSession(c *gin.Context) (*Session, bool) {
 	if c == nil {
@@ -2,15 +2,34 @@ package myAuth
 
 import (
 	"context"
+	"time"
 
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/metrics"
 	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/rpc/interceptor"
 	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// defaultGRPCWhiteList 健康检查与反射默认免鉴权。
+var defaultGRPCWhiteList = []string{
+	"/grpc.health.v1.Health/*",
+	"/grpc.reflection.v1.ServerReflection/*",
+	"/grpc.reflection.v1alpha.ServerReflection/*",
+}
+
 type grpcAuthOptions struct {
-	providerName  string
-	enricherNames []string
+	required         bool
+	providerName     string
+	enricherNames    []string
+	whiteList        []string
+	tokenMissingCode string
+	tokenInvalidCode string
+	peerScopes       map[string][]string
 }
 
 // GRPCOption gRPC 鉴权拦截器配置。
@@ -28,36 +47,197 @@ func WithGRPCEnrichers(names ...string) GRPCOption {
 	}
 }
 
-// GRPCAuthInterceptor 从 metadata token 校验并写入 Session/ssoId；不信任裸 x-sso-id。
-func GRPCAuthInterceptor(opts ...GRPCOption) grpc.UnaryServerInterceptor {
-	options := grpcAuthOptions{}
+// WithGRPCWhiteList 免鉴权方法，支持 /pkg.Service/* 通配（叠加 auth.grpcWhiteList）。
+func WithGRPCWhiteList(methods ...string) GRPCOption {
+	return func(o *grpcAuthOptions) {
+		o.whiteList = append(o.whiteList, methods...)
+	}
+}
+
+func WithGRPCTokenMissingCode(code string) GRPCOption {
+	return func(o *grpcAuthOptions) {
+		o.tokenMissingCode = code
+	}
+}
+
+func WithGRPCTokenInvalidCode(code string) GRPCOption {
+	return func(o *grpcAuthOptions) {
+		o.tokenInvalidCode = code
+	}
+}
+
+// WithGRPCPeerIdentities 未携带 token 的 mTLS 调用按对端 SPIFFE ID / CN 映射为服务主体，value 为授予的权限码。
+func WithGRPCPeerIdentities(scopes map[string][]string) GRPCOption {
+	return func(o *grpcAuthOptions) {
+		if o.peerScopes == nil {
+			o.peerScopes = make(map[string][]string, len(scopes))
+		}
+		for name, codes := range scopes {
+			o.peerScopes[name] = codes
+		}
+	}
+}
+
+func buildGRPCOptions(required bool, opts []GRPCOption) grpcAuthOptions {
+	options := grpcAuthOptions{
+		required:         required,
+		tokenMissingCode: CodeTokenMissing,
+		tokenInvalidCode: CodeTokenInvalid,
+	}
 	for _, opt := range opts {
 		opt(&options)
 	}
+	return options
+}
+
+// GRPCAuthInterceptor 等同 GRPCOptional，保留兼容。
+func GRPCAuthInterceptor(opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	return GRPCOptional(opts...)
+}
+
+// GRPCRequired 必须携带有效 token，否则返回 codes.Unauthenticated。
+func GRPCRequired(opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	return grpcUnaryAuth(buildGRPCOptions(true, opts))
+}
+
+// GRPCOptional 有 token 则校验并加载 Session（无效 token 拒绝），无 token 放行。
+func GRPCOptional(opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	return grpcUnaryAuth(buildGRPCOptions(false, opts))
+}
+
+// GRPCRequiredStream GRPCRequired 的流式版本。
+func GRPCRequiredStream(opts ...GRPCOption) grpc.StreamServerInterceptor {
+	return grpcStreamAuth(buildGRPCOptions(true, opts))
+}
+
+// GRPCOptionalStream GRPCOptional 的流式版本。
+func GRPCOptionalStream(opts ...GRPCOption) grpc.StreamServerInterceptor {
+	return grpcStreamAuth(buildGRPCOptions(false, opts))
+}
+
+func grpcUnaryAuth(options grpcAuthOptions) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		token := myContext.TryGetToken(ctx)
-		if token == "" {
-			return handler(ctx, req)
+		ctx, err := authenticateGRPC(ctx, info.FullMethod, options)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func grpcStreamAuth(options grpcAuthOptions) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateGRPC(ss.Context(), info.FullMethod, options)
+		if err != nil {
+			return err
 		}
+		return handler(srv, interceptor.WrapServerStream(ss, ctx))
+	}
+}
+
+// authenticateGRPC 与 HTTP buildAuthMiddleware 同序：白名单 → token → Session → enricher → 策略表。
+func authenticateGRPC(ctx context.Context, fullMethod string, options grpcAuthOptions) (context.Context, error) {
+	ensureInitialized()
+	m := globalManager.(*manager)
 
-		ensureInitialized()
-		m := globalManager.(*manager)
-		sess, err := m.LoadFromToken(ctx, token, options.providerName)
-		if err != nil || sess == nil {
-			return handler(ctx, req)
+	if m.grpcWhiteListMatch(fullMethod) || NewWhiteListMatcher(options.whiteList).Match(fullMethod) {
+		return ctx, nil
+	}
+
+	token := tokenFromIncoming(ctx)
+	if token == "" {
+		if sess := peerServiceSession(ctx, options.peerScopes); sess != nil {
+			ctx = bindAuthContextGRPC(ctx, sess)
+			return ctx, grpcAuthorize(ctx, m, sess, fullMethod, options.tokenMissingCode)
+		}
+		if options.required {
+			return ctx, grpcAuthError(myException.NewBizError(options.tokenMissingCode, nil))
 		}
+		return ctx, grpcAuthorize(ctx, m, nil, fullMethod, options.tokenMissingCode)
+	}
 
-		if err := runEnrichers(ctx, sess, options.enricherNames); err != nil {
-			return nil, err
+	sess, err := m.LoadFromToken(ctx, token, options.providerName)
+	if err != nil {
+		return ctx, grpcAuthError(myException.NewBizError(options.tokenInvalidCode, nil))
+	}
+	if sess == nil {
+		if options.required {
+			return ctx, grpcAuthError(myException.NewBizError(options.tokenInvalidCode, nil))
 		}
+		return ctx, grpcAuthorize(ctx, m, nil, fullMethod, options.tokenMissingCode)
+	}
 
-		ctx = bindAuthContextGRPC(ctx, sess)
-		return handler(ctx, req)
+	if err := runEnrichers(ctx, sess, options.enricherNames); err != nil {
+		return ctx, err
 	}
+
+	ctx = bindAuthContextGRPC(ctx, sess)
+	return ctx, grpcAuthorize(ctx, m, sess, fullMethod, options.tokenMissingCode)
 }
 
-// RegisterGRPCAuth 将 GRPCAuthInterceptor 注册到全局 gRPC 链。
+// peerServiceSession 对端证书身份命中 WithGRPCPeerIdentities 时构建服务主体 Session。
+func peerServiceSession(ctx context.Context, peerScopes map[string][]string) *Session {
+	if len(peerScopes) == 0 {
+		return nil
+	}
+	id, ok := myContext.TryGetPeerIdentity(ctx)
+	if !ok {
+		if id, ok = interceptor.PeerIdentity(ctx); !ok {
+			return nil
+		}
+	}
+	for _, name := range []string{id.SpiffeID, id.CommonName} {
+		if scopes, ok := peerScopes[name]; ok && name != "" {
+			return newServiceSession(name, id.CommonName, scopes, time.Time{})
+		}
+	}
+	return nil
+}
+
+// grpcAuthorize 按 auth.rbac.policies 校验全方法名。
+func grpcAuthorize(ctx context.Context, m *manager, sess *Session, fullMethod, missingCode string) error {
+	return grpcAuthError(m.authorize(ctx, sess, fullMethod, "", missingCode))
+}
+
+// grpcAuthError 鉴权业务错误转为 gRPC status：未登录 / token 无效为 Unauthenticated，其余为 PermissionDenied。
+func grpcAuthError(err error) error {
+	if err == nil {
+		return nil
+	}
+	metrics.AuthFailure("grpc", myException.GetErrorCode(err))
+	bizErr, ok := err.(*myException.BizError)
+	if !ok {
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.PermissionDenied
+	if bizErr.Code != CodePermissionDenied {
+		code = codes.Unauthenticated
+	}
+	return status.Error(code, myException.EncodeRpcError(bizErr.Code, bizErr.Args))
+}
+
+// tokenFromIncoming 优先读 ContextExtract 写入的 token，单独使用拦截器时回退到 incoming metadata。
+func tokenFromIncoming(ctx context.Context) string {
+	if token := myContext.TryGetToken(ctx); token != "" {
+		return token
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(myContext.HeaderToken); len(vals) > 0 {
+			return NormalizeToken(vals[0])
+		}
+	}
+	return ""
+}
+
+// RegisterGRPCAuth 将 GRPCOptional / GRPCOptionalStream 注册到全局 gRPC 链。
 // 须在 myAuth.Init / MustInitFromViper 之后、starter.Run 之前调用。
 func RegisterGRPCAuth(opts ...GRPCOption) {
-	rpc.RegisterUnaryServerInterceptor(GRPCAuthInterceptor(opts...))
+	rpc.RegisterUnaryServerInterceptor(GRPCOptional(opts...))
+	rpc.RegisterStreamServerInterceptor(GRPCOptionalStream(opts...))
+}
+
+// RegisterGRPCAuthRequired 将 GRPCRequired / GRPCRequiredStream 注册到全局 gRPC 链，白名单方法除外。
+func RegisterGRPCAuthRequired(opts ...GRPCOption) {
+	rpc.RegisterUnaryServerInterceptor(GRPCRequired(opts...))
+	rpc.RegisterStreamServerInterceptor(GRPCRequiredStream(opts...))
 }
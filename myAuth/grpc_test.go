@@ -3,8 +3,11 @@ package myAuth
 import (
 	"context"
 	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"testing"
 )
 
@@ -70,3 +73,123 @@ func TestGRPCAuthInterceptorLoadsSession(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func rpcBizCode(t *testing.T, err error, want codes.Code) string {
+	t.Helper()
+	st, _ := status.FromError(err)
+	if st.Code() != want {
+		t.Fatalf("code = %v, want %v (%v)", st.Code(), want, err)
+	}
+	payload, ok := myException.DecodeRpcError(st.Message())
+	if !ok {
+		t.Fatalf("message is not an rpc error payload: %q", st.Message())
+	}
+	return payload.BizCode
+}
+
+func TestGRPCRequired(t *testing.T) {
+	initTestAuth(t, Config{
+		Provider:           ProviderEncryptedJWT,
+		TokenExpireSeconds: 3600,
+		JWT:                JWTConfig{Key: testJWTKey(), Issuer: "test"},
+		GRPCWhiteList:      []string{"/echo.Echo/Ping"},
+	})
+	_, token, err := Manager().Create(context.Background(), &SessionInput{UserID: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	interceptor := GRPCRequired(WithGRPCWhiteList("/public.Catalog/*"))
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	call := func(method, tok string) error {
+		ctx := context.Background()
+		if tok != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(myContext.HeaderToken, tok))
+		}
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	if code := rpcBizCode(t, call("/echo.Echo/Echo", ""), codes.Unauthenticated); code != CodeTokenMissing {
+		t.Fatalf("missing token: bizCode = %s", code)
+	}
+	if code := rpcBizCode(t, call("/echo.Echo/Echo", "garbage"), codes.Unauthenticated); code != CodeTokenInvalid {
+		t.Fatalf("invalid token: bizCode = %s", code)
+	}
+	if err := call("/echo.Echo/Echo", token); err != nil {
+		t.Fatalf("valid token: %v", err)
+	}
+	for _, method := range []string{"/echo.Echo/Ping", "/grpc.health.v1.Health/Check"} {
+		if err := call(method, ""); err != nil {
+			t.Fatalf("%s should be allowlisted: %v", method, err)
+		}
+	}
+	if err := call("/public.Catalog/List", ""); err != nil {
+		t.Fatalf("option allowlist: %v", err)
+	}
+}
+
+func TestGRPCRequiredStream(t *testing.T) {
+	initTestAuth(t, Config{
+		Provider:           ProviderEncryptedJWT,
+		TokenExpireSeconds: 3600,
+		JWT:                JWTConfig{Key: testJWTKey(), Issuer: "test"},
+	})
+	_, token, err := Manager().Create(context.Background(), &SessionInput{UserID: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	interceptor := GRPCRequiredStream()
+	info := &grpc.StreamServerInfo{FullMethod: "/echo.Echo/Watch", IsServerStream: true}
+	handler := func(srv any, stream grpc.ServerStream) error {
+		sess, ok := SessionFromContext(stream.Context())
+		if !ok || sess.UserID != 6 {
+			t.Fatalf("session missing in stream context: %+v", sess)
+		}
+		return nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(myContext.HeaderToken, token))
+	if err := interceptor(nil, &fakeServerStream{ctx: ctx}, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	err = interceptor(nil, &fakeServerStream{ctx: context.Background()}, info, handler)
+	if code := rpcBizCode(t, err, codes.Unauthenticated); code != CodeTokenMissing {
+		t.Fatalf("bizCode = %s", code)
+	}
+}
+
+func TestGRPCPeerIdentityPrincipal(t *testing.T) {
+	initTestAuth(t, rbacTestConfig())
+	interceptor := GRPCRequired(WithGRPCPeerIdentities(map[string][]string{
+		"spiffe://corp/ns/prod/sa/billing": {"order:refund"},
+	}))
+	info := &grpc.UnaryServerInfo{FullMethod: "/order.OrderService/Refund"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		sess, ok := SessionFromContext(ctx)
+		if !ok || !sess.IsService() || sess.ServiceID != "spiffe://corp/ns/prod/sa/billing" {
+			t.Fatalf("expected peer service principal, got %+v", sess)
+		}
+		return "ok", nil
+	}
+	call := func(spiffeID string) error {
+		ctx := myContext.WithPeerIdentity(context.Background(), myContext.PeerIdentity{CommonName: "billing", SpiffeID: spiffeID})
+		_, err := interceptor(ctx, nil, info, handler)
+		return err
+	}
+
+	if err := call("spiffe://corp/ns/prod/sa/billing"); err != nil {
+		t.Fatalf("mapped peer should pass: %v", err)
+	}
+	if code := status.Code(call("spiffe://corp/ns/prod/sa/unknown")); code != codes.Unauthenticated {
+		t.Fatalf("unmapped peer: code = %v", code)
+	}
+}
@@ -0,0 +1,120 @@
+package myAuth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+const defaultJWKSRefreshSeconds = 300
+
+// jwksForceRefreshInterval 未知 kid 触发强制刷新的最小间隔；
+// 伪造 kid 的请求不会放大为对 JWKS 端点的请求，正常密钥轮换最多延迟该间隔生效
+const jwksForceRefreshInterval = 30 * time.Second
+
+// jwksSource 验签公钥来源：本地密钥 + JWKS 文件 + 远程 JWKS（带缓存与后台刷新）。
+type jwksSource struct {
+	local jwk.Set
+	cache *jwk.Cache
+	url   string
+
+	mu sync.Mutex
+	// fetchedAt 最近一次拉取远程 JWKS 的时间（首次拉取或强制刷新）
+	fetchedAt     time.Time
+	forceInterval time.Duration
+}
+
+func newJWKSSource(local jwk.Set, file, url string, refresh time.Duration) (*jwksSource, error) {
+	if local == nil {
+		local = jwk.NewSet()
+	}
+	src := &jwksSource{local: local, forceInterval: jwksForceRefreshInterval}
+
+	if file = strings.TrimSpace(file); file != "" {
+		set, err := jwk.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("myAuth: read jwks file %s: %w", file, err)
+		}
+		if err := mergeKeySet(src.local, set); err != nil {
+			return nil, err
+		}
+	}
+
+	if url = strings.TrimSpace(url); url != "" {
+		if refresh <= 0 {
+			refresh = defaultJWKSRefreshSeconds * time.Second
+		}
+		cache := jwk.NewCache(context.Background())
+		if err := cache.Register(url, jwk.WithMinRefreshInterval(refresh)); err != nil {
+			return nil, fmt.Errorf("myAuth: register jwks url %s: %w", url, err)
+		}
+		src.cache = cache
+		src.url = url
+	}
+	return src, nil
+}
+
+// KeySet 合并本地与远程公钥；远程拉取失败时仅返回本地公钥并附带错误。
+func (s *jwksSource) KeySet(ctx context.Context) (jwk.Set, error) {
+	if s.cache == nil {
+		return s.local, nil
+	}
+	remote, err := s.cache.Get(ctx, s.url)
+	if err != nil {
+		return s.local, fmt.Errorf("myAuth: fetch jwks %s: %w", s.url, err)
+	}
+	s.mu.Lock()
+	if s.fetchedAt.IsZero() {
+		s.fetchedAt = time.Now()
+	}
+	s.mu.Unlock()
+	if s.local.Len() == 0 {
+		return remote, nil
+	}
+	merged := jwk.NewSet()
+	if err := mergeKeySet(merged, s.local); err != nil {
+		return nil, err
+	}
+	if err := mergeKeySet(merged, remote); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// RefreshForKid kid 不在当前公钥集中时强制刷新远程 JWKS（对方可能已轮换密钥）。
+// 距上次拉取不足 forceInterval 时跳过；并发请求中只有一个会真正发起刷新。
+func (s *jwksSource) RefreshForKid(ctx context.Context, kid string) {
+	if s.cache == nil || kid == "" {
+		return
+	}
+	if set, _ := s.KeySet(ctx); set != nil {
+		if _, ok := set.LookupKeyID(kid); ok {
+			return
+		}
+	}
+	s.mu.Lock()
+	if !s.fetchedAt.IsZero() && time.Since(s.fetchedAt) < s.forceInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.fetchedAt = time.Now()
+	s.mu.Unlock()
+	_, _ = s.cache.Refresh(ctx, s.url)
+}
+
+func mergeKeySet(dst, src jwk.Set) error {
+	for i := 0; i < src.Len(); i++ {
+		key, ok := src.Key(i)
+		if !ok {
+			continue
+		}
+		if err := dst.AddKey(key); err != nil {
+			return fmt.Errorf("myAuth: add jwk %s: %w", key.KeyID(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package myAuth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+func TestJWKSForcedRefreshIsRateLimited(t *testing.T) {
+	raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := jwk.FromRaw(raw.Public())
+	_ = key.Set(jwk.KeyIDKey, "k1")
+	set := jwk.NewSet()
+	_ = set.AddKey(key)
+	body, _ := json.Marshal(set)
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	src, err := newJWKSSource(nil, "", srv.URL, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// 已知 kid 不刷新；伪造 kid 在首次拉取后的间隔内不刷新
+	src.RefreshForKid(ctx, "k1")
+	for i := 0; i < 5; i++ {
+		src.RefreshForKid(ctx, "forged")
+	}
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("jwks fetched %d times, want 1", n)
+	}
+
+	src.mu.Lock()
+	src.fetchedAt = time.Now().Add(-jwksForceRefreshInterval)
+	src.mu.Unlock()
+	for i := 0; i < 5; i++ {
+		src.RefreshForKid(ctx, "forged")
+	}
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("jwks fetched %d times after interval, want 2", n)
+	}
+}
@@ -11,9 +11,12 @@ import (
 // SessionManager 会话管理入口。
 type SessionManager interface {
 	Create(ctx context.Context, input *SessionInput) (*Session, string, error)
+	Refresh(ctx context.Context, refreshToken string) (*Session, string, error)
 	LoadFromRequest(c *gin.Context, providerName string) (*Session, error)
 	LoadFromToken(ctx context.Context, token string, providerName string) (*Session, error)
 	Destroy(ctx context.Context, token string) error
+	ListSessions(ctx context.Context, userID int64) ([]SessionInfo, error)
+	DestroyAllForUser(ctx context.Context, userID int64) error
 }
 
 var globalManager SessionManager
@@ -25,9 +28,15 @@ func Manager() SessionManager {
 }
 
 type manager struct {
-	cfg      Config
-	provider TokenProvider
-	matcher  *WhiteListMatcher
+	cfg         Config
+	provider    TokenProvider
+	matcher     *WhiteListMatcher
+	grpcMatcher *WhiteListMatcher
+	refresh     *redisRefreshStore
+
+	index      *redisUserSessionIndex
+	validAfter *redisValidAfterStore
+	policies   *policyTable
 }
 
 func newManager(cfg Config) (*manager, error) {
@@ -36,11 +45,19 @@ func newManager(cfg Config) (*manager, error) {
 		return nil, err
 	}
 	RegisterTokenProvider(provider)
-	return &manager{
-		cfg:      cfg,
-		provider: provider,
-		matcher:  NewWhiteListMatcher(cfg.WhiteList),
-	}, nil
+	m := &manager{
+		cfg:         cfg,
+		provider:    provider,
+		matcher:     NewWhiteListMatcher(cfg.WhiteList),
+		grpcMatcher: NewWhiteListMatcher(append(append([]string{}, defaultGRPCWhiteList...), cfg.GRPCWhiteList...)),
+		index:       newRedisUserSessionIndex(cfg.Session.UserIndexPrefix, cfg.tokenTTL()),
+		validAfter:  newRedisValidAfterStore(cfg.Session.ValidAfterPrefix, cfg.validAfterTTL()),
+		policies:    newPolicyTable(cfg.RBAC.Policies),
+	}
+	if cfg.Refresh.Enabled {
+		m.refresh = newRedisRefreshStore(cfg.Refresh, cfg.refreshTTL())
+	}
+	return m, nil
 }
 
 func (m *manager) Create(ctx context.Context, input *SessionInput) (*Session, string, error) {
@@ -64,6 +81,12 @@ func (m *manager) Create(ctx context.Context, input *SessionInput) (*Session, st
 	sess := claimsToSession(claims)
 	sess.Token = token
 	sess.JTI = claims.JTI
+	if err := m.issueRefresh(ctx, claims, ttl, nil, sess); err != nil {
+		return nil, "", err
+	}
+	if err := m.indexSession(ctx, claims); err != nil {
+		return nil, "", err
+	}
 	return sess, token, nil
 }
 
@@ -86,6 +109,8 @@ func (m *manager) LoadFromToken(ctx context.Context, token string, providerName
 		if err != nil {
 			return nil, err
 		}
+		// 缓存按路由指定的内置 provider，保留其 JWKS / discovery 等状态。
+		RegisterTokenProvider(p)
 		provider = p
 	}
 
@@ -115,9 +140,27 @@ func (m *manager) Destroy(ctx context.Context, token string) error {
 	if claims == nil {
 		return nil
 	}
+	if m.refresh != nil {
+		family, err := m.refresh.FamilyOf(ctx, claims.JTI)
+		if err != nil {
+			return err
+		}
+		if family != "" {
+			if err := m.revokeFamily(ctx, claims.UserID, family); err != nil {
+				return err
+			}
+		}
+	}
+	if err := m.index.Remove(ctx, claims.UserID, claims.JTI); err != nil {
+		return err
+	}
 	return m.provider.Revoke(ctx, claims)
 }
 
 func (m *manager) whiteListMatch(path string) bool {
 	return m.matcher.Match(path)
 }
+
+func (m *manager) grpcWhiteListMatch(fullMethod string) bool {
+	return m.grpcMatcher.Match(fullMethod)
+}
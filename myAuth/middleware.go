@@ -2,14 +2,17 @@ package myAuth
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure/metrics"
 	"github.com/muyi-zcy/tech-muyi-base-go/myException"
 	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
 )
 
 const (
-	CodeTokenMissing     = "platform.auth.token_missing"
-	CodeTokenInvalid     = "platform.auth.token_invalid"
-	CodePermissionDenied = "platform.auth.permission_denied"
+	CodeTokenMissing        = "platform.auth.token_missing"
+	CodeTokenInvalid        = "platform.auth.token_invalid"
+	CodePermissionDenied    = "platform.auth.permission_denied"
+	CodeRefreshTokenInvalid = "platform.auth.refresh_token_invalid"
+	CodeCredentialInvalid   = "platform.auth.credential_invalid"
 )
 
 type middlewareOptions struct {
@@ -21,6 +24,7 @@ type middlewareOptions struct {
 	tokenInvalidCode string
 	enricherNames    []string
 	beforeAuth       BeforeAuthHook
+	serviceAuth      []ServiceAuthenticator
 }
 
 // Option 中间件配置项。
@@ -112,9 +116,30 @@ func buildAuthMiddleware(base middlewareOptions, opts ...Option) gin.HandlerFunc
 			}
 		}
 
+		if len(base.serviceAuth) > 0 {
+			sess, err := authenticateService(c, base.serviceAuth)
+			if err != nil {
+				abortWithError(c, myException.NewBizError(CodeCredentialInvalid, nil))
+				return
+			}
+			if sess != nil {
+				bindAuthContext(c, sess)
+				if err := m.authorize(c.Request.Context(), sess, path, c.Request.Method, base.tokenMissingCode); err != nil {
+					abortWithError(c, err)
+					return
+				}
+				c.Next()
+				return
+			}
+		}
+
 		token := ExtractToken(c)
 		if token == "" {
 			if !base.required {
+				if err := m.authorize(c.Request.Context(), nil, path, c.Request.Method, base.tokenMissingCode); err != nil {
+					abortWithError(c, err)
+					return
+				}
 				c.Next()
 				return
 			}
@@ -136,6 +161,10 @@ func buildAuthMiddleware(base middlewareOptions, opts ...Option) gin.HandlerFunc
 		}
 		if sess == nil {
 			if !base.required {
+				if err := m.authorize(c.Request.Context(), nil, path, c.Request.Method, base.tokenMissingCode); err != nil {
+					abortWithError(c, err)
+					return
+				}
 				c.Next()
 				return
 			}
@@ -150,6 +179,10 @@ func buildAuthMiddleware(base middlewareOptions, opts ...Option) gin.HandlerFunc
 		}
 
 		bindAuthContext(c, sess)
+		if err := m.authorize(c.Request.Context(), sess, path, c.Request.Method, base.tokenMissingCode); err != nil {
+			abortWithError(c, err)
+			return
+		}
 		c.Next()
 	}
 }
@@ -178,6 +211,7 @@ func Permission(code string, check PermissionCheck, deniedCode ...string) gin.Ha
 }
 
 func abortWithError(c *gin.Context, err error) {
+	metrics.AuthFailure("http", myException.GetErrorCode(err))
 	myResult.ErrorWithError(c, err)
 	c.Abort()
 }
@@ -44,6 +44,10 @@ func resolveProvider(name string, cfg Config) (TokenProvider, error) {
 		return newEncryptedJWTProvider(cfg)
 	case ProviderRedisOpaque:
 		return newRedisOpaqueProvider(cfg)
+	case ProviderSignedJWT:
+		return newSignedJWTProvider(cfg)
+	case ProviderOIDC:
+		return newOIDCProvider(cfg)
 	default:
 		return nil, errUnknownProvider(name)
 	}
@@ -18,6 +18,8 @@ type encryptedJWTProvider struct {
 	audience string
 	ttl      time.Duration
 	revoke   *redisRevocationStore
+
+	validAfter *redisValidAfterStore
 }
 
 func newEncryptedJWTProvider(cfg Config) (TokenProvider, error) {
@@ -31,6 +33,8 @@ func newEncryptedJWTProvider(cfg Config) (TokenProvider, error) {
 		audience: cfg.JWT.Audience,
 		ttl:      cfg.tokenTTL(),
 		revoke:   newRedisRevocationStore(cfg.Session.RevokePrefix),
+
+		validAfter: newRedisValidAfterStore(cfg.Session.ValidAfterPrefix, cfg.validAfterTTL()),
 	}, nil
 }
 
@@ -50,21 +54,9 @@ func (p *encryptedJWTProvider) Issue(ctx context.Context, claims *Claims) (strin
 		jti = uuid.NewString()
 	}
 	claims.JTI = jti
+	claims.IssuedAt = now
 
-	builder := jwt.NewBuilder().
-		Issuer(p.issuer).
-		Subject(strconv.FormatInt(claims.UserID, 10)).
-		IssuedAt(now).
-		Expiration(expireAt).
-		JwtID(jti).
-		Claim("username", claims.Username).
-		Claim("displayName", claims.DisplayName)
-
-	if p.audience != "" {
-		builder = builder.Audience([]string{p.audience})
-	}
-
-	tok, err := builder.Build()
+	tok, err := platformJWTBuilder(p.issuer, p.audience, claims, now, expireAt).Build()
 	if err != nil {
 		return "", err
 	}
@@ -95,37 +87,69 @@ func (p *encryptedJWTProvider) Parse(ctx context.Context, token string) (*Claims
 		return nil, err
 	}
 
-	jti, _ := tok.Get(jwt.JwtIDKey)
-	jtiStr, _ := jti.(string)
-	if revoked, err := p.revoke.IsRevoked(ctx, jtiStr); err != nil {
+	claims := platformClaims(tok)
+	if err := checkJWTRevoked(ctx, p.revoke, p.validAfter, claims); err != nil {
 		return nil, err
-	} else if revoked {
-		return nil, fmt.Errorf("myAuth: token revoked")
 	}
+	return claims, nil
+}
 
-	sub, _ := tok.Get(jwt.SubjectKey)
-	userID, _ := strconv.ParseInt(fmt.Sprint(sub), 10, 64)
+// platformJWTBuilder 平台标准 JWT 载荷：sub=userId，附带 username / displayName。
+func platformJWTBuilder(issuer, audience string, claims *Claims, now, expireAt time.Time) *jwt.Builder {
+	builder := jwt.NewBuilder().
+		Issuer(issuer).
+		Subject(strconv.FormatInt(claims.UserID, 10)).
+		IssuedAt(now).
+		Expiration(expireAt).
+		JwtID(claims.JTI).
+		Claim("username", claims.Username).
+		Claim("displayName", claims.DisplayName)
+	if audience != "" {
+		builder = builder.Audience([]string{audience})
+	}
+	return builder
+}
 
+// platformClaims 从已校验的 JWT 读取平台标准 Claims。
+func platformClaims(tok jwt.Token) *Claims {
+	userID, _ := strconv.ParseInt(tok.Subject(), 10, 64)
 	username, _ := tok.Get("username")
 	displayName, _ := tok.Get("displayName")
+	return &Claims{
+		UserID:      userID,
+		Username:    stringClaim(username),
+		DisplayName: stringClaim(displayName),
+		ExpireAt:    tok.Expiration(),
+		IssuedAt:    tok.IssuedAt(),
+		JTI:         tok.JwtID(),
+	}
+}
 
-	var expireAt time.Time
-	if exp, ok := tok.Get(jwt.ExpirationKey); ok {
-		switch v := exp.(type) {
-		case time.Time:
-			expireAt = v
-		case float64:
-			expireAt = time.Unix(int64(v), 0)
-		}
+// checkJWTRevoked JTI 吊销与用户级 valid-after 校验。
+func checkJWTRevoked(ctx context.Context, revoke *redisRevocationStore, validAfter *redisValidAfterStore, claims *Claims) error {
+	if revoked, err := revoke.IsRevoked(ctx, claims.JTI); err != nil {
+		return err
+	} else if revoked {
+		return fmt.Errorf("myAuth: token revoked")
 	}
+	after, err := validAfter.Get(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+	if !after.IsZero() && claims.IssuedAt.Before(after) {
+		return fmt.Errorf("myAuth: token revoked")
+	}
+	return nil
+}
 
-	return &Claims{
-		UserID:      userID,
-		Username:    fmt.Sprint(username),
-		DisplayName: fmt.Sprint(displayName),
-		ExpireAt:    expireAt,
-		JTI:         jtiStr,
-	}, nil
+func stringClaim(v any) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
 }
 
 func (p *encryptedJWTProvider) Revoke(ctx context.Context, claims *Claims) error {
@@ -137,16 +161,27 @@ func (p *encryptedJWTProvider) Revoke(ctx context.Context, claims *Claims) error
 }
 
 type redisOpaqueProvider struct {
-	store  *redisSessionStore
-	revoke *redisRevocationStore
-	ttl    time.Duration
+	store           *redisSessionStore
+	revoke          *redisRevocationStore
+	ttl             time.Duration
+	sliding         bool
+	slidingInterval time.Duration
+
+	// index 滑动续期后同步用户会话索引中的过期时间
+	index      *redisUserSessionIndex
+	validAfter *redisValidAfterStore
 }
 
 func newRedisOpaqueProvider(cfg Config) (TokenProvider, error) {
 	return &redisOpaqueProvider{
-		store:  newRedisSessionStore(cfg.Session.KeyPrefix, cfg.tokenTTL()),
-		revoke: newRedisRevocationStore(cfg.Session.RevokePrefix),
-		ttl:    cfg.tokenTTL(),
+		store:           newRedisSessionStore(cfg.Session.KeyPrefix, cfg.tokenTTL()),
+		revoke:          newRedisRevocationStore(cfg.Session.RevokePrefix),
+		ttl:             cfg.tokenTTL(),
+		sliding:         cfg.Session.Sliding,
+		slidingInterval: cfg.slidingInterval(),
+
+		index:      newRedisUserSessionIndex(cfg.Session.UserIndexPrefix, cfg.tokenTTL()),
+		validAfter: newRedisValidAfterStore(cfg.Session.ValidAfterPrefix, cfg.validAfterTTL()),
 	}, nil
 }
 
@@ -164,8 +199,11 @@ func (p *redisOpaqueProvider) Issue(ctx context.Context, claims *Claims) (string
 	if claims.ExpireAt.IsZero() {
 		claims.ExpireAt = time.Now().Add(p.ttl)
 	}
+	claims.IssuedAt = time.Now()
 	sess := claimsToSession(claims)
 	sess.Token = token
+	sess.issuedAt = claims.IssuedAt
+	sess.lifetime = claims.ExpireAt.Sub(claims.IssuedAt)
 	if err := p.store.Save(ctx, token, sess); err != nil {
 		return "", err
 	}
@@ -183,10 +221,20 @@ func (p *redisOpaqueProvider) Parse(ctx context.Context, token string) (*Claims,
 	if !sess.ExpireAt.IsZero() && time.Now().After(sess.ExpireAt) {
 		return nil, fmt.Errorf("myAuth: token expired")
 	}
+	// 「退出所有设备」之前签发的会话即使仍在存储中（如索引条目已丢失）也一律拒绝；
+	// 早于 issuedAt 字段写入的会话签发时间为零值，同样视为之前签发
+	if after, err := p.validAfter.Get(ctx, sess.UserID); err != nil {
+		return nil, err
+	} else if !after.IsZero() && sess.issuedAt.Before(after) {
+		return nil, fmt.Errorf("myAuth: token revoked")
+	}
 	jti := sess.JTI
 	if jti == "" {
 		jti = token
 	}
+	if err := p.slide(ctx, token, jti, sess); err != nil {
+		return nil, err
+	}
 	return &Claims{
 		UserID:      sess.UserID,
 		Username:    sess.Username,
@@ -196,6 +244,27 @@ func (p *redisOpaqueProvider) Parse(ctx context.Context, token string) (*Claims,
 	}, nil
 }
 
+// slide 滑动续期：距上次续期超过 slidingInterval 时将过期时间推至 now + 会话自身有效期
+// （SessionInput.TTL 短于全局 ttl 的会话不会被拉长），并同步用户会话索引，避免索引按原过期时间清理仍有效的会话。
+func (p *redisOpaqueProvider) slide(ctx context.Context, token, jti string, sess *Session) error {
+	if !p.sliding || sess.ExpireAt.IsZero() {
+		return nil
+	}
+	lifetime := sess.lifetime
+	if lifetime <= 0 {
+		lifetime = p.ttl
+	}
+	renewed := time.Now().Add(lifetime)
+	if renewed.Sub(sess.ExpireAt) < p.slidingInterval {
+		return nil
+	}
+	sess.ExpireAt = renewed
+	if err := p.store.Save(ctx, token, sess); err != nil {
+		return err
+	}
+	return p.index.Touch(ctx, sess.UserID, jti, renewed)
+}
+
 func (p *redisOpaqueProvider) Revoke(ctx context.Context, claims *Claims) error {
 	if claims == nil || claims.JTI == "" {
 		return nil
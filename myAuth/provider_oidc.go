@@ -0,0 +1,257 @@
+package myAuth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"golang.org/x/sync/singleflight"
+)
+
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+// oidcDiscoveryRetryInterval discovery 失败后的退避时间，期间直接返回上次的错误，不再请求 IdP
+const oidcDiscoveryRetryInterval = 5 * time.Second
+
+// oidcReservedClaims 已参与校验或映射的标准 claims，不写入 extras。
+var oidcReservedClaims = map[string]struct{}{
+	jwt.IssuerKey:     {},
+	jwt.AudienceKey:   {},
+	jwt.ExpirationKey: {},
+	jwt.IssuedAtKey:   {},
+	jwt.NotBeforeKey:  {},
+	jwt.JwtIDKey:      {},
+}
+
+// oidcProvider 外部 IdP 签发的 access token 验证（资源服务器模式）。
+// discovery 在首次解析时拉取，并发请求合并为一次；失败后退避 oidcDiscoveryRetryInterval 再重试。
+type oidcProvider struct {
+	cfg      OIDCConfig
+	issuer   string
+	audience string
+	refresh  time.Duration
+	skew     time.Duration
+	client   *http.Client
+	revoke   *redisRevocationStore
+
+	validAfter *redisValidAfterStore
+
+	discovery singleflight.Group
+	mu        sync.Mutex
+	keys      *jwksSource
+	// lastErr / failedAt 最近一次 discovery 失败，退避期内直接返回
+	lastErr  error
+	failedAt time.Time
+}
+
+type oidcDiscovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+func newOIDCProvider(cfg Config) (TokenProvider, error) {
+	oc := cfg.OIDC
+	if oc.Issuer == "" {
+		return nil, fmt.Errorf("myAuth: auth.oidc.issuer is required")
+	}
+	return &oidcProvider{
+		cfg:      oc,
+		issuer:   oc.Issuer,
+		audience: oc.Audience,
+		refresh:  time.Duration(oc.JWKSRefreshSeconds) * time.Second,
+		skew:     time.Duration(oc.ClockSkewSeconds) * time.Second,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		revoke:   newRedisRevocationStore(cfg.Session.RevokePrefix),
+
+		validAfter: newRedisValidAfterStore(cfg.Session.ValidAfterPrefix, cfg.validAfterTTL()),
+	}, nil
+}
+
+func (p *oidcProvider) Name() string { return ProviderOIDC }
+
+func (p *oidcProvider) Issue(context.Context, *Claims) (string, error) {
+	return "", fmt.Errorf("myAuth: oidc provider cannot issue tokens")
+}
+
+func (p *oidcProvider) Parse(ctx context.Context, token string) (*Claims, error) {
+	keys, err := p.keySource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys.RefreshForKid(ctx, tokenKeyID(token))
+	set, err := keys.KeySet(ctx)
+	if err != nil && set.Len() == 0 {
+		return nil, err
+	}
+
+	opts := []jwt.ParseOption{
+		jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true)),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAcceptableSkew(p.skew),
+	}
+	if p.audience != "" {
+		opts = append(opts, jwt.WithAudience(p.audience))
+	}
+	tok, err := jwt.Parse([]byte(token), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := p.mapClaims(ctx, tok)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkJWTRevoked(ctx, p.revoke, p.validAfter, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Revoke 仅在本地吊销（按 jti），不通知 IdP。
+func (p *oidcProvider) Revoke(ctx context.Context, claims *Claims) error {
+	if claims == nil || claims.JTI == "" {
+		return nil
+	}
+	return p.revoke.Revoke(ctx, claims.JTI, time.Until(claims.ExpireAt))
+}
+
+// mapClaims 按配置映射标准字段，其余 claims 加前缀放入 Extras。
+func (p *oidcProvider) mapClaims(ctx context.Context, tok jwt.Token) (*Claims, error) {
+	all, err := tok.AsMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rawID, ok := all[p.cfg.ClaimUserID]
+	if !ok {
+		return nil, fmt.Errorf("myAuth: oidc token missing claim %s", p.cfg.ClaimUserID)
+	}
+	userID, err := claimInt64(rawID)
+	if err != nil {
+		return nil, fmt.Errorf("myAuth: oidc claim %s: %w", p.cfg.ClaimUserID, err)
+	}
+
+	claims := &Claims{
+		UserID:      userID,
+		Username:    stringClaim(all[p.cfg.ClaimUsername]),
+		DisplayName: stringClaim(all[p.cfg.ClaimDisplayName]),
+		ExpireAt:    tok.Expiration(),
+		IssuedAt:    tok.IssuedAt(),
+		JTI:         tok.JwtID(),
+	}
+	for k, v := range all {
+		if _, reserved := oidcReservedClaims[k]; reserved {
+			continue
+		}
+		if k == p.cfg.ClaimUserID || k == p.cfg.ClaimUsername || k == p.cfg.ClaimDisplayName {
+			continue
+		}
+		if claims.Extras == nil {
+			claims.Extras = make(map[string]any)
+		}
+		claims.Extras[p.cfg.ExtrasPrefix+k] = v
+	}
+	return claims, nil
+}
+
+// keySource 返回 discovery 得到的 JWKS 来源；首次调用时拉取 discovery，HTTP 请求期间不持有锁。
+func (p *oidcProvider) keySource(ctx context.Context) (*jwksSource, error) {
+	p.mu.Lock()
+	keys, lastErr, failedAt := p.keys, p.lastErr, p.failedAt
+	p.mu.Unlock()
+	if keys != nil {
+		return keys, nil
+	}
+	if lastErr != nil && time.Since(failedAt) < oidcDiscoveryRetryInterval {
+		return nil, lastErr
+	}
+
+	// 合并后的请求不随某个调用方取消，超时由 client 控制
+	v, err, _ := p.discovery.Do("discovery", func() (any, error) {
+		p.mu.Lock()
+		keys := p.keys
+		p.mu.Unlock()
+		if keys != nil {
+			return keys, nil
+		}
+		keys, err := p.loadKeySource(context.WithoutCancel(ctx))
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if err != nil {
+			p.lastErr, p.failedAt = err, time.Now()
+			return nil, err
+		}
+		p.keys, p.lastErr = keys, nil
+		return keys, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*jwksSource), nil
+}
+
+func (p *oidcProvider) loadKeySource(ctx context.Context) (*jwksSource, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newJWKSSource(nil, "", doc.JWKSURI, p.refresh)
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	url := p.cfg.DiscoveryURL
+	if url == "" {
+		url = strings.TrimSuffix(p.issuer, "/") + oidcDiscoveryPath
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("myAuth: oidc discovery %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("myAuth: oidc discovery %s: status %d", url, resp.StatusCode)
+	}
+	var doc oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("myAuth: oidc discovery %s: %w", url, err)
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(p.issuer, "/") {
+		return nil, fmt.Errorf("myAuth: oidc discovery issuer %q does not match %q", doc.Issuer, p.issuer)
+	}
+	if doc.JWKSURI == "" {
+		return nil, fmt.Errorf("myAuth: oidc discovery %s has no jwks_uri", url)
+	}
+	return &doc, nil
+}
+
+func claimInt64(v any) (int64, error) {
+	switch val := v.(type) {
+	case string:
+		return strconv.ParseInt(val, 10, 64)
+	case float64:
+		if val != math.Trunc(val) {
+			return 0, fmt.Errorf("%v is not an integer", val)
+		}
+		return int64(val), nil
+	case json.Number:
+		return val.Int64()
+	case int64:
+		return val, nil
+	case int:
+		return int64(val), nil
+	default:
+		return 0, fmt.Errorf("unsupported value %v", v)
+	}
+}
@@ -0,0 +1,246 @@
+package myAuth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// stubIdP 本地模拟 IdP：discovery + JWKS，可在运行中轮换签名密钥。
+type stubIdP struct {
+	srv *httptest.Server
+
+	mu     sync.Mutex
+	signer jwk.Key
+	public jwk.Set
+
+	// discoveries discovery 请求次数；discoveryDown 为 true 时 discovery 返回 503
+	discoveries   atomic.Int32
+	discoveryDown atomic.Bool
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+	idp := &stubIdP{public: jwk.NewSet()}
+	idp.rotate(t, "idp-1")
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcDiscoveryPath, func(w http.ResponseWriter, _ *http.Request) {
+		idp.discoveries.Add(1)
+		if idp.discoveryDown.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   idp.srv.URL,
+			"jwks_uri": idp.srv.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		idp.mu.Lock()
+		defer idp.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(idp.public)
+	})
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+func (idp *stubIdP) rotate(t *testing.T, kid string) {
+	t.Helper()
+	raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, _ := jwk.FromRaw(raw)
+	_ = priv.Set(jwk.KeyIDKey, kid)
+	_ = priv.Set(jwk.AlgorithmKey, jwa.ES256)
+	pub, _ := jwk.PublicKeyOf(priv)
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.signer = priv
+	_ = idp.public.AddKey(pub)
+}
+
+func (idp *stubIdP) token(t *testing.T, build func(b *jwt.Builder) *jwt.Builder) string {
+	t.Helper()
+	now := time.Now()
+	b := jwt.NewBuilder().
+		Issuer(idp.srv.URL).
+		Audience([]string{"my-xi-api"}).
+		IssuedAt(now).
+		Expiration(now.Add(time.Hour)).
+		JwtID("idp-" + now.Format(time.RFC3339Nano))
+	tok, err := build(b).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp.mu.Lock()
+	signer := idp.signer
+	idp.mu.Unlock()
+	signed, err := jwt.Sign(tok, jwt.WithKey(jwa.ES256, signer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(signed)
+}
+
+func oidcTestConfig(idp *stubIdP) Config {
+	return Config{
+		Provider: ProviderOIDC,
+		OIDC: OIDCConfig{
+			Issuer:      idp.srv.URL,
+			Audience:    "my-xi-api",
+			ClaimUserID: "uid",
+		},
+	}
+}
+
+func TestOIDCProviderMapsClaims(t *testing.T) {
+	useTestRedis(t)
+	idp := newStubIdP(t)
+	initTestAuth(t, oidcTestConfig(idp))
+	ctx := context.Background()
+
+	token := idp.token(t, func(b *jwt.Builder) *jwt.Builder {
+		return b.Subject("corp|alice").
+			Claim("uid", "1001").
+			Claim("preferred_username", "alice").
+			Claim("name", "Alice").
+			Claim("groups", []string{"dev"})
+	})
+	sess, err := Manager().LoadFromToken(ctx, token, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess.UserID != 1001 || sess.Username != "alice" || sess.DisplayName != "Alice" {
+		t.Fatalf("unexpected session: %+v", sess)
+	}
+	if sub, _ := sess.Extra("oidc.sub"); sub != "corp|alice" {
+		t.Fatalf("oidc.sub = %v", sub)
+	}
+	if _, ok := sess.Extra("oidc.groups"); !ok {
+		t.Fatal("remaining claims should be in extras")
+	}
+	if _, ok := sess.Extra("oidc.uid"); ok {
+		t.Fatal("mapped claims should not be duplicated in extras")
+	}
+	if _, _, err := Manager().Create(ctx, &SessionInput{UserID: 1}); err == nil {
+		t.Fatal("oidc provider must not issue tokens")
+	}
+}
+
+func TestOIDCProviderRejectsInvalidTokens(t *testing.T) {
+	useTestRedis(t)
+	idp := newStubIdP(t)
+	p, err := newOIDCProvider(oidcTestConfig(idp).withDefaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	cases := map[string]func(b *jwt.Builder) *jwt.Builder{
+		"audience": func(b *jwt.Builder) *jwt.Builder {
+			return b.Claim("uid", 1).Audience([]string{"other"})
+		},
+		"issuer": func(b *jwt.Builder) *jwt.Builder {
+			return b.Claim("uid", 1).Issuer("https://evil.example")
+		},
+		"expired": func(b *jwt.Builder) *jwt.Builder {
+			return b.Claim("uid", 1).Expiration(time.Now().Add(-time.Minute))
+		},
+		"user id": func(b *jwt.Builder) *jwt.Builder {
+			return b.Claim("uid", "alice")
+		},
+	}
+	for name, build := range cases {
+		if _, err := p.Parse(ctx, idp.token(t, build)); err == nil {
+			t.Fatalf("%s: expected rejection", name)
+		}
+	}
+}
+
+func TestOIDCProviderFollowsKeyRotation(t *testing.T) {
+	useTestRedis(t)
+	idp := newStubIdP(t)
+	p, err := newOIDCProvider(oidcTestConfig(idp).withDefaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	withUID := func(b *jwt.Builder) *jwt.Builder { return b.Claim("uid", 7) }
+
+	if _, err := p.Parse(ctx, idp.token(t, withUID)); err != nil {
+		t.Fatal(err)
+	}
+	idp.rotate(t, "idp-2")
+	rotated := idp.token(t, withUID)
+	// 刚拉取过 JWKS，未知 kid 不立即强制刷新
+	if _, err := p.Parse(ctx, rotated); err == nil {
+		t.Fatal("forced refresh should be rate limited")
+	}
+	keys := p.(*oidcProvider).keys
+	keys.mu.Lock()
+	keys.fetchedAt = time.Now().Add(-jwksForceRefreshInterval)
+	keys.mu.Unlock()
+	claims, err := p.Parse(ctx, rotated)
+	if err != nil {
+		t.Fatalf("token signed with rotated key should verify: %v", err)
+	}
+	if claims.UserID != 7 {
+		t.Fatalf("userID = %d", claims.UserID)
+	}
+}
+
+func TestOIDCDiscoveryBackoff(t *testing.T) {
+	useTestRedis(t)
+	idp := newStubIdP(t)
+	idp.discoveryDown.Store(true)
+	p, err := newOIDCProvider(oidcTestConfig(idp).withDefaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	token := idp.token(t, func(b *jwt.Builder) *jwt.Builder { return b.Claim("uid", 7) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = p.Parse(ctx, token)
+		}()
+	}
+	wg.Wait()
+	if _, err := p.Parse(ctx, token); err == nil {
+		t.Fatal("expected discovery error")
+	}
+	if n := idp.discoveries.Load(); n != 1 {
+		t.Fatalf("discovery requested %d times within backoff, want 1", n)
+	}
+
+	// 退避期过后重试
+	idp.discoveryDown.Store(false)
+	op := p.(*oidcProvider)
+	op.mu.Lock()
+	op.failedAt = time.Now().Add(-oidcDiscoveryRetryInterval)
+	op.mu.Unlock()
+	if _, err := p.Parse(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	if n := idp.discoveries.Load(); n != 2 {
+		t.Fatalf("discovery requested %d times, want 2", n)
+	}
+}
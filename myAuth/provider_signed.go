@@ -0,0 +1,293 @@
+package myAuth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+)
+
+// JWKSPath JWKS 公钥发布路径。
+const JWKSPath = "/.well-known/jwks.json"
+
+type signedJWTProvider struct {
+	signer   jwk.Key
+	alg      jwa.SignatureAlgorithm
+	public   jwk.Set
+	keys     *jwksSource
+	issuer   string
+	audience string
+	ttl      time.Duration
+	revoke   *redisRevocationStore
+
+	validAfter *redisValidAfterStore
+}
+
+func newSignedJWTProvider(cfg Config) (TokenProvider, error) {
+	sc := cfg.SignedJWT
+	public := jwk.NewSet()
+	var signer jwk.Key
+	var signerAlg jwa.SignatureAlgorithm
+
+	for _, kc := range sc.Keys {
+		priv, pub, alg, err := loadSigningKey(kc, sc.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		if err := public.AddKey(pub); err != nil {
+			return nil, err
+		}
+		if priv == nil {
+			continue
+		}
+		if signer == nil && (sc.ActiveKid == "" || sc.ActiveKid == kc.Kid) {
+			signer, signerAlg = priv, alg
+		}
+	}
+	if sc.ActiveKid != "" && signer == nil && hasPrivateKey(sc.Keys) {
+		return nil, fmt.Errorf("myAuth: auth.signedJwt.activeKid %q has no private key", sc.ActiveKid)
+	}
+
+	keys, err := newJWKSSource(public, sc.JWKSFile, sc.JWKSURL, time.Duration(sc.JWKSRefreshSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signedJWTProvider{
+		signer:   signer,
+		alg:      signerAlg,
+		public:   public,
+		keys:     keys,
+		issuer:   cfg.JWT.Issuer,
+		audience: cfg.JWT.Audience,
+		ttl:      cfg.tokenTTL(),
+		revoke:   newRedisRevocationStore(cfg.Session.RevokePrefix),
+
+		validAfter: newRedisValidAfterStore(cfg.Session.ValidAfterPrefix, cfg.validAfterTTL()),
+	}, nil
+}
+
+func (p *signedJWTProvider) Name() string { return ProviderSignedJWT }
+
+func (p *signedJWTProvider) Issue(_ context.Context, claims *Claims) (string, error) {
+	if claims == nil {
+		return "", fmt.Errorf("myAuth: claims is nil")
+	}
+	if p.signer == nil {
+		return "", fmt.Errorf("myAuth: signed_jwt is in verify-only mode")
+	}
+	now := time.Now()
+	if claims.ExpireAt.IsZero() {
+		claims.ExpireAt = now.Add(p.ttl)
+	}
+	if claims.JTI == "" {
+		claims.JTI = uuid.NewString()
+	}
+	claims.IssuedAt = now
+
+	tok, err := platformJWTBuilder(p.issuer, p.audience, claims, now, claims.ExpireAt).Build()
+	if err != nil {
+		return "", err
+	}
+	signed, err := jwt.Sign(tok, jwt.WithKey(p.alg, p.signer))
+	if err != nil {
+		return "", err
+	}
+	return string(signed), nil
+}
+
+func (p *signedJWTProvider) Parse(ctx context.Context, token string) (*Claims, error) {
+	tok, err := p.verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	claims := platformClaims(tok)
+	if err := checkJWTRevoked(ctx, p.revoke, p.validAfter, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verify 验签并校验 exp / iss / aud；kid 不在当前公钥集时按限频强制刷新远程 JWKS（对方已轮换密钥）。
+func (p *signedJWTProvider) verify(ctx context.Context, token string) (jwt.Token, error) {
+	p.keys.RefreshForKid(ctx, tokenKeyID(token))
+	return p.parseWithKeys(ctx, token)
+}
+
+func (p *signedJWTProvider) parseWithKeys(ctx context.Context, token string) (jwt.Token, error) {
+	set, err := p.keys.KeySet(ctx)
+	if err != nil && set.Len() == 0 {
+		return nil, err
+	}
+	opts := []jwt.ParseOption{
+		jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true)),
+		jwt.WithValidate(true),
+	}
+	if p.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(p.issuer))
+	}
+	if p.audience != "" {
+		opts = append(opts, jwt.WithAudience(p.audience))
+	}
+	return jwt.Parse([]byte(token), opts...)
+}
+
+func (p *signedJWTProvider) Revoke(ctx context.Context, claims *Claims) error {
+	if claims == nil {
+		return nil
+	}
+	return p.revoke.Revoke(ctx, claims.JTI, time.Until(claims.ExpireAt))
+}
+
+// PublicKeys 本服务可发布的公钥集合（仅配置的本地密钥，不含远程 JWKS）。
+func (p *signedJWTProvider) PublicKeys() jwk.Set {
+	return p.public
+}
+
+// JWKSHandler 输出 JWKS（RFC 7517 原始格式，不包裹 MyResult）。
+func JWKSHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := getProvider(ProviderSignedJWT)
+		if !ok {
+			c.JSON(200, gin.H{"keys": []any{}})
+			return
+		}
+		signed, ok := p.(*signedJWTProvider)
+		if !ok {
+			c.JSON(200, gin.H{"keys": []any{}})
+			return
+		}
+		data, err := json.Marshal(signed.PublicKeys())
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.Data(200, "application/json", data)
+	}
+}
+
+// RegisterJWKSRoute 在 auth.signedJwt.publishJwks=true 时挂载 /.well-known/jwks.json（应挂在根路由而非 API 组）。
+func RegisterJWKSRoute(r gin.IRoutes) {
+	if !CurrentConfig().SignedJWT.PublishJWKS {
+		return
+	}
+	r.GET(JWKSPath, JWKSHandler())
+}
+
+func tokenKeyID(token string) string {
+	msg, err := jws.Parse([]byte(token))
+	if err != nil || len(msg.Signatures()) == 0 {
+		return ""
+	}
+	return msg.Signatures()[0].ProtectedHeaders().KeyID()
+}
+
+func hasPrivateKey(keys []SigningKeyConfig) bool {
+	for _, kc := range keys {
+		if kc.PrivateKey != "" || kc.PrivateKeyFile != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// loadSigningKey 解析 PEM 密钥并设置 kid / alg；仅有公钥时 priv 为 nil。
+func loadSigningKey(kc SigningKeyConfig, defaultAlg string) (priv jwk.Key, pub jwk.Key, alg jwa.SignatureAlgorithm, err error) {
+	if kc.Kid == "" {
+		return nil, nil, "", fmt.Errorf("myAuth: auth.signedJwt.keys entry requires kid")
+	}
+	privPEM, err := readKeyMaterial(kc.PrivateKey, kc.PrivateKeyFile)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	pubPEM, err := readKeyMaterial(kc.PublicKey, kc.PublicKeyFile)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	switch {
+	case privPEM != nil:
+		priv, err = jwk.ParseKey(privPEM, jwk.WithPEM(true))
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("myAuth: parse private key %s: %w", kc.Kid, err)
+		}
+		pub, err = jwk.PublicKeyOf(priv)
+	case pubPEM != nil:
+		pub, err = jwk.ParseKey(pubPEM, jwk.WithPEM(true))
+	default:
+		return nil, nil, "", fmt.Errorf("myAuth: signing key %s has no key material", kc.Kid)
+	}
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("myAuth: parse public key %s: %w", kc.Kid, err)
+	}
+
+	name := kc.Algorithm
+	if name == "" {
+		name = defaultAlg
+	}
+	alg, err = signatureAlgorithm(name, pub)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("myAuth: signing key %s: %w", kc.Kid, err)
+	}
+	for _, k := range []jwk.Key{priv, pub} {
+		if k == nil {
+			continue
+		}
+		_ = k.Set(jwk.KeyIDKey, kc.Kid)
+		_ = k.Set(jwk.AlgorithmKey, alg)
+		_ = k.Set(jwk.KeyUsageKey, jwk.ForSignature)
+	}
+	return priv, pub, alg, nil
+}
+
+func readKeyMaterial(inline, file string) ([]byte, error) {
+	if inline = strings.TrimSpace(inline); inline != "" {
+		return []byte(inline), nil
+	}
+	if file = strings.TrimSpace(file); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("myAuth: read key file %s: %w", file, err)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
+
+// signatureAlgorithm 校验算法名与密钥类型匹配；name 为空时按密钥类型推断。
+func signatureAlgorithm(name string, key jwk.Key) (jwa.SignatureAlgorithm, error) {
+	var inferred jwa.SignatureAlgorithm
+	switch key.KeyType() {
+	case jwa.RSA:
+		inferred = jwa.RS256
+	case jwa.EC:
+		inferred = jwa.ES256
+	case jwa.OKP:
+		inferred = jwa.EdDSA
+	default:
+		return "", fmt.Errorf("unsupported key type %s", key.KeyType())
+	}
+	if name == "" {
+		return inferred, nil
+	}
+	alg := jwa.SignatureAlgorithm(name)
+	switch alg {
+	case jwa.RS256, jwa.ES256, jwa.EdDSA:
+	default:
+		return "", fmt.Errorf("unsupported algorithm %s (RS256, ES256, EdDSA)", name)
+	}
+	if alg != inferred {
+		return "", fmt.Errorf("algorithm %s does not match key type %s", name, key.KeyType())
+	}
+	return alg, nil
+}
@@ -0,0 +1,158 @@
+package myAuth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func pemPrivateKey(t *testing.T, key crypto.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func pemPublicKey(t *testing.T, key crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func signedTestConfig(sc SignedJWTConfig) Config {
+	return Config{
+		Provider:           ProviderSignedJWT,
+		TokenExpireSeconds: 3600,
+		JWT:                JWTConfig{Issuer: "test"},
+		SignedJWT:          sc,
+	}.withDefaults()
+}
+
+func TestSignedJWTAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	cases := map[string]crypto.PrivateKey{"RS256": rsaKey, "ES256": ecKey, "EdDSA": edKey}
+	for alg, key := range cases {
+		t.Run(alg, func(t *testing.T) {
+			p, err := newSignedJWTProvider(signedTestConfig(SignedJWTConfig{
+				Algorithm: alg,
+				Keys:      []SigningKeyConfig{{Kid: "k-" + alg, PrivateKey: pemPrivateKey(t, key)}},
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := p.Issue(context.Background(), &Claims{UserID: 21, Username: "signed"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kid := tokenKeyID(token); kid != "k-"+alg {
+				t.Fatalf("kid = %q", kid)
+			}
+			claims, err := p.Parse(context.Background(), token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.UserID != 21 || claims.Username != "signed" {
+				t.Fatalf("unexpected claims: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestSignedJWTKeyRotation(t *testing.T) {
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	newKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ctx := context.Background()
+
+	before, err := newSignedJWTProvider(signedTestConfig(SignedJWTConfig{
+		Keys: []SigningKeyConfig{{Kid: "2024", PrivateKey: pemPrivateKey(t, oldKey)}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := before.Issue(ctx, &Claims{UserID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := newSignedJWTProvider(signedTestConfig(SignedJWTConfig{
+		ActiveKid: "2025",
+		Keys: []SigningKeyConfig{
+			{Kid: "2024", PublicKey: pemPublicKey(t, &oldKey.PublicKey)},
+			{Kid: "2025", PrivateKey: pemPrivateKey(t, newKey)},
+		},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := after.Parse(ctx, oldToken); err != nil {
+		t.Fatalf("token signed by retiring key should verify: %v", err)
+	}
+	newToken, err := after.Issue(ctx, &Claims{UserID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokenKeyID(newToken) != "2025" {
+		t.Fatalf("new token kid = %q", tokenKeyID(newToken))
+	}
+	if _, err := before.Parse(ctx, newToken); err == nil {
+		t.Fatal("unknown kid should not verify")
+	}
+}
+
+func TestSignedJWTVerifyOnlyFromJWKSURL(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ctx := context.Background()
+
+	issuer, err := newSignedJWTProvider(signedTestConfig(SignedJWTConfig{
+		Keys: []SigningKeyConfig{{Kid: "ed", PrivateKey: pemPrivateKey(t, edKey)}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := json.Marshal(issuer.(*signedJWTProvider).PublicKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(jwks)
+	}))
+	defer srv.Close()
+
+	verifier, err := newSignedJWTProvider(signedTestConfig(SignedJWTConfig{JWKSURL: srv.URL}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := issuer.Issue(ctx, &Claims{UserID: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := verifier.Parse(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 3 {
+		t.Fatalf("userID = %d", claims.UserID)
+	}
+	if _, err := verifier.Issue(ctx, &Claims{UserID: 3}); err == nil {
+		t.Fatal("verify-only provider must not issue tokens")
+	}
+}
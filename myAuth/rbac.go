@@ -0,0 +1,374 @@
+package myAuth
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+)
+
+// GrantsExtraKey 已加载的 Grants 在 Session extras 中的 key。
+const GrantsExtraKey = "myAuth.grants"
+
+// Grants 会话的角色与权限（权限已按 auth.rbac.roles 展开）。
+type Grants struct {
+	Roles       []string
+	Permissions []string
+}
+
+// Has 判断是否具备权限，支持通配：order:* 覆盖 order:create、order:item:read；* 覆盖全部。
+func (g *Grants) Has(code string) bool {
+	if g == nil || code == "" {
+		return false
+	}
+	for _, grant := range g.Permissions {
+		if matchPermission(grant, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole 判断是否具备角色。
+func (g *Grants) HasRole(role string) bool {
+	if g == nil {
+		return false
+	}
+	for _, r := range g.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionLoader 加载会话的角色与权限（DB、配置或 enricher 写入的 extras）。
+type PermissionLoader func(ctx context.Context, sess *Session) (*Grants, error)
+
+var (
+	loaderMu         sync.RWMutex
+	permissionLoader PermissionLoader
+)
+
+// RegisterPermissionLoader 注册 PermissionLoader；未注册时从 Session extras 读取 roles / permissions。
+func RegisterPermissionLoader(fn PermissionLoader) {
+	loaderMu.Lock()
+	defer loaderMu.Unlock()
+	permissionLoader = fn
+	defaultGrantsCache.clear()
+}
+
+func currentPermissionLoader() PermissionLoader {
+	loaderMu.RLock()
+	defer loaderMu.RUnlock()
+	return permissionLoader
+}
+
+// LoadGrants 返回会话的 Grants；同一请求内缓存在 extras，跨请求按 jti 缓存 auth.rbac.cacheSeconds。
+func LoadGrants(ctx context.Context, sess *Session) (*Grants, error) {
+	if sess == nil {
+		return nil, nil
+	}
+	if v, ok := sess.Extra(GrantsExtraKey); ok {
+		if g, ok := v.(*Grants); ok {
+			return g, nil
+		}
+	}
+	if g, ok := defaultGrantsCache.get(sess.JTI); ok {
+		sess.SetExtra(GrantsExtraKey, g)
+		return g, nil
+	}
+
+	cfg := CurrentConfig().RBAC
+	loader := currentPermissionLoader()
+	if loader == nil {
+		loader = extrasPermissionLoader(cfg)
+	}
+	g, err := loader(ctx, sess)
+	if err != nil {
+		return nil, err
+	}
+	g = expandRoles(g, cfg.Roles)
+
+	expireAt := time.Now().Add(time.Duration(cfg.CacheSeconds) * time.Second)
+	if !sess.ExpireAt.IsZero() && sess.ExpireAt.Before(expireAt) {
+		expireAt = sess.ExpireAt
+	}
+	defaultGrantsCache.set(sess.JTI, g, expireAt)
+	sess.SetExtra(GrantsExtraKey, g)
+	return g, nil
+}
+
+// InvalidateGrants 清除指定会话的 Grants 缓存（角色变更后调用）；不传 jti 时清空全部。
+func InvalidateGrants(jtis ...string) {
+	if len(jtis) == 0 {
+		defaultGrantsCache.clear()
+		return
+	}
+	for _, jti := range jtis {
+		defaultGrantsCache.remove(jti)
+	}
+}
+
+// HasPermission 判断 context 中的会话是否具备权限（HTTP handler 传 c.Request.Context()）。
+func HasPermission(ctx context.Context, code string) bool {
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return false
+	}
+	g, err := LoadGrants(ctx, sess)
+	return err == nil && g.Has(code)
+}
+
+// RequirePermission 路由级权限中间件，需在 Required 之后使用；须满足全部 codes。
+func RequirePermission(codes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess, ok := GetSession(c)
+		if !ok || sess == nil {
+			abortWithError(c, myException.NewBizError(CodePermissionDenied, nil))
+			return
+		}
+		g, err := LoadGrants(c.Request.Context(), sess)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		for _, code := range codes {
+			if !g.Has(code) {
+				abortWithError(c, myException.NewBizError(CodePermissionDenied, nil))
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// extrasPermissionLoader 默认 loader：读取 enricher / OIDC 写入的 extras。
+func extrasPermissionLoader(cfg RBACConfig) PermissionLoader {
+	return func(_ context.Context, sess *Session) (*Grants, error) {
+		g := &Grants{}
+		if v, ok := sess.Extra(cfg.RolesExtraKey); ok {
+			g.Roles = toStringSlice(v)
+		}
+		if v, ok := sess.Extra(cfg.PermissionsExtraKey); ok {
+			g.Permissions = toStringSlice(v)
+		}
+		return g, nil
+	}
+}
+
+func expandRoles(g *Grants, roles map[string][]string) *Grants {
+	if g == nil {
+		g = &Grants{}
+	}
+	if len(roles) == 0 || len(g.Roles) == 0 {
+		return g
+	}
+	seen := make(map[string]struct{}, len(g.Permissions))
+	perms := make([]string, 0, len(g.Permissions))
+	add := func(p string) {
+		if _, ok := seen[p]; ok {
+			return
+		}
+		seen[p] = struct{}{}
+		perms = append(perms, p)
+	}
+	for _, p := range g.Permissions {
+		add(p)
+	}
+	for _, role := range g.Roles {
+		for _, p := range roles[role] {
+			add(p)
+		}
+	}
+	return &Grants{Roles: g.Roles, Permissions: perms}
+}
+
+func toStringSlice(v any) []string {
+	switch val := v.(type) {
+	case []string:
+		return val
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, item := range val {
+			if s := stringClaim(item); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		var out []string
+		for _, part := range strings.FieldsFunc(val, func(r rune) bool { return r == ',' || r == ' ' }) {
+			out = append(out, part)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// matchPermission 按 ":" 分段匹配；段 "*" 匹配单段，末段 "*" 匹配其后任意段。
+func matchPermission(grant, code string) bool {
+	if grant == code || grant == "*" {
+		return true
+	}
+	gSegs := strings.Split(grant, ":")
+	cSegs := strings.Split(code, ":")
+	for i, seg := range gSegs {
+		if seg == "*" && i == len(gSegs)-1 {
+			return len(cSegs) > i
+		}
+		if i >= len(cSegs) || (seg != "*" && seg != cSegs[i]) {
+			return false
+		}
+	}
+	return len(gSegs) == len(cSegs)
+}
+
+type grantsEntry struct {
+	grants   *Grants
+	expireAt time.Time
+}
+
+// grantsCache 进程内按 jti 缓存 Grants；jti 为空时不缓存。
+type grantsCache struct {
+	mu    sync.Mutex
+	items map[string]grantsEntry
+}
+
+var defaultGrantsCache = &grantsCache{items: make(map[string]grantsEntry)}
+
+func (c *grantsCache) get(jti string) (*Grants, bool) {
+	if jti == "" {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[jti]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expireAt) {
+		delete(c.items, jti)
+		return nil, false
+	}
+	return e.grants, true
+}
+
+func (c *grantsCache) set(jti string, g *Grants, expireAt time.Time) {
+	if jti == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, e := range c.items {
+		if now.After(e.expireAt) {
+			delete(c.items, k)
+		}
+	}
+	c.items[jti] = grantsEntry{grants: g, expireAt: expireAt}
+}
+
+func (c *grantsCache) remove(jti string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, jti)
+}
+
+func (c *grantsCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]grantsEntry)
+}
+
+// policyTable auth.rbac.policies 编译结果。
+type policyTable struct {
+	entries []policyEntry
+}
+
+type policyEntry struct {
+	matcher     *WhiteListMatcher
+	methods     map[string]struct{}
+	permissions []string
+	any         bool
+}
+
+func newPolicyTable(policies []PolicyConfig) *policyTable {
+	t := &policyTable{}
+	for _, p := range policies {
+		if p.Pattern == "" {
+			continue
+		}
+		entry := policyEntry{
+			matcher:     NewWhiteListMatcher([]string{p.Pattern}),
+			permissions: p.Permissions,
+			any:         p.Any,
+		}
+		if len(p.Methods) > 0 {
+			entry.methods = make(map[string]struct{}, len(p.Methods))
+			for _, m := range p.Methods {
+				entry.methods[strings.ToUpper(m)] = struct{}{}
+			}
+		}
+		t.entries = append(t.entries, entry)
+	}
+	return t
+}
+
+// lookup 返回首条命中的策略；method 为空（gRPC）时忽略 Methods 限制。
+func (t *policyTable) lookup(path, method string) *policyEntry {
+	if t == nil {
+		return nil
+	}
+	for i := range t.entries {
+		e := &t.entries[i]
+		if method != "" && e.methods != nil {
+			if _, ok := e.methods[strings.ToUpper(method)]; !ok {
+				continue
+			}
+		}
+		if e.matcher.Match(path) {
+			return e
+		}
+	}
+	return nil
+}
+
+func (e *policyEntry) allow(g *Grants) bool {
+	if len(e.permissions) == 0 {
+		return true
+	}
+	for _, code := range e.permissions {
+		has := g.Has(code)
+		if e.any && has {
+			return true
+		}
+		if !e.any && !has {
+			return false
+		}
+	}
+	return !e.any
+}
+
+// authorize 按策略表校验；无策略命中返回 nil，未登录返回 missingCode。
+func (m *manager) authorize(ctx context.Context, sess *Session, path, method, missingCode string) error {
+	policy := m.policies.lookup(path, method)
+	if policy == nil {
+		return nil
+	}
+	if sess == nil {
+		return myException.NewBizError(missingCode, nil)
+	}
+	g, err := LoadGrants(ctx, sess)
+	if err != nil {
+		return err
+	}
+	if !policy.allow(g) {
+		return myException.NewBizError(CodePermissionDenied, nil)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package myAuth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMatchPermission(t *testing.T) {
+	cases := []struct {
+		grant, code string
+		want        bool
+	}{
+		{"order:create", "order:create", true},
+		{"order:*", "order:create", true},
+		{"order:*", "order:item:read", true},
+		{"order:*", "order", false},
+		{"order:*:read", "order:item:read", true},
+		{"order:*:read", "order:item:write", false},
+		{"*", "user:delete", true},
+		{"order:create", "order:create:draft", false},
+		{"user:*", "order:create", false},
+	}
+	for _, tc := range cases {
+		if got := matchPermission(tc.grant, tc.code); got != tc.want {
+			t.Errorf("matchPermission(%q, %q) = %v, want %v", tc.grant, tc.code, got, tc.want)
+		}
+	}
+}
+
+func rbacTestConfig() Config {
+	return Config{
+		Provider:           ProviderEncryptedJWT,
+		TokenExpireSeconds: 3600,
+		JWT:                JWTConfig{Key: testJWTKey(), Issuer: "test"},
+		RBAC: RBACConfig{
+			Roles: map[string][]string{"clerk": {"order:read"}, "admin": {"order:*"}},
+			Policies: []PolicyConfig{
+				{Pattern: "/api/orders/**", Methods: []string{"DELETE"}, Permissions: []string{"order:delete"}},
+				{Pattern: "/api/orders/**", Permissions: []string{"order:read"}},
+				{Pattern: "/order.OrderService/*", Permissions: []string{"order:delete", "order:refund"}, Any: true},
+			},
+		},
+	}
+}
+
+func useRoleLoader(t *testing.T, roles map[int64][]string) {
+	t.Helper()
+	RegisterPermissionLoader(func(_ context.Context, sess *Session) (*Grants, error) {
+		return &Grants{Roles: roles[sess.UserID]}, nil
+	})
+	t.Cleanup(func() { RegisterPermissionLoader(nil) })
+}
+
+func TestRBACRoutePolicies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	initTestAuth(t, rbacTestConfig())
+	useRoleLoader(t, map[int64][]string{1: {"clerk"}, 2: {"admin"}})
+	ctx := context.Background()
+	_, clerk, _ := Manager().Create(ctx, &SessionInput{UserID: 1})
+	_, admin, _ := Manager().Create(ctx, &SessionInput{UserID: 2})
+
+	r := gin.New()
+	r.Use(Optional())
+	r.Any("/api/orders/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	cases := []struct {
+		method, token string
+		want          bool
+	}{
+		{http.MethodGet, clerk, true},
+		{http.MethodDelete, clerk, false},
+		{http.MethodDelete, admin, true},
+		{http.MethodGet, "", false},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, "/api/orders/9", nil)
+		if tc.token != "" {
+			req.Header.Set(myContext.HeaderToken, tc.token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if got := w.Code == http.StatusNoContent; got != tc.want {
+			t.Errorf("%s with token=%v: allowed=%v, want %v", tc.method, tc.token != "", got, tc.want)
+		}
+	}
+}
+
+func TestGRPCAuthInterceptorAppliesPolicies(t *testing.T) {
+	initTestAuth(t, rbacTestConfig())
+	useRoleLoader(t, map[int64][]string{1: {"clerk"}, 2: {"admin"}})
+	_, clerk, _ := Manager().Create(context.Background(), &SessionInput{UserID: 1})
+	_, admin, _ := Manager().Create(context.Background(), &SessionInput{UserID: 2})
+
+	interceptor := GRPCAuthInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/order.OrderService/Refund"}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	call := func(token string) error {
+		ctx := context.Background()
+		if token != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(myContext.HeaderToken, token))
+		}
+		_, err := interceptor(ctx, nil, info, handler)
+		return err
+	}
+
+	if err := call(admin); err != nil {
+		t.Fatalf("admin should pass: %v", err)
+	}
+	if code := status.Code(call(clerk)); code != codes.PermissionDenied {
+		t.Fatalf("clerk: code = %v", code)
+	}
+	if code := status.Code(call("")); code != codes.Unauthenticated {
+		t.Fatalf("anonymous: code = %v", code)
+	}
+}
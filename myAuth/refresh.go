@@ -0,0 +1,294 @@
+package myAuth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure"
+)
+
+var (
+	// ErrRefreshTokenInvalid refresh token 不存在、已过期或所属家族已被吊销。
+	ErrRefreshTokenInvalid = errors.New("myAuth: refresh token invalid")
+	// ErrRefreshTokenReused 已轮换的 refresh token 被再次使用，整个家族已被吊销。
+	ErrRefreshTokenReused = errors.New("myAuth: refresh token reused")
+	// ErrRefreshDisabled 未启用 auth.refresh。
+	ErrRefreshDisabled = errors.New("myAuth: refresh token is disabled")
+)
+
+// refreshRecord 一枚 refresh token 的存储内容；FamilyID 串联同一次登录派生的全部令牌。
+// 轮换时沿用登录时的 access 有效期与 extras，ExpireAt 为家族的绝对过期时间，不随轮换延长。
+type refreshRecord struct {
+	UserID      int64          `json:"userId"`
+	Username    string         `json:"username"`
+	DisplayName string         `json:"displayName"`
+	FamilyID    string         `json:"familyId"`
+	AccessJTI   string         `json:"accessJti"`
+	AccessTTL   time.Duration  `json:"accessTtl,omitempty"`
+	Extras      map[string]any `json:"extras,omitempty"`
+	ExpireAt    time.Time      `json:"expireAt"`
+}
+
+// redisRefreshStore Redis 刷新令牌存储。
+//
+//	prefix + token            → refreshRecord（TTL = refresh 有效期）
+//	prefix + "used:" + token  → 轮换标记（SETNX，用于复用检测）
+//	familyPrefix + family     → 家族内已签发的 access JTI 集合
+//	familyPrefix + family + ":revoked" → 家族吊销标记
+//	familyPrefix + "jti:" + jti → family（登出时按 access token 定位家族）
+type redisRefreshStore struct {
+	prefix       string
+	familyPrefix string
+	ttl          time.Duration
+}
+
+func newRedisRefreshStore(cfg RefreshConfig, ttl time.Duration) *redisRefreshStore {
+	return &redisRefreshStore{
+		prefix:       cfg.KeyPrefix,
+		familyPrefix: cfg.FamilyPrefix,
+		ttl:          ttl,
+	}
+}
+
+func (s *redisRefreshStore) client() (*redis.Client, error) {
+	client := infrastructure.GetRedis()
+	if client == nil {
+		return nil, fmt.Errorf("myAuth: redis is not initialized")
+	}
+	return client, nil
+}
+
+// Issue 在 family 下签发新 refresh token 并登记 access JTI；新家族的 ExpireAt 为 now + ttl，
+// 轮换时调用方传入原家族的 ExpireAt，各键的 TTL 为剩余时长。
+func (s *redisRefreshStore) Issue(ctx context.Context, rec *refreshRecord) (string, error) {
+	client, err := s.client()
+	if err != nil {
+		return "", err
+	}
+	if rec.FamilyID == "" {
+		rec.FamilyID = uuid.NewString()
+	}
+	if rec.ExpireAt.IsZero() {
+		rec.ExpireAt = time.Now().Add(s.ttl)
+	}
+	remain := time.Until(rec.ExpireAt)
+	if remain <= 0 {
+		return "", ErrRefreshTokenInvalid
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+
+	token := uuid.NewString()
+	familyKey := s.familyPrefix + rec.FamilyID
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.prefix+token, data, remain)
+		if rec.AccessJTI != "" {
+			pipe.SAdd(ctx, familyKey, rec.AccessJTI)
+			pipe.Set(ctx, s.familyPrefix+"jti:"+rec.AccessJTI, rec.FamilyID, remain)
+		}
+		pipe.Expire(ctx, familyKey, remain)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *redisRefreshStore) Get(ctx context.Context, token string) (*refreshRecord, error) {
+	client, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	data, err := client.Get(ctx, s.prefix+token).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec refreshRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// MarkUsed 标记 token 已轮换；返回 false 表示此前已被使用过（复用）。
+func (s *redisRefreshStore) MarkUsed(ctx context.Context, token string) (bool, error) {
+	client, err := s.client()
+	if err != nil {
+		return false, err
+	}
+	return client.SetNX(ctx, s.prefix+"used:"+token, "1", s.ttl).Result()
+}
+
+func (s *redisRefreshStore) FamilyRevoked(ctx context.Context, family string) (bool, error) {
+	client, err := s.client()
+	if err != nil {
+		return false, err
+	}
+	n, err := client.Exists(ctx, s.familyPrefix+family+":revoked").Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// FamilyOf 根据 access JTI 查找所属家族，未登记时返回空串。
+func (s *redisRefreshStore) FamilyOf(ctx context.Context, jti string) (string, error) {
+	if jti == "" {
+		return "", nil
+	}
+	client, err := s.client()
+	if err != nil {
+		return "", err
+	}
+	family, err := client.Get(ctx, s.familyPrefix+"jti:"+jti).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return family, err
+}
+
+// RevokeFamily 写入家族吊销标记并返回家族内全部 access JTI，由调用方逐个吊销。
+func (s *redisRefreshStore) RevokeFamily(ctx context.Context, family string) ([]string, error) {
+	client, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	familyKey := s.familyPrefix + family
+	jtis, err := client.SMembers(ctx, familyKey).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, familyKey+":revoked", "1", s.ttl)
+		pipe.Del(ctx, familyKey)
+		for _, jti := range jtis {
+			pipe.Del(ctx, s.familyPrefix+"jti:"+jti)
+		}
+		return nil
+	})
+	return jtis, err
+}
+
+// issueRefresh 签发 refresh token；prev 为轮换前的记录，新登录时为 nil。
+func (m *manager) issueRefresh(ctx context.Context, claims *Claims, accessTTL time.Duration, prev *refreshRecord, sess *Session) error {
+	if m.refresh == nil {
+		return nil
+	}
+	rec := &refreshRecord{
+		UserID:      claims.UserID,
+		Username:    claims.Username,
+		DisplayName: claims.DisplayName,
+		AccessJTI:   claims.JTI,
+		AccessTTL:   accessTTL,
+		Extras:      claims.Extras,
+	}
+	if prev != nil {
+		rec.FamilyID = prev.FamilyID
+		rec.ExpireAt = prev.ExpireAt
+	}
+	token, err := m.refresh.Issue(ctx, rec)
+	if err != nil {
+		return err
+	}
+	sess.RefreshToken = token
+	sess.RefreshExpireAt = rec.ExpireAt
+	return nil
+}
+
+// Refresh 用 refresh token 换取新的 access token 与 refresh token（轮换）。
+// 已轮换的 refresh token 再次出现视为泄露，吊销整个家族并返回 ErrRefreshTokenReused。
+func (m *manager) Refresh(ctx context.Context, refreshToken string) (*Session, string, error) {
+	if m.refresh == nil {
+		return nil, "", ErrRefreshDisabled
+	}
+	refreshToken = NormalizeToken(refreshToken)
+	if refreshToken == "" {
+		return nil, "", ErrRefreshTokenInvalid
+	}
+
+	rec, err := m.refresh.Get(ctx, refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+	if rec == nil || time.Now().After(rec.ExpireAt) {
+		return nil, "", ErrRefreshTokenInvalid
+	}
+	if revoked, err := m.refresh.FamilyRevoked(ctx, rec.FamilyID); err != nil {
+		return nil, "", err
+	} else if revoked {
+		return nil, "", ErrRefreshTokenInvalid
+	}
+
+	fresh, err := m.refresh.MarkUsed(ctx, refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+	if !fresh {
+		if err := m.revokeFamily(ctx, rec.UserID, rec.FamilyID); err != nil {
+			return nil, "", err
+		}
+		return nil, "", ErrRefreshTokenReused
+	}
+
+	// 沿用登录时的 access 有效期（早于该字段写入的记录按全局 ttl），且不超过家族的绝对过期时间
+	accessTTL := rec.AccessTTL
+	if accessTTL <= 0 {
+		accessTTL = m.cfg.tokenTTL()
+	}
+	expireAt := time.Now().Add(accessTTL)
+	if expireAt.After(rec.ExpireAt) {
+		expireAt = rec.ExpireAt
+	}
+	claims := &Claims{
+		UserID:      rec.UserID,
+		Username:    rec.Username,
+		DisplayName: rec.DisplayName,
+		ExpireAt:    expireAt,
+		Extras:      rec.Extras,
+	}
+	token, err := m.provider.Issue(ctx, claims)
+	if err != nil {
+		return nil, "", err
+	}
+	sess := claimsToSession(claims)
+	sess.Token = token
+	if err := m.issueRefresh(ctx, claims, accessTTL, rec, sess); err != nil {
+		return nil, "", err
+	}
+	// 同一家族在索引中只占一个会话名额：以新 access token 替换旧条目。
+	if err := m.index.Remove(ctx, rec.UserID, rec.AccessJTI); err != nil {
+		return nil, "", err
+	}
+	if err := m.indexSession(ctx, claims); err != nil {
+		return nil, "", err
+	}
+	return sess, token, nil
+}
+
+func (m *manager) revokeFamily(ctx context.Context, userID int64, family string) error {
+	jtis, err := m.refresh.RevokeFamily(ctx, family)
+	if err != nil {
+		return err
+	}
+	if err := m.index.Remove(ctx, userID, jtis...); err != nil {
+		return err
+	}
+	expireAt := time.Now().Add(m.cfg.tokenTTL())
+	for _, jti := range jtis {
+		if err := m.provider.Revoke(ctx, &Claims{UserID: userID, JTI: jti, ExpireAt: expireAt}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
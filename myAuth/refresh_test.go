@@ -0,0 +1,252 @@
+package myAuth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+)
+
+func useTestRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	prev := infrastructure.RedisClient
+	infrastructure.RedisClient = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = infrastructure.RedisClient.Close()
+		infrastructure.RedisClient = prev
+	})
+	return mr
+}
+
+func initTestAuth(t *testing.T, cfg Config) {
+	t.Helper()
+	cfgMu.Lock()
+	prevInit := initialized
+	prevCfg := globalCfg
+	prevMgr := globalManager
+	cfgMu.Unlock()
+
+	providerMu.Lock()
+	prevProviders := make(map[string]TokenProvider, len(providers))
+	for k, v := range providers {
+		prevProviders[k] = v
+	}
+	providerMu.Unlock()
+
+	t.Cleanup(func() {
+		cfgMu.Lock()
+		initialized = prevInit
+		globalCfg = prevCfg
+		globalManager = prevMgr
+		cfgMu.Unlock()
+
+		providerMu.Lock()
+		providers = prevProviders
+		providerMu.Unlock()
+	})
+
+	if err := Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshRotationAndReuseDetection(t *testing.T) {
+	useTestRedis(t)
+	initTestAuth(t, Config{
+		Provider:           ProviderEncryptedJWT,
+		TokenExpireSeconds: 3600,
+		JWT:                JWTConfig{Key: testJWTKey(), Issuer: "test"},
+		Refresh:            RefreshConfig{Enabled: true},
+	})
+	ctx := context.Background()
+
+	sess, token, err := Manager().Create(ctx, &SessionInput{UserID: 7, Username: "u7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess.RefreshToken == "" {
+		t.Fatal("refresh token not issued")
+	}
+
+	rotated, newToken, err := Manager().Refresh(ctx, sess.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newToken == "" || rotated.RefreshToken == "" || rotated.RefreshToken == sess.RefreshToken {
+		t.Fatalf("refresh did not rotate: %+v", rotated)
+	}
+	if rotated.UserID != 7 || rotated.Username != "u7" {
+		t.Fatalf("unexpected refreshed session: %+v", rotated)
+	}
+
+	if _, _, err := Manager().Refresh(ctx, sess.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse err = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, err := Manager().Refresh(ctx, rotated.RefreshToken); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("family member err = %v, want ErrRefreshTokenInvalid", err)
+	}
+	for _, tok := range []string{token, newToken} {
+		if _, err := Manager().LoadFromToken(ctx, tok, ""); err == nil {
+			t.Fatal("access token of revoked family still valid")
+		}
+	}
+}
+
+func TestRefreshHandlerErrorCodes(t *testing.T) {
+	mr := useTestRedis(t)
+	initTestAuth(t, Config{
+		Provider:           ProviderRedisOpaque,
+		TokenExpireSeconds: 3600,
+		Refresh:            RefreshConfig{Enabled: true},
+	})
+	sess, _, err := Manager().Create(context.Background(), &SessionInput{UserID: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	var lastErr error
+	engine.Use(func(c *gin.Context) {
+		c.Next()
+		if e := c.Errors.Last(); e != nil {
+			lastErr = e.Err
+		}
+	})
+	RegisterRoutes(&engine.RouterGroup)
+	refresh := func(token string) string {
+		lastErr = nil
+		req := httptest.NewRequest(http.MethodPost, "/v1/auth/refresh", nil)
+		req.Header.Set(HeaderRefreshToken, token)
+		engine.ServeHTTP(httptest.NewRecorder(), req)
+		return myException.GetErrorCode(lastErr)
+	}
+
+	if code := refresh("unknown"); code != CodeRefreshTokenInvalid {
+		t.Fatalf("unknown token code = %s", code)
+	}
+	// Redis 故障不能让客户端误以为 refresh token 已失效
+	mr.SetError("LOADING")
+	if code := refresh(sess.RefreshToken); code == CodeRefreshTokenInvalid || lastErr == nil {
+		t.Fatalf("redis outage code = %s, err = %v", code, lastErr)
+	}
+	mr.SetError("")
+	if code := refresh(sess.RefreshToken); lastErr != nil {
+		t.Fatalf("refresh after recovery code = %s, err = %v", code, lastErr)
+	}
+	if code := refresh(sess.RefreshToken); code != CodeRefreshTokenInvalid {
+		t.Fatalf("reused token code = %s", code)
+	}
+}
+
+func TestDestroyRevokesRefreshFamily(t *testing.T) {
+	useTestRedis(t)
+	initTestAuth(t, Config{
+		Provider:           ProviderRedisOpaque,
+		TokenExpireSeconds: 3600,
+		Refresh:            RefreshConfig{Enabled: true},
+	})
+	ctx := context.Background()
+
+	sess, token, err := Manager().Create(ctx, &SessionInput{UserID: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Manager().Destroy(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Manager().Refresh(ctx, sess.RefreshToken); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("refresh after logout err = %v", err)
+	}
+}
+
+func TestRefreshKeepsSessionTTLAndFamilyExpiry(t *testing.T) {
+	mr := useTestRedis(t)
+	initTestAuth(t, Config{
+		Provider:           ProviderRedisOpaque,
+		TokenExpireSeconds: 3600,
+		Refresh:            RefreshConfig{Enabled: true, ExpireSeconds: 7200},
+	})
+	ctx := context.Background()
+
+	sess, _, err := Manager().Create(ctx, &SessionInput{UserID: 10, TTL: 10 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 模拟登录时写入的 extras
+	store := Manager().(*manager).refresh
+	rec, err := store.Get(ctx, sess.RefreshToken)
+	if err != nil || rec == nil {
+		t.Fatalf("load refresh record: %v", err)
+	}
+	rec.Extras = map[string]any{ExtraTenantId: "t-1"}
+	data, _ := json.Marshal(rec)
+	mr.Set(store.prefix+sess.RefreshToken, string(data))
+
+	mr.FastForward(time.Minute)
+	rotated, _, err := Manager().Refresh(ctx, sess.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remain := time.Until(rotated.ExpireAt); remain > 11*time.Minute {
+		t.Fatalf("refreshed access token uses global ttl: %v", remain)
+	}
+	if !rotated.RefreshExpireAt.Equal(sess.RefreshExpireAt) {
+		t.Fatalf("family expiry extended: %v -> %v", sess.RefreshExpireAt, rotated.RefreshExpireAt)
+	}
+	if v, _ := rotated.Extra(ExtraTenantId); v != "t-1" {
+		t.Fatalf("extras dropped on refresh: %v", v)
+	}
+	if ttl := mr.TTL(store.prefix + rotated.RefreshToken); ttl > time.Until(sess.RefreshExpireAt)+time.Second {
+		t.Fatalf("rotated refresh token ttl %v exceeds family expiry", ttl)
+	}
+}
+
+func TestRedisOpaqueSlidingExpiry(t *testing.T) {
+	mr := useTestRedis(t)
+	initTestAuth(t, Config{
+		Provider:           ProviderRedisOpaque,
+		TokenExpireSeconds: 3600,
+		Session:            SessionStoreConfig{Sliding: true, SlidingIntervalSeconds: 60},
+	})
+	ctx := context.Background()
+
+	_, token, err := Manager().Create(ctx, &SessionInput{UserID: 9, TTL: 10 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 模拟 5 分钟后访问：会话剩余 5 分钟
+	store := Manager().(*manager).provider.(*redisOpaqueProvider).store
+	stored, err := store.Get(ctx, token)
+	if err != nil || stored == nil {
+		t.Fatalf("load stored session: %v", err)
+	}
+	stored.ExpireAt = time.Now().Add(5 * time.Minute)
+	if err := store.Save(ctx, token, stored); err != nil {
+		t.Fatal(err)
+	}
+	key := defaultSessionKeyPrefix + token
+	before := mr.TTL(key)
+
+	sess, err := Manager().LoadFromToken(ctx, token, "")
+	if err != nil || sess == nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if after := mr.TTL(key); after <= before {
+		t.Fatalf("ttl not extended: before=%v after=%v", before, after)
+	}
+	// 按会话自身的 10 分钟续期，而非全局 1 小时
+	if remain := time.Until(sess.ExpireAt); remain < 9*time.Minute || remain > 11*time.Minute {
+		t.Fatalf("expireAt slid by wrong lifetime: %v", remain)
+	}
+}
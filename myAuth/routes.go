@@ -0,0 +1,92 @@
+package myAuth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
+)
+
+// HeaderRefreshToken 刷新令牌请求头（亦可放在 JSON body 的 refreshToken 字段）。
+const HeaderRefreshToken = "x-refresh-token"
+
+// TokenResult 签发 / 刷新接口的返回体。
+type TokenResult struct {
+	Token           string    `json:"token"`
+	ExpireAt        time.Time `json:"expireAt"`
+	RefreshToken    string    `json:"refreshToken,omitempty"`
+	RefreshExpireAt time.Time `json:"refreshExpireAt"`
+}
+
+// NewTokenResult 由 Session 构建返回体，登录接口可直接复用。
+func NewTokenResult(sess *Session) TokenResult {
+	if sess == nil {
+		return TokenResult{}
+	}
+	return TokenResult{
+		Token:           sess.Token,
+		ExpireAt:        sess.ExpireAt,
+		RefreshToken:    sess.RefreshToken,
+		RefreshExpireAt: sess.RefreshExpireAt,
+	}
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RegisterRoutes 挂载刷新与登出接口（/v1/auth/refresh、/v1/auth/logout）。
+// refresh 不携带 access token，所在路由组若挂了 Required，需将其加入白名单。
+func RegisterRoutes(group *gin.RouterGroup) {
+	auth := group.Group("/v1/auth")
+	{
+		auth.POST("/refresh", RefreshHandler())
+		auth.POST("/logout", LogoutHandler())
+	}
+}
+
+// RefreshHandler 用 refresh token 换取新令牌对。
+func RefreshHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := NormalizeToken(c.GetHeader(HeaderRefreshToken))
+		if token == "" {
+			var req refreshRequest
+			_ = c.ShouldBindJSON(&req)
+			token = NormalizeToken(req.RefreshToken)
+		}
+		if token == "" {
+			abortWithError(c, myException.NewBizError(CodeRefreshTokenInvalid, nil))
+			return
+		}
+
+		sess, _, err := Manager().Refresh(c.Request.Context(), token)
+		if errors.Is(err, ErrRefreshTokenInvalid) || errors.Is(err, ErrRefreshTokenReused) {
+			abortWithError(c, myException.NewBizError(CodeRefreshTokenInvalid, nil))
+			return
+		}
+		if err != nil {
+			// Redis 等故障不能让客户端丢弃仍有效的 refresh token
+			abortWithError(c, err)
+			return
+		}
+		myResult.Success(c, NewTokenResult(sess))
+	}
+}
+
+// LogoutHandler 吊销当前 access token 及其 refresh 家族。
+func LogoutHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := Token(c)
+		if token == "" {
+			abortWithError(c, myException.NewBizError(CodeTokenMissing, nil))
+			return
+		}
+		if err := Manager().Destroy(c.Request.Context(), token); err != nil {
+			abortWithError(c, myException.NewBizError(CodeTokenInvalid, nil))
+			return
+		}
+		myResult.Success(c, nil)
+	}
+}
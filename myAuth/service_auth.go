@@ -0,0 +1,385 @@
+package myAuth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure"
+)
+
+// HMAC 签名请求头。
+const (
+	HeaderAuthKeyID     = "x-auth-key-id"
+	HeaderAuthTimestamp = "x-auth-timestamp"
+	HeaderAuthNonce     = "x-auth-nonce"
+	HeaderAuthSignature = "x-auth-signature"
+)
+
+// ServiceAuthenticator 服务间凭证校验；请求未携带该类凭证时返回 (nil, nil)，交由后续方式处理。
+type ServiceAuthenticator interface {
+	Name() string
+	Authenticate(c *gin.Context) (*Session, error)
+}
+
+// WithServiceAuth 在 token 鉴权前尝试服务凭证，命中则以服务主体 Session 放行。
+func WithServiceAuth(auths ...ServiceAuthenticator) Option {
+	return func(o *middlewareOptions) {
+		o.serviceAuth = append(o.serviceAuth, auths...)
+	}
+}
+
+// WithAPIKey 启用 api key 鉴权；store 为 nil 时使用 Redis 存储。
+func WithAPIKey(store APIKeyStore) Option {
+	return WithServiceAuth(NewAPIKeyAuthenticator(store))
+}
+
+// WithHMAC 启用 HMAC 签名鉴权；store 为 nil 时使用 auth.service.hmacKeys。
+func WithHMAC(store HMACKeyStore) Option {
+	return WithServiceAuth(NewHMACAuthenticator(store))
+}
+
+func authenticateService(c *gin.Context, auths []ServiceAuthenticator) (*Session, error) {
+	for _, a := range auths {
+		sess, err := a.Authenticate(c)
+		if err != nil {
+			return nil, fmt.Errorf("myAuth: %s: %w", a.Name(), err)
+		}
+		if sess != nil {
+			return sess, nil
+		}
+	}
+	return nil, nil
+}
+
+// newServiceSession 服务主体 Session：无 UserID / token，scopes 直接作为 RBAC 权限。
+func newServiceSession(id, name string, scopes []string, expireAt time.Time) *Session {
+	sess := &Session{
+		Username:  name,
+		ExpireAt:  expireAt,
+		Principal: PrincipalService,
+		ServiceID: id,
+	}
+	sess.SetExtra(GrantsExtraKey, &Grants{Permissions: scopes})
+	return sess
+}
+
+// APIKey api key 元数据；明文 key 不落库，仅存 SHA-256 摘要。
+type APIKey struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Scopes   []string  `json:"scopes"`
+	ExpireAt time.Time `json:"expireAt"`
+	Disabled bool      `json:"disabled"`
+}
+
+// APIKeyStore 按摘要查询 api key，未找到返回 (nil, nil)。
+type APIKeyStore interface {
+	FindAPIKey(ctx context.Context, hash string) (*APIKey, error)
+}
+
+// APIKeyStoreFunc 函数式 APIKeyStore，便于接入 DB 查询。
+type APIKeyStoreFunc func(ctx context.Context, hash string) (*APIKey, error)
+
+func (f APIKeyStoreFunc) FindAPIKey(ctx context.Context, hash string) (*APIKey, error) {
+	return f(ctx, hash)
+}
+
+// HashAPIKey 返回 api key 的 SHA-256 十六进制摘要。
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey 生成随机 api key，返回明文（仅展示一次）与存储用摘要。
+func GenerateAPIKey(prefix string) (plain string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	plain = prefix + base64.RawURLEncoding.EncodeToString(buf)
+	return plain, HashAPIKey(plain), nil
+}
+
+// RedisAPIKeyStore prefix + hash → APIKey JSON。
+type RedisAPIKeyStore struct {
+	prefix string
+}
+
+// NewRedisAPIKeyStore prefix 为空时使用 auth.service.apiKeyPrefix。
+func NewRedisAPIKeyStore(prefix string) *RedisAPIKeyStore {
+	return &RedisAPIKeyStore{prefix: prefix}
+}
+
+func (s *RedisAPIKeyStore) key(hash string) string {
+	prefix := s.prefix
+	if prefix == "" {
+		prefix = CurrentConfig().Service.APIKeyPrefix
+	}
+	return prefix + hash
+}
+
+func (s *RedisAPIKeyStore) FindAPIKey(ctx context.Context, hash string) (*APIKey, error) {
+	client := infrastructure.GetRedis()
+	if client == nil {
+		return nil, fmt.Errorf("myAuth: redis is not initialized")
+	}
+	data, err := client.Get(ctx, s.key(hash)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var key APIKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Save 保存 api key；设置了 ExpireAt 时 Redis 同步过期。
+func (s *RedisAPIKeyStore) Save(ctx context.Context, hash string, key *APIKey) error {
+	client := infrastructure.GetRedis()
+	if client == nil {
+		return fmt.Errorf("myAuth: redis is not initialized")
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	var ttl time.Duration
+	if !key.ExpireAt.IsZero() {
+		if ttl = time.Until(key.ExpireAt); ttl <= 0 {
+			return fmt.Errorf("myAuth: api key %s already expired", key.ID)
+		}
+	}
+	return client.Set(ctx, s.key(hash), data, ttl).Err()
+}
+
+// Delete 吊销 api key。
+func (s *RedisAPIKeyStore) Delete(ctx context.Context, hash string) error {
+	client := infrastructure.GetRedis()
+	if client == nil {
+		return fmt.Errorf("myAuth: redis is not initialized")
+	}
+	return client.Del(ctx, s.key(hash)).Err()
+}
+
+type apiKeyAuthenticator struct {
+	store APIKeyStore
+}
+
+// NewAPIKeyAuthenticator 读取 auth.service.apiKeyHeader 或 Authorization: ApiKey <key>。
+func NewAPIKeyAuthenticator(store APIKeyStore) ServiceAuthenticator {
+	if store == nil {
+		store = NewRedisAPIKeyStore("")
+	}
+	return &apiKeyAuthenticator{store: store}
+}
+
+func (a *apiKeyAuthenticator) Name() string { return "api_key" }
+
+func (a *apiKeyAuthenticator) Authenticate(c *gin.Context) (*Session, error) {
+	plain := strings.TrimSpace(c.GetHeader(CurrentConfig().Service.APIKeyHeader))
+	if plain == "" {
+		if auth := c.GetHeader("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "ApiKey ") {
+			plain = strings.TrimSpace(auth[7:])
+		}
+	}
+	if plain == "" {
+		return nil, nil
+	}
+	key, err := a.store.FindAPIKey(c.Request.Context(), HashAPIKey(plain))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.Disabled {
+		return nil, fmt.Errorf("unknown or disabled key")
+	}
+	if !key.ExpireAt.IsZero() && time.Now().After(key.ExpireAt) {
+		return nil, fmt.Errorf("key %s expired", key.ID)
+	}
+	return newServiceSession(key.ID, key.Name, key.Scopes, key.ExpireAt), nil
+}
+
+// HMACKey HMAC 共享密钥；Secret 需可还原，勿与 api key 一样只存摘要。
+type HMACKey struct {
+	KeyID    string    `mapstructure:"keyId" json:"keyId"`
+	Secret   string    `mapstructure:"secret" json:"secret"`
+	Name     string    `mapstructure:"name" json:"name"`
+	Scopes   []string  `mapstructure:"scopes" json:"scopes"`
+	ExpireAt time.Time `mapstructure:"expireAt" json:"expireAt"`
+	Disabled bool      `mapstructure:"disabled" json:"disabled"`
+}
+
+// HMACKeyStore 按 key id 查询密钥，未找到返回 (nil, nil)。
+type HMACKeyStore interface {
+	FindHMACKey(ctx context.Context, keyID string) (*HMACKey, error)
+}
+
+// HMACKeyStoreFunc 函数式 HMACKeyStore。
+type HMACKeyStoreFunc func(ctx context.Context, keyID string) (*HMACKey, error)
+
+func (f HMACKeyStoreFunc) FindHMACKey(ctx context.Context, keyID string) (*HMACKey, error) {
+	return f(ctx, keyID)
+}
+
+// StaticHMACKeyStore 固定密钥表（配置文件或测试）。
+func StaticHMACKeyStore(keys ...HMACKey) HMACKeyStore {
+	byID := make(map[string]HMACKey, len(keys))
+	for _, k := range keys {
+		byID[k.KeyID] = k
+	}
+	return HMACKeyStoreFunc(func(_ context.Context, keyID string) (*HMACKey, error) {
+		k, ok := byID[keyID]
+		if !ok {
+			return nil, nil
+		}
+		return &k, nil
+	})
+}
+
+type hmacAuthenticator struct {
+	store HMACKeyStore
+}
+
+// NewHMACAuthenticator 校验 SignRequest 生成的签名头。
+func NewHMACAuthenticator(store HMACKeyStore) ServiceAuthenticator {
+	return &hmacAuthenticator{store: store}
+}
+
+func (a *hmacAuthenticator) Name() string { return "hmac" }
+
+func (a *hmacAuthenticator) Authenticate(c *gin.Context) (*Session, error) {
+	keyID := c.GetHeader(HeaderAuthKeyID)
+	signature := c.GetHeader(HeaderAuthSignature)
+	if keyID == "" && signature == "" {
+		return nil, nil
+	}
+	ts := c.GetHeader(HeaderAuthTimestamp)
+	nonce := c.GetHeader(HeaderAuthNonce)
+	if keyID == "" || signature == "" || ts == "" || nonce == "" {
+		return nil, fmt.Errorf("incomplete signature headers")
+	}
+
+	cfg := CurrentConfig().Service
+	skew := time.Duration(cfg.SignatureSkewSeconds) * time.Second
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp")
+	}
+	if d := time.Since(time.Unix(sec, 0)); d > skew || d < -skew {
+		return nil, fmt.Errorf("timestamp outside %s window", skew)
+	}
+
+	store := a.store
+	if store == nil {
+		store = StaticHMACKeyStore(cfg.HMACKeys...)
+	}
+	ctx := c.Request.Context()
+	key, err := store.FindHMACKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.Disabled {
+		return nil, fmt.Errorf("unknown or disabled key %s", keyID)
+	}
+	if !key.ExpireAt.IsZero() && time.Now().After(key.ExpireAt) {
+		return nil, fmt.Errorf("key %s expired", keyID)
+	}
+
+	body, err := readBodyForSignature(c.Request, cfg.MaxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	expected := computeSignature(key.Secret, signingString(c.Request.Method, c.Request.URL.RequestURI(), ts, nonce, body))
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(signature))) != 1 {
+		return nil, fmt.Errorf("signature mismatch")
+	}
+
+	// nonce 校验放在验签之后，避免伪造请求占用 nonce。
+	if err := useNonce(ctx, cfg.NoncePrefix+keyID+":"+nonce, 2*skew); err != nil {
+		return nil, err
+	}
+	return newServiceSession(key.KeyID, key.Name, key.Scopes, key.ExpireAt), nil
+}
+
+// SignRequest 调用方为请求添加 HMAC 签名头；body 读取后会被还原。
+func SignRequest(req *http.Request, keyID, secret string) error {
+	body, err := readBodyForSignature(req, 0)
+	if err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := uuid.NewString()
+	req.Header.Set(HeaderAuthKeyID, keyID)
+	req.Header.Set(HeaderAuthTimestamp, ts)
+	req.Header.Set(HeaderAuthNonce, nonce)
+	req.Header.Set(HeaderAuthSignature, computeSignature(secret, signingString(req.Method, req.URL.RequestURI(), ts, nonce, body)))
+	return nil
+}
+
+// signingString METHOD\nURI（含 query）\nTIMESTAMP\nNONCE\nhex(sha256(body))。
+func signingString(method, uri, ts, nonce string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return strings.Join([]string{strings.ToUpper(method), uri, ts, nonce, hex.EncodeToString(sum[:])}, "\n")
+}
+
+func computeSignature(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// readBodyForSignature 读取并还原 body；limit > 0 时超限报错。
+func readBodyForSignature(req *http.Request, limit int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	reader := io.Reader(req.Body)
+	if limit > 0 {
+		reader = io.LimitReader(req.Body, limit+1)
+	}
+	body, err := io.ReadAll(reader)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, fmt.Errorf("request body exceeds %d bytes", limit)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// useNonce 防重放：nonce 首次出现才放行；Redis 不可用时拒绝（fail closed）。
+func useNonce(ctx context.Context, key string, ttl time.Duration) error {
+	client := infrastructure.GetRedis()
+	if client == nil {
+		return fmt.Errorf("redis is not initialized, cannot check nonce")
+	}
+	ok, err := client.SetNX(ctx, key, 1, ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("nonce replayed")
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package myAuth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func serviceAuthRouter(opts ...Option) (*gin.Engine, *[]*Session) {
+	gin.SetMode(gin.TestMode)
+	var seen []*Session
+	r := gin.New()
+	r.Use(Required(opts...))
+	handler := func(c *gin.Context) {
+		seen = append(seen, MustSession(c))
+		c.Status(http.StatusNoContent)
+	}
+	r.POST("/hooks/order", handler)
+	r.GET("/partner/orders", RequirePermission("order:read"), handler)
+	return r, &seen
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	useTestRedis(t)
+	initTestAuth(t, Config{
+		Provider:           ProviderEncryptedJWT,
+		TokenExpireSeconds: 3600,
+		JWT:                JWTConfig{Key: testJWTKey(), Issuer: "test"},
+	})
+	ctx := context.Background()
+	store := NewRedisAPIKeyStore("")
+
+	plain, hash, err := GenerateAPIKey("pk_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(ctx, hash, &APIKey{ID: "partner-1", Name: "acme", Scopes: []string{"order:*"}}); err != nil {
+		t.Fatal(err)
+	}
+	readOnly, roHash, _ := GenerateAPIKey("pk_")
+	_ = store.Save(ctx, roHash, &APIKey{ID: "partner-2", Scopes: []string{"user:read"}})
+
+	r, seen := serviceAuthRouter(WithAPIKey(nil))
+	cases := []struct {
+		name, path, key string
+		want            bool
+	}{
+		{"valid", "/partner/orders", plain, true},
+		{"bearer form", "/partner/orders", "ApiKey " + plain, true},
+		{"missing scope", "/partner/orders", readOnly, false},
+		{"unknown", "/partner/orders", "pk_unknown", false},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if strings.HasPrefix(tc.key, "ApiKey ") {
+			req.Header.Set("Authorization", tc.key)
+		} else {
+			req.Header.Set(defaultAPIKeyHeader, tc.key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if got := w.Code == http.StatusNoContent; got != tc.want {
+			t.Errorf("%s: allowed = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if len(*seen) == 0 || !(*seen)[0].IsService() || (*seen)[0].ServiceID != "partner-1" {
+		t.Fatalf("expected service principal, got %+v", *seen)
+	}
+}
+
+func TestHMACSignatureAuthentication(t *testing.T) {
+	useTestRedis(t)
+	initTestAuth(t, Config{
+		Provider:           ProviderEncryptedJWT,
+		TokenExpireSeconds: 3600,
+		JWT:                JWTConfig{Key: testJWTKey(), Issuer: "test"},
+		Service: ServiceAuthConfig{
+			HMACKeys: []HMACKey{
+				{KeyID: "webhook", Secret: "s3cret", Scopes: []string{"order:write"}},
+				{KeyID: "old", Secret: "s3cret", ExpireAt: time.Now().Add(-time.Hour)},
+			},
+		},
+	})
+	r, _ := serviceAuthRouter(WithHMAC(nil))
+
+	signed := func(keyID, secret, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/hooks/order?id=1", strings.NewReader(body))
+		if err := SignRequest(req, keyID, secret); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	allowed := func(req *http.Request) bool {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code == http.StatusNoContent
+	}
+
+	req := signed("webhook", "s3cret", `{"id":1}`)
+	if !allowed(req) {
+		t.Fatal("valid signature should pass")
+	}
+	replay := httptest.NewRequest(http.MethodPost, "/hooks/order?id=1", strings.NewReader(`{"id":1}`))
+	replay.Header = req.Header.Clone()
+	if allowed(replay) {
+		t.Fatal("replayed nonce should be rejected")
+	}
+
+	tampered := signed("webhook", "s3cret", `{"id":1}`)
+	tampered.Body = http.NoBody
+	if allowed(tampered) {
+		t.Fatal("tampered body should be rejected")
+	}
+	if allowed(signed("webhook", "wrong", "")) {
+		t.Fatal("wrong secret should be rejected")
+	}
+	if allowed(signed("old", "s3cret", "")) {
+		t.Fatal("expired key should be rejected")
+	}
+
+	stale := signed("webhook", "s3cret", "")
+	stale.Header.Set(HeaderAuthTimestamp, "1000")
+	if allowed(stale) {
+		t.Fatal("stale timestamp should be rejected")
+	}
+}
@@ -12,9 +12,31 @@ type Session struct {
 	Token string
 	JTI   string
 
+	// RefreshToken 仅在启用 auth.refresh 时由 Create / Refresh 返回，不参与鉴权。
+	RefreshToken    string
+	RefreshExpireAt time.Time
+
+	// Principal 主体类型，空值等同 PrincipalUser；api key / HMAC 鉴权为 PrincipalService。
+	Principal string
+	// ServiceID 服务主体标识（api key id 或 HMAC key id）。
+	ServiceID string
+
+	// issuedAt 签发时间，用于 valid-after 校验（早于该字段写入的会话为零值）。
+	issuedAt time.Time
+	// lifetime 签发时的有效期，滑动续期按此延长（早于该字段写入的会话为 0，按全局 ttl）。
+	lifetime time.Duration
+
 	extras map[string]any
 }
 
+// ExtraTenantId Session extras 中的租户 ID，鉴权后写入 myContext（由 enricher 或 token claims 提供）。
+const ExtraTenantId = "tenantId"
+
+const (
+	PrincipalUser    = "user"
+	PrincipalService = "service"
+)
+
 // SessionInput 创建会话时的输入。
 type SessionInput struct {
 	UserID      int64
@@ -23,6 +45,11 @@ type SessionInput struct {
 	TTL         time.Duration
 }
 
+// IsService 是否为服务主体（无用户身份）。
+func (s *Session) IsService() bool {
+	return s != nil && s.Principal == PrincipalService
+}
+
 func (s *Session) Extra(key string) (any, bool) {
 	if s == nil || s.extras == nil {
 		return nil, false
@@ -16,6 +16,8 @@ type sessionRecord struct {
 	DisplayName string         `json:"displayName"`
 	ExpireAt    time.Time      `json:"expireAt"`
 	JTI         string         `json:"jti"`
+	IssuedAt    time.Time      `json:"issuedAt"`
+	Lifetime    time.Duration  `json:"lifetime,omitempty"`
 	Extras      map[string]any `json:"extras,omitempty"`
 }
 
@@ -39,6 +41,8 @@ func (s *redisSessionStore) Save(ctx context.Context, token string, sess *Sessio
 		DisplayName: sess.DisplayName,
 		ExpireAt:    sess.ExpireAt,
 		JTI:         sess.JTI,
+		IssuedAt:    sess.issuedAt,
+		Lifetime:    sess.lifetime,
 		Extras:      sess.cloneExtras(),
 	}
 	data, err := json.Marshal(record)
@@ -77,6 +81,8 @@ func (s *redisSessionStore) Get(ctx context.Context, token string) (*Session, er
 		ExpireAt:    record.ExpireAt,
 		JTI:         record.JTI,
 		Token:       token,
+		issuedAt:    record.IssuedAt,
+		lifetime:    record.Lifetime,
 	}
 	if len(record.Extras) > 0 {
 		sess.extras = record.Extras
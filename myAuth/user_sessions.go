@@ -0,0 +1,334 @@
+package myAuth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/muyi-zcy/tech-muyi-base-go/infrastructure"
+)
+
+// SessionInfo 用户会话索引中的一条记录（不含 token 明文）。
+type SessionInfo struct {
+	JTI      string    `json:"jti"`
+	UserID   int64     `json:"userId"`
+	Provider string    `json:"provider"`
+	IssuedAt time.Time `json:"issuedAt"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
+// redisUserSessionIndex 按用户维护会话索引，覆盖所有 provider。
+//
+//	prefix + userId          → ZSET(member=jti, score=issuedAt 毫秒)，按签发时间排序
+//	prefix + userId + ":info" → HASH(jti → SessionInfo JSON)
+//
+// Redis 未初始化时与吊销存储一致地静默跳过，不影响纯 JWT 场景。
+type redisUserSessionIndex struct {
+	prefix string
+	ttl    time.Duration
+}
+
+func newRedisUserSessionIndex(prefix string, ttl time.Duration) *redisUserSessionIndex {
+	return &redisUserSessionIndex{prefix: prefix, ttl: ttl}
+}
+
+func (s *redisUserSessionIndex) keys(userID int64) (string, string) {
+	key := s.prefix + strconv.FormatInt(userID, 10)
+	return key, key + ":info"
+}
+
+// Add 登记会话；maxConcurrent > 0 时返回因超额被挤出的最早会话（已从索引移除）。
+func (s *redisUserSessionIndex) Add(ctx context.Context, info SessionInfo, maxConcurrent int) ([]SessionInfo, error) {
+	client := infrastructure.GetRedis()
+	if client == nil || info.JTI == "" {
+		return nil, nil
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	zkey, hkey := s.keys(info.UserID)
+	ttl := s.ttl
+	if remain := time.Until(info.ExpireAt); remain > ttl {
+		ttl = remain
+	}
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, zkey, &redis.Z{Score: float64(info.IssuedAt.UnixMilli()), Member: info.JTI})
+		pipe.HSet(ctx, hkey, info.JTI, data)
+		pipe.Expire(ctx, zkey, ttl)
+		pipe.Expire(ctx, hkey, ttl)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if maxConcurrent <= 0 {
+		return nil, nil
+	}
+
+	if _, err := s.prune(ctx, info.UserID); err != nil {
+		return nil, err
+	}
+	count, err := client.ZCard(ctx, zkey).Result()
+	if err != nil {
+		return nil, err
+	}
+	overflow := count - int64(maxConcurrent)
+	if overflow <= 0 {
+		return nil, nil
+	}
+	jtis, err := client.ZRange(ctx, zkey, 0, overflow-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	evicted, err := s.load(ctx, info.UserID, jtis)
+	if err != nil {
+		return nil, err
+	}
+	return evicted, s.Remove(ctx, info.UserID, jtis...)
+}
+
+// Touch 会话续期后更新索引中的过期时间，并按需延长索引键的 TTL；
+// 条目已不在索引中（被挤出或已清空）时不重新登记。
+func (s *redisUserSessionIndex) Touch(ctx context.Context, userID int64, jti string, expireAt time.Time) error {
+	client := infrastructure.GetRedis()
+	if client == nil || jti == "" {
+		return nil
+	}
+	zkey, hkey := s.keys(userID)
+	raw, err := client.HGet(ctx, hkey, jti).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var info SessionInfo
+	if err := json.Unmarshal([]byte(raw), &info); err != nil {
+		return err
+	}
+	info.ExpireAt = expireAt
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	remain := time.Until(expireAt)
+	current, err := client.TTL(ctx, zkey).Result()
+	if err != nil {
+		return err
+	}
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, hkey, jti, data)
+		if current < remain {
+			pipe.Expire(ctx, zkey, remain)
+			pipe.Expire(ctx, hkey, remain)
+		}
+		return nil
+	})
+	return err
+}
+
+// Remove 从索引中移除会话。
+func (s *redisUserSessionIndex) Remove(ctx context.Context, userID int64, jtis ...string) error {
+	client := infrastructure.GetRedis()
+	if client == nil || len(jtis) == 0 {
+		return nil
+	}
+	zkey, hkey := s.keys(userID)
+	members := make([]interface{}, 0, len(jtis))
+	for _, jti := range jtis {
+		members = append(members, jti)
+	}
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, zkey, members...)
+		pipe.HDel(ctx, hkey, jtis...)
+		return nil
+	})
+	return err
+}
+
+// List 返回用户未过期的会话，按签发时间升序；过期条目顺带清理。
+func (s *redisUserSessionIndex) List(ctx context.Context, userID int64) ([]SessionInfo, error) {
+	client := infrastructure.GetRedis()
+	if client == nil {
+		return nil, fmt.Errorf("myAuth: redis is not initialized")
+	}
+	return s.prune(ctx, userID)
+}
+
+// Clear 删除用户全部索引并返回删除前的会话。
+func (s *redisUserSessionIndex) Clear(ctx context.Context, userID int64) ([]SessionInfo, error) {
+	client := infrastructure.GetRedis()
+	if client == nil {
+		return nil, fmt.Errorf("myAuth: redis is not initialized")
+	}
+	zkey, hkey := s.keys(userID)
+	jtis, err := client.ZRange(ctx, zkey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.load(ctx, userID, jtis)
+	if err != nil {
+		return nil, err
+	}
+	return sessions, client.Del(ctx, zkey, hkey).Err()
+}
+
+func (s *redisUserSessionIndex) prune(ctx context.Context, userID int64) ([]SessionInfo, error) {
+	client := infrastructure.GetRedis()
+	zkey, _ := s.keys(userID)
+	jtis, err := client.ZRange(ctx, zkey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.load(ctx, userID, jtis)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	alive := make([]SessionInfo, 0, len(sessions))
+	var expired []string
+	for _, info := range sessions {
+		if !info.ExpireAt.IsZero() && now.After(info.ExpireAt) {
+			expired = append(expired, info.JTI)
+			continue
+		}
+		alive = append(alive, info)
+	}
+	if err := s.Remove(ctx, userID, expired...); err != nil {
+		return nil, err
+	}
+	return alive, nil
+}
+
+// load 按 jtis 顺序读取会话详情；详情缺失的 jti 仅保留标识。
+func (s *redisUserSessionIndex) load(ctx context.Context, userID int64, jtis []string) ([]SessionInfo, error) {
+	if len(jtis) == 0 {
+		return nil, nil
+	}
+	client := infrastructure.GetRedis()
+	_, hkey := s.keys(userID)
+	values, err := client.HMGet(ctx, hkey, jtis...).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SessionInfo, 0, len(jtis))
+	for i, jti := range jtis {
+		info := SessionInfo{JTI: jti, UserID: userID}
+		if raw, ok := values[i].(string); ok {
+			_ = json.Unmarshal([]byte(raw), &info)
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// redisValidAfterStore 用户级签发时间下限：iat 早于该时间的 JWT 一律视为吊销。
+type redisValidAfterStore struct {
+	prefix string
+	ttl    time.Duration
+}
+
+func newRedisValidAfterStore(prefix string, ttl time.Duration) *redisValidAfterStore {
+	return &redisValidAfterStore{prefix: prefix, ttl: ttl}
+}
+
+func (s *redisValidAfterStore) Get(ctx context.Context, userID int64) (time.Time, error) {
+	client := infrastructure.GetRedis()
+	if client == nil {
+		return time.Time{}, nil
+	}
+	ms, err := client.Get(ctx, s.prefix+strconv.FormatInt(userID, 10)).Int64()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	// 兼容按秒存储的旧记录
+	if ms < validAfterMilliThreshold {
+		return time.Unix(ms, 0), nil
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// validAfterMilliThreshold 小于该值的记录为秒级时间戳（毫秒级时间戳自 2001 年起即超过该值）
+const validAfterMilliThreshold = 1e12
+
+// Set 记录下限，按毫秒向上取整存储。JWT iat 只有秒级精度、向下取整，与「退出所有设备」同一秒内签发的 JWT
+// 因此一律视为之前签发而被拒绝（宁可让用户重新登录，也不放过退出前签发的令牌）。
+func (s *redisValidAfterStore) Set(ctx context.Context, userID int64, at time.Time) error {
+	client := infrastructure.GetRedis()
+	if client == nil {
+		return fmt.Errorf("myAuth: redis is not initialized")
+	}
+	return client.Set(ctx, s.prefix+strconv.FormatInt(userID, 10), at.Add(time.Millisecond-time.Nanosecond).UnixMilli(), s.ttl).Err()
+}
+
+func (m *manager) indexSession(ctx context.Context, claims *Claims) error {
+	issuedAt := claims.IssuedAt
+	if issuedAt.IsZero() {
+		issuedAt = time.Now()
+	}
+	evicted, err := m.index.Add(ctx, SessionInfo{
+		JTI:      claims.JTI,
+		UserID:   claims.UserID,
+		Provider: m.provider.Name(),
+		IssuedAt: issuedAt,
+		ExpireAt: claims.ExpireAt,
+	}, m.cfg.Session.MaxConcurrent)
+	if err != nil {
+		return err
+	}
+	for _, info := range evicted {
+		if err := m.revokeIndexed(ctx, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeIndexed 吊销索引中的一条会话及其 refresh 家族。
+func (m *manager) revokeIndexed(ctx context.Context, info SessionInfo) error {
+	if m.refresh != nil {
+		family, err := m.refresh.FamilyOf(ctx, info.JTI)
+		if err != nil {
+			return err
+		}
+		if family != "" {
+			if err := m.revokeFamily(ctx, info.UserID, family); err != nil {
+				return err
+			}
+		}
+	}
+	expireAt := info.ExpireAt
+	if expireAt.IsZero() {
+		expireAt = time.Now().Add(m.cfg.tokenTTL())
+	}
+	return m.provider.Revoke(ctx, &Claims{UserID: info.UserID, JTI: info.JTI, ExpireAt: expireAt})
+}
+
+// ListSessions 列出用户当前有效的会话。
+func (m *manager) ListSessions(ctx context.Context, userID int64) ([]SessionInfo, error) {
+	return m.index.List(ctx, userID)
+}
+
+// DestroyAllForUser 「退出所有设备」：写入 valid-after 时间并吊销索引中的全部会话。
+func (m *manager) DestroyAllForUser(ctx context.Context, userID int64) error {
+	if err := m.validAfter.Set(ctx, userID, time.Now()); err != nil {
+		return err
+	}
+	sessions, err := m.index.Clear(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, info := range sessions {
+		if err := m.revokeIndexed(ctx, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package myAuth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestListSessionsAndMaxConcurrent(t *testing.T) {
+	useTestRedis(t)
+	initTestAuth(t, Config{
+		Provider:           ProviderEncryptedJWT,
+		TokenExpireSeconds: 3600,
+		JWT:                JWTConfig{Key: testJWTKey(), Issuer: "test"},
+		Session:            SessionStoreConfig{MaxConcurrent: 2},
+	})
+	ctx := context.Background()
+
+	var tokens []string
+	for i := 0; i < 3; i++ {
+		_, token, err := Manager().Create(ctx, &SessionInput{UserID: 11})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, token)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	sessions, err := Manager().ListSessions(ctx, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("sessions = %d, want 2", len(sessions))
+	}
+	if _, err := Manager().LoadFromToken(ctx, tokens[0], ""); err == nil {
+		t.Fatal("oldest session should be evicted")
+	}
+	for _, tok := range tokens[1:] {
+		if sess, err := Manager().LoadFromToken(ctx, tok, ""); err != nil || sess == nil {
+			t.Fatalf("recent session should survive: %v", err)
+		}
+	}
+}
+
+func TestDestroyAllForUser(t *testing.T) {
+	useTestRedis(t)
+	initTestAuth(t, Config{
+		Provider:           ProviderRedisOpaque,
+		TokenExpireSeconds: 3600,
+	})
+	ctx := context.Background()
+
+	_, t1, err := Manager().Create(ctx, &SessionInput{UserID: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, t2, err := Manager().Create(ctx, &SessionInput{UserID: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, other, err := Manager().Create(ctx, &SessionInput{UserID: 13})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Manager().DestroyAllForUser(ctx, 12); err != nil {
+		t.Fatal(err)
+	}
+	for _, tok := range []string{t1, t2} {
+		if sess, _ := Manager().LoadFromToken(ctx, tok, ""); sess != nil {
+			t.Fatal("session should be destroyed")
+		}
+	}
+	if sess, err := Manager().LoadFromToken(ctx, other, ""); err != nil || sess == nil {
+		t.Fatal("other user's session should survive")
+	}
+	if sessions, _ := Manager().ListSessions(ctx, 12); len(sessions) != 0 {
+		t.Fatalf("index not cleared: %+v", sessions)
+	}
+}
+
+func TestEncryptedJWTValidAfter(t *testing.T) {
+	useTestRedis(t)
+	cfg := Config{
+		Provider:           ProviderEncryptedJWT,
+		TokenExpireSeconds: 3600,
+		JWT:                JWTConfig{Key: testJWTKey(), Issuer: "test"},
+	}.withDefaults()
+	p, err := newEncryptedJWTProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	token, err := p.Issue(ctx, &Claims{UserID: 14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newRedisValidAfterStore(cfg.Session.ValidAfterPrefix, time.Hour)
+	if err := store.Set(ctx, 14, time.Now().Add(2*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Parse(ctx, token); err == nil {
+		t.Fatal("token issued before valid-after should be rejected")
+	}
+}
+
+func TestSlidingSessionIndexAndValidAfter(t *testing.T) {
+	useTestRedis(t)
+	initTestAuth(t, Config{
+		Provider:           ProviderRedisOpaque,
+		TokenExpireSeconds: 3600,
+		Session:            SessionStoreConfig{Sliding: true, SlidingIntervalSeconds: 60},
+	})
+	ctx := context.Background()
+	m := Manager().(*manager)
+
+	_, token, err := m.Create(ctx, &SessionInput{UserID: 15, TTL: 10 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 模拟 9 分钟后访问：会话与索引均只剩 1 分钟
+	store := m.provider.(*redisOpaqueProvider).store
+	stored, err := store.Get(ctx, token)
+	if err != nil || stored == nil {
+		t.Fatalf("load stored session: %v", err)
+	}
+	stored.ExpireAt = time.Now().Add(time.Minute)
+	if err := store.Save(ctx, token, stored); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.index.Touch(ctx, 15, token, stored.ExpireAt); err != nil {
+		t.Fatal(err)
+	}
+
+	if sess, err := m.LoadFromToken(ctx, token, ""); err != nil || sess == nil {
+		t.Fatalf("load session: %v", err)
+	}
+	sessions, err := m.ListSessions(ctx, 15)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("sessions = %+v, err = %v", sessions, err)
+	}
+	if remain := time.Until(sessions[0].ExpireAt); remain < 9*time.Minute {
+		t.Fatalf("index expireAt not slid: %v", remain)
+	}
+
+	// 索引条目丢失时「退出所有设备」仍须使会话失效
+	if err := m.index.Remove(ctx, 15, token); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DestroyAllForUser(ctx, 15); err != nil {
+		t.Fatal(err)
+	}
+	if sess, _ := m.LoadFromToken(ctx, token, ""); sess != nil {
+		t.Fatal("sliding session survived DestroyAllForUser")
+	}
+	// valid-after 向上取整到毫秒，同一毫秒内签发的会话同样视为之前签发
+	time.Sleep(2 * time.Millisecond)
+	if _, fresh, err := m.Create(ctx, &SessionInput{UserID: 15}); err != nil {
+		t.Fatal(err)
+	} else if sess, err := m.LoadFromToken(ctx, fresh, ""); err != nil || sess == nil {
+		t.Fatalf("session issued after valid-after rejected: %v", err)
+	}
+}
+
+func TestValidAfterSameSecond(t *testing.T) {
+	mr := useTestRedis(t)
+	cfg := Config{
+		Provider:           ProviderEncryptedJWT,
+		TokenExpireSeconds: 3600,
+		JWT:                JWTConfig{Key: testJWTKey(), Issuer: "test"},
+	}.withDefaults()
+	p, err := newEncryptedJWTProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	token, err := p.Issue(ctx, &Claims{UserID: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newRedisValidAfterStore(cfg.Session.ValidAfterPrefix, time.Hour)
+	if err := store.Set(ctx, 16, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Parse(ctx, token); err == nil {
+		t.Fatal("token issued in the same second before valid-after should be rejected")
+	}
+
+	// 旧版按秒存储的记录仍可读取
+	if err := mr.Set(cfg.Session.ValidAfterPrefix+"17", "1700000000"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Get(ctx, 17); err != nil || !got.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("legacy valid-after = %v, err = %v", got, err)
+	}
+}
@@ -8,8 +8,9 @@ import (
 
 // ScalarBinding 可传播的标量上下文；空字符串表示不写入/不覆盖。
 type ScalarBinding struct {
-	SsoId string
-	Token string
+	SsoId    string
+	Token    string
+	TenantId string
 }
 
 // BindScalars 将标量写入 Gin 与 request context（唯一入口，避免双写遗漏）。
@@ -26,6 +27,10 @@ func BindScalars(c *gin.Context, b ScalarBinding) {
 		c.Set(ginKeyToken, b.Token)
 		ctx = context.WithValue(ctx, keyToken, b.Token)
 	}
+	if b.TenantId != "" {
+		c.Set(ginKeyTenant, b.TenantId)
+		ctx = context.WithValue(ctx, keyTenant, b.TenantId)
+	}
 	c.Request = c.Request.WithContext(ctx)
 }
 
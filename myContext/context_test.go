@@ -36,7 +36,7 @@ func TestBindScalarsAndResolveActor(t *testing.T) {
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	BindScalars(c, ScalarBinding{SsoId: "42", Token: "tok"})
+	BindScalars(c, ScalarBinding{SsoId: "42", Token: "tok", TenantId: "t1"})
 	ctx := c.Request.Context()
 	if TryGetSsoId(ctx) != "42" {
 		t.Fatalf("ssoId = %q", TryGetSsoId(ctx))
@@ -44,6 +44,9 @@ func TestBindScalarsAndResolveActor(t *testing.T) {
 	if TryGetToken(ctx) != "tok" {
 		t.Fatalf("token = %q", TryGetToken(ctx))
 	}
+	if TryGetTenantId(ctx) != "t1" {
+		t.Fatalf("tenantId = %q", TryGetTenantId(ctx))
+	}
 	if ResolveActor(ctx) != "42" {
 		t.Fatalf("ResolveActor = %q", ResolveActor(ctx))
 	}
@@ -0,0 +1,58 @@
+package myContext
+
+import (
+	"context"
+	"crypto/x509"
+)
+
+// PeerIdentity mTLS 对端证书身份；仅在证书链校验通过的连接上写入。
+type PeerIdentity struct {
+	CommonName string
+	// SpiffeID 证书 URI SAN 中的 spiffe:// 标识（如 spiffe://corp/ns/prod/sa/order）
+	SpiffeID string
+	DNSNames []string
+	URIs     []string
+}
+
+// Name 优先返回 SPIFFE ID，否则返回 CN。
+func (p PeerIdentity) Name() string {
+	if p.SpiffeID != "" {
+		return p.SpiffeID
+	}
+	return p.CommonName
+}
+
+// PeerIdentityFromCertificate 从叶子证书提取身份。
+func PeerIdentityFromCertificate(cert *x509.Certificate) PeerIdentity {
+	if cert == nil {
+		return PeerIdentity{}
+	}
+	id := PeerIdentity{
+		CommonName: cert.Subject.CommonName,
+		DNSNames:   cert.DNSNames,
+	}
+	for _, u := range cert.URIs {
+		id.URIs = append(id.URIs, u.String())
+		if u.Scheme == "spiffe" && id.SpiffeID == "" {
+			id.SpiffeID = u.String()
+		}
+	}
+	return id
+}
+
+// WithPeerIdentity 写入对端身份（gRPC ContextExtract 使用）。
+func WithPeerIdentity(ctx context.Context, id PeerIdentity) context.Context {
+	if ctx == nil || id.Name() == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, keyPeerIdentity, id)
+}
+
+// TryGetPeerIdentity 读取对端身份；非 mTLS 连接返回 false。
+func TryGetPeerIdentity(ctx context.Context) (PeerIdentity, bool) {
+	if ctx == nil {
+		return PeerIdentity{}, false
+	}
+	id, ok := ctx.Value(keyPeerIdentity).(PeerIdentity)
+	return id, ok
+}
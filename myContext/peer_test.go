@@ -0,0 +1,31 @@
+package myContext
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+)
+
+func TestPeerIdentityFromCertificate(t *testing.T) {
+	spiffe, _ := url.Parse("spiffe://corp/ns/prod/sa/order")
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "order-service"},
+		DNSNames: []string{"order.prod.svc"},
+		URIs:     []*url.URL{spiffe},
+	}
+	id := PeerIdentityFromCertificate(cert)
+	if id.SpiffeID != spiffe.String() || id.Name() != spiffe.String() {
+		t.Fatalf("spiffe id = %q, name = %q", id.SpiffeID, id.Name())
+	}
+
+	ctx := WithPeerIdentity(context.Background(), id)
+	got, ok := TryGetPeerIdentity(ctx)
+	if !ok || got.CommonName != "order-service" {
+		t.Fatalf("TryGetPeerIdentity = %+v, %v", got, ok)
+	}
+	if _, ok := TryGetPeerIdentity(WithPeerIdentity(context.Background(), PeerIdentity{})); ok {
+		t.Fatal("empty identity should not be stored")
+	}
+}
@@ -22,7 +22,7 @@ func WithMetadata(ctx context.Context, md metadata.MD) context.Context {
 	return ctx
 }
 
-// MetadataFromContext 从 context 提取 metadata；ssoId 仅在鉴权后随 token 一并传播。
+// MetadataFromContext 从 context 提取 metadata；ssoId / tenantId 仅在鉴权后随 token 一并传播。
 func MetadataFromContext(ctx context.Context) metadata.MD {
 	md := metadata.MD{}
 	if traceId := TryGetTraceId(ctx); traceId != "" {
@@ -34,6 +34,9 @@ func MetadataFromContext(ctx context.Context) metadata.MD {
 	if ssoId := TryGetSsoId(ctx); ssoId != "" {
 		md.Set(HeaderSsoId, ssoId)
 	}
+	if tenantId := TryGetTenantId(ctx); tenantId != "" {
+		md.Set(HeaderTenantId, tenantId)
+	}
 	return md
 }
 
@@ -43,3 +46,14 @@ func firstMD(md metadata.MD, key string) string {
 	}
 	return ""
 }
+
+// TryGetSourceService 上游服务名（来自 x-source-service，仅用于日志与统计，不作鉴权依据）。
+func TryGetSourceService(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if v, ok := ctx.Value(keySourceService).(string); ok {
+		return v
+	}
+	return ""
+}
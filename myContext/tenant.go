@@ -0,0 +1,22 @@
+package myContext
+
+import "context"
+
+// TryGetTenantId 从 context 获取租户 ID（不报错）；由鉴权层从会话写入，未鉴权或无租户时为空。
+func TryGetTenantId(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if tenantId, ok := ctx.Value(keyTenant).(string); ok {
+		return tenantId
+	}
+	return ""
+}
+
+// WithTenantId 写入租户 ID（鉴权成功后使用；不信任外部裸 x-tenant-id）。
+func WithTenantId(ctx context.Context, tenantId string) context.Context {
+	if ctx == nil || tenantId == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, keyTenant, tenantId)
+}
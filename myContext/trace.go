@@ -52,3 +52,11 @@ func GetTraceId(ctx context.Context) (string, error) {
 	}
 	return "", myException.NewBizError("platform.unauthorized", nil)
 }
+
+// WithTraceId 写入 traceId（gRPC 入口、异步任务等非 Gin 场景使用）。
+func WithTraceId(ctx context.Context, traceId string) context.Context {
+	if ctx == nil || traceId == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, keyTrace, traceId)
+}
@@ -0,0 +1,179 @@
+package myFeature
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/config"
+	"github.com/muyi-zcy/tech-muyi-base-go/myLogger"
+	"go.uber.org/zap"
+)
+
+const (
+	HashBySsoId  = "ssoId"
+	HashByTenant = "tenant"
+
+	configKey = "features"
+)
+
+// Config myFeature 配置（[features]），可放在本地配置文件或 Nacos 配置中心。
+type Config struct {
+	// Flags 开关名 → 配置；开关名不区分大小写（配置加载后统一为小写）。
+	Flags map[string]FlagConfig `mapstructure:"flags"`
+}
+
+// FlagConfig 单个开关。判定顺序：enabled=false → deny 名单 → allow 名单 → 百分比灰度。
+type FlagConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	Description string `mapstructure:"description"`
+	// Percentage 灰度比例 0-100，未配置时为 100（对所有人开启）。
+	Percentage *float64 `mapstructure:"percentage"`
+	// HashBy 灰度分桶依据：ssoId（默认）或 tenant；同一主体对同一开关的结果稳定。
+	HashBy string `mapstructure:"hashBy"`
+
+	AllowSsoIds  []string `mapstructure:"allowSsoIds"`
+	DenySsoIds   []string `mapstructure:"denySsoIds"`
+	AllowTenants []string `mapstructure:"allowTenants"`
+	DenyTenants  []string `mapstructure:"denyTenants"`
+}
+
+var (
+	cfgMu     sync.RWMutex
+	flags     = map[string]*flag{}
+	watchOnce sync.Once
+)
+
+// Init 加载开关配置，替换全部开关；配置非法时保留原配置并返回错误。
+func Init(cfg Config) error {
+	compiled := make(map[string]*flag, len(cfg.Flags))
+	for name, fc := range cfg.Flags {
+		f, err := compileFlag(name, fc)
+		if err != nil {
+			return err
+		}
+		compiled[f.name] = f
+	}
+	cfgMu.Lock()
+	flags = compiled
+	cfgMu.Unlock()
+	return nil
+}
+
+// ConfigFromViper 从配置加载 [features]。
+func ConfigFromViper() (Config, error) {
+	cfg := Config{}
+	if err := config.GetConfigByType(configKey, &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// InitFromViper 从配置初始化，并在配置文件或 Nacos 配置变更时热更新开关；
+// 初次加载失败时仍会监听变更，修正配置后即生效。
+func InitFromViper() error {
+	var watchErr error
+	watchOnce.Do(func() {
+		watchErr = config.OnKeyChange(configKey, func(cfg Config) {
+			if err := Init(cfg); err != nil {
+				myLogger.Warn("功能开关热更新失败，沿用原配置", zap.Error(err))
+				return
+			}
+			myLogger.Info("功能开关已热更新", zap.Int("flags", len(cfg.Flags)))
+		})
+	})
+	if watchErr != nil {
+		return watchErr
+	}
+	cfg, err := ConfigFromViper()
+	if err != nil {
+		return err
+	}
+	return Init(cfg)
+}
+
+// MustInitFromViper 从配置初始化，失败 panic。
+func MustInitFromViper() {
+	if err := InitFromViper(); err != nil {
+		panic(err)
+	}
+}
+
+// Names 返回已配置的开关名（有序）。
+func Names() []string {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// flag 编译后的开关，名单转为集合。
+type flag struct {
+	name        string
+	enabled     bool
+	description string
+	percentage  float64
+	hashBy      string
+
+	allowSso    map[string]struct{}
+	denySso     map[string]struct{}
+	allowTenant map[string]struct{}
+	denyTenant  map[string]struct{}
+}
+
+func compileFlag(name string, fc FlagConfig) (*flag, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return nil, fmt.Errorf("myFeature: flag name is required")
+	}
+	f := &flag{
+		name:        name,
+		enabled:     fc.Enabled,
+		description: fc.Description,
+		percentage:  100,
+		allowSso:    toSet(fc.AllowSsoIds),
+		denySso:     toSet(fc.DenySsoIds),
+		allowTenant: toSet(fc.AllowTenants),
+		denyTenant:  toSet(fc.DenyTenants),
+	}
+	if fc.Percentage != nil {
+		if *fc.Percentage < 0 || *fc.Percentage > 100 {
+			return nil, fmt.Errorf("myFeature: features.flags.%s.percentage must be within [0, 100]", name)
+		}
+		f.percentage = *fc.Percentage
+	}
+	switch strings.ToLower(fc.HashBy) {
+	case "", strings.ToLower(HashBySsoId):
+		f.hashBy = HashBySsoId
+	case HashByTenant:
+		f.hashBy = HashByTenant
+	default:
+		return nil, fmt.Errorf("myFeature: unknown features.flags.%s.hashBy %q", name, fc.HashBy)
+	}
+	return f, nil
+}
+
+func toSet(items []string) map[string]struct{} {
+	if len(items) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			set[item] = struct{}{}
+		}
+	}
+	return set
+}
+
+func lookup(name string) (*flag, bool) {
+	cfgMu.RLock()
+	defer cfgMu.RUnlock()
+	f, ok := flags[strings.ToLower(name)]
+	return f, ok
+}
@@ -0,0 +1,109 @@
+package myFeature
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+)
+
+// 判定原因，便于排查某个主体为何命中 / 未命中。
+const (
+	ReasonUnknown      = "unknown"
+	ReasonDisabled     = "disabled"
+	ReasonDenied       = "denied"
+	ReasonAllowed      = "allowed"
+	ReasonRollout      = "rollout"
+	ReasonNotInRollout = "not_in_rollout"
+	ReasonNoSubject    = "no_subject"
+)
+
+// bucketScale 灰度分桶精度（0.01%）。
+const bucketScale = 10000
+
+// Subject 判定主体。
+type Subject struct {
+	SsoId    string
+	TenantId string
+}
+
+// SubjectFromContext 从 myContext 读取 ssoId 与 tenantId（均由鉴权层写入）。
+func SubjectFromContext(ctx context.Context) Subject {
+	return Subject{
+		SsoId:    myContext.TryGetSsoId(ctx),
+		TenantId: myContext.TryGetTenantId(ctx),
+	}
+}
+
+// Evaluation 判定结果。
+type Evaluation struct {
+	Flag    string `json:"flag"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// Enabled 判断当前请求主体是否开启该开关；未配置的开关视为关闭。
+func Enabled(ctx context.Context, name string) bool {
+	return Evaluate(ctx, name).Enabled
+}
+
+// Evaluate 按 context 中的主体判定开关。
+func Evaluate(ctx context.Context, name string) Evaluation {
+	return EvaluateFor(name, SubjectFromContext(ctx))
+}
+
+// EvaluateFor 按指定主体判定开关（异步任务等无请求上下文的场景）。
+func EvaluateFor(name string, subject Subject) Evaluation {
+	f, ok := lookup(name)
+	if !ok {
+		return Evaluation{Flag: name, Reason: ReasonUnknown}
+	}
+	enabled, reason := f.evaluate(subject)
+	return Evaluation{Flag: f.name, Enabled: enabled, Reason: reason}
+}
+
+func (f *flag) evaluate(s Subject) (bool, string) {
+	if !f.enabled {
+		return false, ReasonDisabled
+	}
+	if contains(f.denySso, s.SsoId) || contains(f.denyTenant, s.TenantId) {
+		return false, ReasonDenied
+	}
+	if contains(f.allowSso, s.SsoId) || contains(f.allowTenant, s.TenantId) {
+		return true, ReasonAllowed
+	}
+	if f.percentage >= 100 {
+		return true, ReasonRollout
+	}
+	if f.percentage <= 0 {
+		return false, ReasonNotInRollout
+	}
+	key := s.SsoId
+	if f.hashBy == HashByTenant {
+		key = s.TenantId
+	}
+	if key == "" {
+		return false, ReasonNoSubject
+	}
+	if float64(bucket(f.name, key)) < f.percentage*bucketScale/100 {
+		return true, ReasonRollout
+	}
+	return false, ReasonNotInRollout
+}
+
+// bucket 以开关名加盐，避免不同开关的灰度人群完全重合；比例调大时已命中的主体保持命中。
+func bucket(name, key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{':'})
+	_, _ = h.Write([]byte(key))
+	return h.Sum32() % bucketScale
+}
+
+func contains(set map[string]struct{}, v string) bool {
+	if v == "" || set == nil {
+		return false
+	}
+	_, ok := set[v]
+	return ok
+}
@@ -0,0 +1,133 @@
+package myFeature
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+)
+
+func pct(v float64) *float64 { return &v }
+
+func TestEvaluateOrder(t *testing.T) {
+	err := Init(Config{Flags: map[string]FlagConfig{
+		"New_Checkout": {
+			Enabled:      true,
+			Percentage:   pct(0),
+			AllowSsoIds:  []string{"1", "2"},
+			DenySsoIds:   []string{"2"},
+			AllowTenants: []string{"t1"},
+		},
+		"off": {Enabled: false, AllowSsoIds: []string{"1"}},
+		"all": {Enabled: true},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		flag    string
+		subject Subject
+		want    bool
+		reason  string
+	}{
+		{"new_checkout", Subject{SsoId: "1"}, true, ReasonAllowed},
+		{"new_checkout", Subject{SsoId: "2"}, false, ReasonDenied},
+		{"new_checkout", Subject{SsoId: "3", TenantId: "t1"}, true, ReasonAllowed},
+		{"new_checkout", Subject{SsoId: "3"}, false, ReasonNotInRollout},
+		{"off", Subject{SsoId: "1"}, false, ReasonDisabled},
+		{"all", Subject{}, true, ReasonRollout},
+		{"missing", Subject{SsoId: "1"}, false, ReasonUnknown},
+	}
+	for _, tc := range cases {
+		got := EvaluateFor(tc.flag, tc.subject)
+		if got.Enabled != tc.want || got.Reason != tc.reason {
+			t.Errorf("EvaluateFor(%q, %+v) = %+v, want %v/%s", tc.flag, tc.subject, got, tc.want, tc.reason)
+		}
+	}
+}
+
+func TestPercentageRollout(t *testing.T) {
+	if err := Init(Config{Flags: map[string]FlagConfig{
+		"half":   {Enabled: true, Percentage: pct(50)},
+		"tenant": {Enabled: true, Percentage: pct(50), HashBy: "tenant"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	hits := 0
+	for i := 0; i < 10000; i++ {
+		if EvaluateFor("half", Subject{SsoId: strconv.Itoa(i)}).Enabled {
+			hits++
+		}
+	}
+	if hits < 4500 || hits > 5500 {
+		t.Fatalf("50%% rollout hit %d of 10000", hits)
+	}
+	first := EvaluateFor("half", Subject{SsoId: "42"})
+	for i := 0; i < 10; i++ {
+		if EvaluateFor("half", Subject{SsoId: "42"}) != first {
+			t.Fatal("rollout result should be stable for the same subject")
+		}
+	}
+	if got := EvaluateFor("tenant", Subject{SsoId: "42"}); got.Enabled || got.Reason != ReasonNoSubject {
+		t.Fatalf("tenant rollout without tenant = %+v", got)
+	}
+}
+
+func TestInitRejectsInvalidConfig(t *testing.T) {
+	if err := Init(Config{Flags: map[string]FlagConfig{"a": {Enabled: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Init(Config{Flags: map[string]FlagConfig{"b": {Percentage: pct(120)}}}); err == nil {
+		t.Fatal("expected error for percentage > 100")
+	}
+	if err := Init(Config{Flags: map[string]FlagConfig{"b": {HashBy: "device"}}}); err == nil {
+		t.Fatal("expected error for unknown hashBy")
+	}
+	if !EvaluateFor("a", Subject{}).Enabled {
+		t.Fatal("invalid config should keep previous flags")
+	}
+}
+
+func TestRequireMiddleware(t *testing.T) {
+	if err := Init(Config{Flags: map[string]FlagConfig{
+		"beta": {Enabled: true, Percentage: pct(0), AllowSsoIds: []string{"7"}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if ssoId := c.GetHeader("x-test-sso"); ssoId != "" {
+			myContext.BindScalars(c, myContext.ScalarBinding{SsoId: ssoId})
+		}
+	})
+	reached := false
+	r.GET("/beta", Require("beta"), func(c *gin.Context) {
+		reached = true
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/beta", nil)
+	req.Header.Set("x-test-sso", "7")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !reached {
+		t.Fatalf("allowed subject status = %d", w.Code)
+	}
+
+	reached = false
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/beta", nil))
+	if reached {
+		t.Fatal("gated route should not reach handler")
+	}
+
+	if Enabled(context.Background(), "beta") {
+		t.Fatal("anonymous context should not be in allow list")
+	}
+}
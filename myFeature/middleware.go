@@ -0,0 +1,34 @@
+package myFeature
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
+)
+
+// CodeFeatureDisabled 开关关闭时返回的错误码，默认与未注册路由一致（404），不暴露未发布功能。
+const CodeFeatureDisabled = "platform.route.not_found"
+
+// Require 路由级开关中间件，需在鉴权中间件之后使用（依赖 ssoId / tenantId）；开关关闭时中止请求。
+func Require(name string, disabledCode ...string) gin.HandlerFunc {
+	code := CodeFeatureDisabled
+	if len(disabledCode) > 0 && disabledCode[0] != "" {
+		code = disabledCode[0]
+	}
+	return func(c *gin.Context) {
+		if !Enabled(c.Request.Context(), name) {
+			myResult.ErrorWithError(c, myException.NewBizError(code, nil))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// IsEnabled 在 handler 内按开关分支。
+func IsEnabled(c *gin.Context, name string) bool {
+	if c == nil || c.Request == nil {
+		return false
+	}
+	return Enabled(c.Request.Context(), name)
+}
@@ -0,0 +1,67 @@
+package myFeature
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
+)
+
+// FlagState 开关配置与对指定主体的判定结果。
+type FlagState struct {
+	Name         string     `json:"name"`
+	Enabled      bool       `json:"enabled"`
+	Description  string     `json:"description,omitempty"`
+	Percentage   float64    `json:"percentage"`
+	HashBy       string     `json:"hashBy"`
+	AllowSsoIds  int        `json:"allowSsoIds"`
+	DenySsoIds   int        `json:"denySsoIds"`
+	AllowTenants int        `json:"allowTenants"`
+	DenyTenants  int        `json:"denyTenants"`
+	Evaluation   Evaluation `json:"evaluation"`
+}
+
+// States 返回全部开关的状态，Evaluation 为对 subject 的判定结果。
+func States(subject Subject) []FlagState {
+	names := Names()
+	states := make([]FlagState, 0, len(names))
+	for _, name := range names {
+		f, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		enabled, reason := f.evaluate(subject)
+		states = append(states, FlagState{
+			Name:         f.name,
+			Enabled:      f.enabled,
+			Description:  f.description,
+			Percentage:   f.percentage,
+			HashBy:       f.hashBy,
+			AllowSsoIds:  len(f.allowSso),
+			DenySsoIds:   len(f.denySso),
+			AllowTenants: len(f.allowTenant),
+			DenyTenants:  len(f.denyTenant),
+			Evaluation:   Evaluation{Flag: f.name, Enabled: enabled, Reason: reason},
+		})
+	}
+	return states
+}
+
+// RegisterRoutes 挂载开关管理接口（GET /v1/features）。
+// 接口会暴露开关配置，所在路由组应挂 myAuth.Required 与权限中间件；
+// 启用 plugins.admin 时已自动挂载为 GET {admin.path}/features，无需重复注册。
+func RegisterRoutes(group *gin.RouterGroup) {
+	group.GET("/v1/features", ListHandler())
+}
+
+// ListHandler 列出开关状态；默认按当前请求主体判定，可用 ?ssoId=&tenantId= 指定主体排查。
+func ListHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subject := SubjectFromContext(c.Request.Context())
+		if ssoId, ok := c.GetQuery("ssoId"); ok {
+			subject.SsoId = ssoId
+		}
+		if tenantId, ok := c.GetQuery("tenantId"); ok {
+			subject.TenantId = tenantId
+		}
+		myResult.Success(c, States(subject))
+	}
+}
@@ -8,11 +8,35 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var snowFlake = SnowFlake{}
 
+// 生成统计，供指标采集
+var (
+	generatedCount      atomic.Uint64
+	clockBackwardsCount atomic.Uint64
+	sequenceWaitCount   atomic.Uint64
+)
+
+// Stats ID 生成统计
+type Stats struct {
+	Generated      uint64 // 成功生成的 ID 数
+	ClockBackwards uint64 // 因时钟回拨拒绝生成的次数
+	SequenceWaits  uint64 // 毫秒内序列耗尽、等待下一毫秒的次数
+}
+
+// GetStats 获取 ID 生成统计
+func GetStats() Stats {
+	return Stats{
+		Generated:      generatedCount.Load(),
+		ClockBackwards: clockBackwardsCount.Load(),
+		SequenceWaits:  sequenceWaitCount.Load(),
+	}
+}
+
 type SnowFlake struct {
 	epoch     int64 // 起始时间戳
 	timestamp int64 // 当前时间戳，毫秒
@@ -74,6 +98,7 @@ func NextId() (int64, error) {
 
 	now := time.Now().UnixNano() / 1000000 // 获取当前时间戳，转毫秒
 	if now < snowFlake.lastTimestamp {     // 如果当前时间小于上一次 ID 生成的时间戳，说明发生时钟回拨
+		clockBackwardsCount.Add(1)
 		return 0, errors.New(fmt.Sprintf("Clock moved backwards. Refusing to generate myId for %d milliseconds", snowFlake.lastTimestamp-now))
 	}
 
@@ -87,6 +112,7 @@ func NextId() (int64, error) {
 		snowFlake.sequence = (snowFlake.sequence + 1) & snowFlake.sequenceMask
 		// 毫秒内序列溢出：超过最大值; 阻塞到下一个毫秒，获得新的时间戳
 		if snowFlake.sequence == 0 {
+			sequenceWaitCount.Add(1)
 			for now <= snowFlake.lastTimestamp {
 				now = time.Now().UnixNano() / 1000000
 			}
@@ -96,6 +122,7 @@ func NextId() (int64, error) {
 	}
 	// 保存本次的时间戳
 	snowFlake.lastTimestamp = now
+	generatedCount.Add(1)
 
 	// 根据偏移量，向左位移达到
 	return (t << snowFlake.timestampShift) | (snowFlake.centerId << snowFlake.centerIdShift) | (snowFlake.workerId << snowFlake.workerIdShift) | snowFlake.sequence, nil
@@ -192,6 +192,20 @@ func AppCode() string {
 	return defaultStore.appCode
 }
 
+// Versions 返回各语言文案版本（内容摘要），未初始化时为 nil
+func Versions() map[string]string {
+	if defaultStore == nil {
+		return nil
+	}
+	defaultStore.mu.RLock()
+	defer defaultStore.mu.RUnlock()
+	versions := make(map[string]string, len(defaultStore.versions))
+	for locale, version := range defaultStore.versions {
+		versions[locale] = version
+	}
+	return versions
+}
+
 func (s *store) getMessages(locale string) (*MessagesBundle, error) {
 	loc := locale
 	if loc == "" {
@@ -0,0 +1,122 @@
+package myLogger
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+)
+
+// 按 traceId / ssoId 临时输出 debug 日志，用于排查单个请求或单个用户而不调低全局级别；
+// 仅对 *Ctx 方法生效（需从 ctx 读取 traceId / ssoId）
+const (
+	ElevateTraceId = "traceId"
+	ElevateSsoId   = "ssoId"
+
+	// DefaultElevateTTL 未指定时长时的默认有效期
+	DefaultElevateTTL = 5 * time.Minute
+	// MaxElevateTTL 有效期上限，避免遗忘关闭导致日志量长期放大
+	MaxElevateTTL = time.Hour
+)
+
+// Elevation 一条临时调试规则
+type Elevation struct {
+	Kind     string    `json:"kind"`
+	Id       string    `json:"id"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
+var (
+	elevateMu  sync.Mutex
+	elevations = map[string]Elevation{}
+	// elevateCount 无规则时 *Ctx 方法无需加锁查询
+	elevateCount atomic.Int32
+)
+
+// ElevateTrace 指定 traceId 的日志在 ttl 内按 debug 输出，ttl<=0 时为 DefaultElevateTTL
+func ElevateTrace(traceId string, ttl time.Duration) Elevation {
+	return elevate(ElevateTraceId, traceId, ttl)
+}
+
+// ElevateSso 指定 ssoId（用户）的日志在 ttl 内按 debug 输出
+func ElevateSso(ssoId string, ttl time.Duration) Elevation {
+	return elevate(ElevateSsoId, ssoId, ttl)
+}
+
+// ClearElevation 提前结束临时调试
+func ClearElevation(kind, id string) {
+	elevateMu.Lock()
+	defer elevateMu.Unlock()
+	delete(elevations, kind+":"+id)
+	elevateCount.Store(int32(len(elevations)))
+}
+
+// Elevations 生效中的临时调试规则（按到期时间排序）
+func Elevations() []Elevation {
+	elevateMu.Lock()
+	defer elevateMu.Unlock()
+	pruneLocked(time.Now())
+	list := make([]Elevation, 0, len(elevations))
+	for _, e := range elevations {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ExpireAt.Before(list[j].ExpireAt) })
+	return list
+}
+
+func elevate(kind, id string, ttl time.Duration) Elevation {
+	if ttl <= 0 {
+		ttl = DefaultElevateTTL
+	}
+	if ttl > MaxElevateTTL {
+		ttl = MaxElevateTTL
+	}
+	e := Elevation{Kind: kind, Id: id, ExpireAt: time.Now().Add(ttl)}
+	if id == "" {
+		return e
+	}
+	elevateMu.Lock()
+	defer elevateMu.Unlock()
+	elevations[kind+":"+id] = e
+	elevateCount.Store(int32(len(elevations)))
+	return e
+}
+
+// elevated ctx 的 traceId 或 ssoId 命中生效中的规则
+func elevated(ctx context.Context) bool {
+	if ctx == nil || elevateCount.Load() == 0 {
+		return false
+	}
+	traceId := myContext.TryGetTraceId(ctx)
+	ssoId := myContext.TryGetSsoId(ctx)
+	if traceId == "" && ssoId == "" {
+		return false
+	}
+	now := time.Now()
+	elevateMu.Lock()
+	defer elevateMu.Unlock()
+	pruneLocked(now)
+	if traceId != "" {
+		if _, ok := elevations[ElevateTraceId+":"+traceId]; ok {
+			return true
+		}
+	}
+	if ssoId != "" {
+		if _, ok := elevations[ElevateSsoId+":"+ssoId]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func pruneLocked(now time.Time) {
+	for key, e := range elevations {
+		if now.After(e.ExpireAt) {
+			delete(elevations, key)
+		}
+	}
+	elevateCount.Store(int32(len(elevations)))
+}
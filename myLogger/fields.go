@@ -0,0 +1,215 @@
+package myLogger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"go.uber.org/zap"
+)
+
+// FieldsExtractor 从 ctx 提取日志字段，*Ctx 方法与 FromContext 每次输出时调用；
+// 须足够轻量（仅读取 ctx 中已有的值），无字段时返回 nil
+type FieldsExtractor func(ctx context.Context) []zap.Field
+
+type namedExtractor struct {
+	name string
+	fn   FieldsExtractor
+}
+
+var (
+	extractorMu sync.Mutex
+	// extractors 写时复制，输出日志时无需加锁
+	extractors atomic.Pointer[[]namedExtractor]
+)
+
+func init() {
+	RegisterFieldsExtractor("context", contextFields)
+}
+
+// RegisterFieldsExtractor 注册字段提取器，按注册顺序输出；同名覆盖，fn 为 nil 时移除。
+// 内置 context 提取器输出 traceId / ssoId / tenantId
+func RegisterFieldsExtractor(name string, fn FieldsExtractor) {
+	extractorMu.Lock()
+	defer extractorMu.Unlock()
+	var current []namedExtractor
+	if p := extractors.Load(); p != nil {
+		current = *p
+	}
+	next := make([]namedExtractor, 0, len(current)+1)
+	replaced := false
+	for _, e := range current {
+		if e.name != name {
+			next = append(next, e)
+			continue
+		}
+		replaced = true
+		if fn != nil {
+			next = append(next, namedExtractor{name: name, fn: fn})
+		}
+	}
+	if !replaced && fn != nil {
+		next = append(next, namedExtractor{name: name, fn: fn})
+	}
+	extractors.Store(&next)
+}
+
+// contextFields 鉴权后才写入的身份字段，须在输出时读取而非绑定时
+func contextFields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	if traceId := myContext.TryGetTraceId(ctx); traceId != "" {
+		fields = append(fields, zap.String(myContext.TraceId, traceId))
+	}
+	if ssoId := myContext.TryGetSsoId(ctx); ssoId != "" {
+		fields = append(fields, zap.String(myContext.SsoId, ssoId))
+	}
+	if tenantId := myContext.TryGetTenantId(ctx); tenantId != "" {
+		fields = append(fields, zap.String(myContext.TenantId, tenantId))
+	}
+	return fields
+}
+
+type boundKey struct{}
+
+// bound With 绑定到 ctx 的字段；按全局日志代次缓存已编码字段的 logger，同一请求多次输出无需重复编码
+type bound struct {
+	fields []zap.Field
+	keys   map[string]struct{}
+	cache  atomic.Pointer[boundCache]
+}
+
+type boundCache struct {
+	generation uint64
+	filtered   *zap.Logger
+	debug      *zap.Logger
+}
+
+// With 返回附带 fields 的 ctx，之后经该 ctx 输出的日志（*Ctx 方法、FromContext）自动带上这些字段；
+// 可多次调用叠加，同名字段以最后一次为准。适合在中间件中一次性绑定路由、来源服务、locale 等
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(fields) == 0 {
+		return ctx
+	}
+	parent := boundFrom(ctx)
+	b := &bound{keys: make(map[string]struct{})}
+	for _, f := range fields {
+		b.keys[f.Key] = struct{}{}
+	}
+	if parent != nil {
+		for _, f := range parent.fields {
+			if _, ok := b.keys[f.Key]; !ok {
+				b.fields = append(b.fields, f)
+			}
+		}
+		for key := range parent.keys {
+			b.keys[key] = struct{}{}
+		}
+	}
+	b.fields = append(b.fields, fields...)
+	return context.WithValue(ctx, boundKey{}, b)
+}
+
+// Fields ctx 上通过 With 绑定的字段（不含提取器输出）
+func Fields(ctx context.Context) []zap.Field {
+	b := boundFrom(ctx)
+	if b == nil {
+		return nil
+	}
+	return append([]zap.Field(nil), b.fields...)
+}
+
+// FromContext 附带 ctx 绑定字段与提取器字段的 *zap.Logger，命中临时调试规则时不做级别过滤；
+// 未初始化时返回 Nop，可直接调用
+func FromContext(ctx context.Context) *zap.Logger {
+	l := rootFor(ctx)
+	if l == nil {
+		return zap.NewNop()
+	}
+	// 全局日志为包装函数跳过了 1 层调用栈，直接使用 *zap.Logger 时须还原
+	return l.With(extracted(ctx, boundFrom(ctx), nil)...).WithOptions(zap.AddCallerSkip(-1))
+}
+
+// FromContext 命名 logger 版本的 FromContext，级别按名称生效
+func (l *Logger) FromContext(ctx context.Context) *zap.Logger {
+	c := l.get()
+	if c == nil {
+		return zap.NewNop()
+	}
+	return c.pick(ctx).With(ctxFields(ctx, nil)...).WithOptions(zap.AddCallerSkip(-1))
+}
+
+func boundFrom(ctx context.Context) *bound {
+	if ctx == nil {
+		return nil
+	}
+	b, _ := ctx.Value(boundKey{}).(*bound)
+	return b
+}
+
+// rootFor 全局日志（命中临时调试时不过滤级别），ctx 有绑定字段时返回已编码字段的缓存 logger
+func rootFor(ctx context.Context) *zap.Logger {
+	if log == nil {
+		return nil
+	}
+	debug := elevated(ctx)
+	b := boundFrom(ctx)
+	if b == nil {
+		if debug {
+			return debugLog
+		}
+		return log
+	}
+	gen := generation.Load()
+	c := b.cache.Load()
+	if c == nil || c.generation != gen {
+		c = &boundCache{generation: gen, filtered: log.With(b.fields...)}
+		b.cache.Store(c)
+	}
+	if !debug {
+		return c.filtered
+	}
+	if c.debug == nil {
+		// 临时调试命中较少，按需构建；并发下重复构建无副作用
+		next := &boundCache{generation: c.generation, filtered: c.filtered, debug: debugLog.With(b.fields...)}
+		b.cache.CompareAndSwap(c, next)
+		c = next
+	}
+	return c.debug
+}
+
+// ctxFields 绑定字段、提取器字段与调用方字段；命名 logger 不缓存绑定字段，每次随调用方字段一并输出
+func ctxFields(ctx context.Context, fields []zap.Field) []zap.Field {
+	b := boundFrom(ctx)
+	if b == nil {
+		return extracted(ctx, nil, nil, fields...)
+	}
+	return extracted(ctx, b, b.fields, fields...)
+}
+
+// extracted 依次追加 prefix、各提取器字段（跳过 With 已绑定的同名字段）与调用方字段
+func extracted(ctx context.Context, b *bound, prefix []zap.Field, fields ...zap.Field) []zap.Field {
+	p := extractors.Load()
+	if ctx == nil || p == nil || len(*p) == 0 {
+		if len(prefix) == 0 {
+			return fields
+		}
+		return append(append(make([]zap.Field, 0, len(prefix)+len(fields)), prefix...), fields...)
+	}
+	out := make([]zap.Field, 0, len(prefix)+len(fields)+4)
+	out = append(out, prefix...)
+	for _, e := range *p {
+		for _, f := range e.fn(ctx) {
+			if b != nil {
+				if _, ok := b.keys[f.Key]; ok {
+					continue
+				}
+			}
+			out = append(out, f)
+		}
+	}
+	return append(out, fields...)
+}
@@ -0,0 +1,84 @@
+package myLogger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithBindsFields(t *testing.T) {
+	buf := captureLogs(t, "info", nil)
+
+	ctx := myContext.WithTraceId(context.Background(), "t-1")
+	ctx = With(ctx, zap.String("route", "/v1/order/:id"), zap.String("locale", "zh-CN"))
+	ctx = With(ctx, zap.String("locale", "en-US"))
+	ctx = myContext.WithSsoId(ctx, "u-1")
+
+	InfoCtx(ctx, "root")
+	Named("order").InfoCtx(ctx, "named")
+	FromContext(ctx).Info("direct")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("want 3 lines, got %d: %s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		for _, want := range []string{`"route":"/v1/order/:id"`, `"locale":"en-US"`, `"traceId":"t-1"`, `"ssoId":"u-1"`} {
+			if !strings.Contains(line, want) {
+				t.Errorf("missing %s in %s", want, line)
+			}
+		}
+		if strings.Contains(line, "zh-CN") || strings.Count(line, `"locale"`) != 1 {
+			t.Errorf("overridden field duplicated: %s", line)
+		}
+	}
+}
+
+func TestFieldsExtractor(t *testing.T) {
+	buf := captureLogs(t, "info", nil)
+	RegisterFieldsExtractor("test", func(ctx context.Context) []zap.Field {
+		return []zap.Field{zap.String("region", "cn-east")}
+	})
+	defer RegisterFieldsExtractor("test", nil)
+
+	InfoCtx(context.Background(), "with-region")
+	// With 绑定的同名字段优先于提取器
+	InfoCtx(With(context.Background(), zap.String("region", "bound")), "bound-region")
+
+	out := buf.String()
+	if !strings.Contains(out, `"region":"cn-east"`) {
+		t.Errorf("extractor field missing: %s", out)
+	}
+	if strings.Count(out, `"region"`) != 2 || !strings.Contains(out, `"region":"bound"`) {
+		t.Errorf("bound field should replace extractor field: %s", out)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	buf := &bytes.Buffer{}
+	encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "message"})
+	core := SamplingConfig{Initial: 2, Thereafter: 5}.wrap(zapcore.NewCore(encoder, zapcore.AddSync(buf), zapcore.DebugLevel))
+	setRoot(zap.New(core))
+	if err := ApplyLevels("info", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 12; i++ {
+		Info("hot")
+	}
+	Info("other")
+
+	out := buf.String()
+	// 前 2 条 + 第 7、12 条
+	if n := strings.Count(out, `"hot"`); n != 4 {
+		t.Errorf("sampled count = %d, want 4: %s", n, out)
+	}
+	if !strings.Contains(out, `"other"`) {
+		t.Errorf("distinct message sampled out: %s", out)
+	}
+}
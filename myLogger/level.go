@@ -0,0 +1,180 @@
+package myLogger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	// atomicLevel 全局日志级别，运行时可调整
+	atomicLevel = zap.NewAtomicLevel()
+
+	levelsMu sync.RWMutex
+	// overrides 按名称覆盖的级别（log.levels），子名称未单独配置时继承父名称
+	overrides = map[string]zapcore.Level{}
+)
+
+// levelCore 按 enabler 过滤；底层 core 以 debug 构建，级别完全由这里决定，
+// 以便命名 logger 的级别低于全局级别、以及按 traceId 临时提升时仍能输出
+type levelCore struct {
+	zapcore.Core
+	enabler zapcore.LevelEnabler
+}
+
+func wrapLevel(enabler zapcore.LevelEnabler) zap.Option {
+	return zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return &levelCore{Core: c, enabler: enabler}
+	})
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	return c.enabler.Enabled(l)
+}
+
+func (c *levelCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.enabler)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), enabler: c.enabler}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enabler.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// Level 当前全局日志级别
+func Level() string {
+	return atomicLevel.Level().String()
+}
+
+// SetLevel 运行时调整全局日志级别（debug / info / warn / error 等），立即生效；
+// 未单独覆盖级别的命名 logger 随之调整
+func SetLevel(level string) error {
+	l, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	atomicLevel.SetLevel(l)
+	refreshNamedLevels()
+	return nil
+}
+
+// NamedLevel 命名 logger 的生效级别
+func NamedLevel(name string) string {
+	return resolveLevel(name).String()
+}
+
+// SetNamedLevel 覆盖命名 logger（及其未单独配置的子 logger）的级别；level 为空时移除覆盖、跟随全局
+func SetNamedLevel(name, level string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return SetLevel(level)
+	}
+	if strings.TrimSpace(level) == "" {
+		levelsMu.Lock()
+		delete(overrides, name)
+		levelsMu.Unlock()
+		refreshNamedLevels()
+		return nil
+	}
+	l, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	levelsMu.Lock()
+	overrides[name] = l
+	levelsMu.Unlock()
+	refreshNamedLevels()
+	return nil
+}
+
+// Levels 按名称覆盖的级别
+func Levels() map[string]string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	out := make(map[string]string, len(overrides))
+	for name, l := range overrides {
+		out[name] = l.String()
+	}
+	return out
+}
+
+// ApplyLevels 以 log.level 与 log.levels 整体替换当前级别（配置热更新时调用），
+// 任一级别无法解析时不做任何修改
+func ApplyLevels(level string, levels map[string]string) error {
+	global := atomicLevel.Level()
+	if strings.TrimSpace(level) != "" {
+		l, err := parseLevel(level)
+		if err != nil {
+			return err
+		}
+		global = l
+	}
+	parsed, err := parseLevels(levels)
+	if err != nil {
+		return err
+	}
+	levelsMu.Lock()
+	overrides = parsed
+	levelsMu.Unlock()
+	atomicLevel.SetLevel(global)
+	refreshNamedLevels()
+	return nil
+}
+
+// resolveLevel 按名称逐级向上查找覆盖（rpc.client → rpc），都没有时为全局级别
+func resolveLevel(name string) zapcore.Level {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	for name != "" {
+		if l, ok := overrides[name]; ok {
+			return l
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return atomicLevel.Level()
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(strings.ToLower(strings.TrimSpace(level)))); err != nil {
+		return l, fmt.Errorf("myLogger: invalid level %q", level)
+	}
+	return l, nil
+}
+
+// parseLevels 解析全部可解析的项，存在无法解析的项时一并返回错误
+func parseLevels(levels map[string]string) (map[string]zapcore.Level, error) {
+	parsed := make(map[string]zapcore.Level, len(levels))
+	var invalid []string
+	for name, level := range levels {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		l, err := parseLevel(level)
+		if err != nil {
+			invalid = append(invalid, name+"="+level)
+			continue
+		}
+		parsed[name] = l
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return parsed, fmt.Errorf("myLogger: invalid levels %s", strings.Join(invalid, ", "))
+	}
+	return parsed, nil
+}
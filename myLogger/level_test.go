@@ -0,0 +1,107 @@
+package myLogger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// captureLogs 以内存 core 替换全局日志，返回输出缓冲
+func captureLogs(t *testing.T, level string, levels map[string]string) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "message", NameKey: "myLogger", LevelKey: "level", EncodeLevel: zapcore.LowercaseLevelEncoder})
+	setRoot(zap.New(zapcore.NewCore(encoder, zapcore.AddSync(buf), zapcore.DebugLevel)))
+	if err := ApplyLevels(level, levels); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestNamedLevelOverride(t *testing.T) {
+	buf := captureLogs(t, "info", map[string]string{"rpc": "debug", "rpc.resolver": "error"})
+
+	Named("rpc.client").Debug("client-debug")
+	Named("rpc.resolver").Warn("resolver-warn")
+	Named("nacos").Debug("nacos-debug")
+	Debug("root-debug")
+	Info("root-info")
+
+	out := buf.String()
+	for _, want := range []string{"client-debug", "root-info", `"myLogger":"rpc.client"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in %s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"resolver-warn", "nacos-debug", "root-debug"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("unexpected %q in %s", unwanted, out)
+		}
+	}
+
+	if got := NamedLevel("rpc.client.pool"); got != "debug" {
+		t.Errorf("NamedLevel(rpc.client.pool) = %s, want debug", got)
+	}
+	if err := SetNamedLevel("rpc", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := NamedLevel("rpc.client"); got != "info" {
+		t.Errorf("after removing override NamedLevel(rpc.client) = %s, want info", got)
+	}
+}
+
+func TestApplyLevelsInvalidKeepsCurrent(t *testing.T) {
+	captureLogs(t, "warn", map[string]string{"rpc": "debug"})
+
+	if err := ApplyLevels("info", map[string]string{"rpc": "verbose"}); err == nil {
+		t.Fatal("expected error for invalid level")
+	}
+	if Level() != "warn" || Levels()["rpc"] != "debug" {
+		t.Errorf("levels changed on invalid input: %s %v", Level(), Levels())
+	}
+}
+
+func TestElevateTrace(t *testing.T) {
+	buf := captureLogs(t, "warn", nil)
+	defer ClearElevation(ElevateTraceId, "t-1")
+
+	hit := myContext.WithTraceId(context.Background(), "t-1")
+	miss := myContext.WithTraceId(context.Background(), "t-2")
+	ElevateTrace("t-1", time.Minute)
+
+	DebugCtx(hit, "hit-root")
+	Named("rpc").DebugCtx(hit, "hit-named")
+	DebugCtx(miss, "miss")
+
+	out := buf.String()
+	if !strings.Contains(out, "hit-root") || !strings.Contains(out, "hit-named") {
+		t.Errorf("elevated trace not logged: %s", out)
+	}
+	if strings.Contains(out, "miss") {
+		t.Errorf("non-elevated trace logged: %s", out)
+	}
+
+	ClearElevation(ElevateTraceId, "t-1")
+	DebugCtx(hit, "after-clear")
+	if strings.Contains(buf.String(), "after-clear") {
+		t.Error("logged after elevation cleared")
+	}
+}
+
+func TestElevationExpires(t *testing.T) {
+	captureLogs(t, "warn", nil)
+	ElevateSso("u-1", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if elevated(myContext.WithSsoId(context.Background(), "u-1")) {
+		t.Error("expired elevation still active")
+	}
+	if len(Elevations()) != 0 {
+		t.Errorf("expired elevation listed: %v", Elevations())
+	}
+}
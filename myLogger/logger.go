@@ -6,13 +6,25 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"time"
 )
 
 var (
 	log *zap.Logger
+	// debugLog 与 log 共用输出但不做级别过滤，供命名 logger 与按 traceId 临时调试使用
+	debugLog *zap.Logger
+	// generation 每次重新初始化递增，命名 logger 据此重建
+	generation atomic.Uint64
 )
 
+// setRoot 以不做级别过滤的 base 替换全局日志，级别由 atomicLevel 控制
+func setRoot(base *zap.Logger) {
+	debugLog = base
+	log = base.WithOptions(wrapLevel(atomicLevel))
+	generation.Add(1)
+}
+
 // GlobalConfig 全局日志配置
 type GlobalConfig struct {
 	EnableTraceId bool   // 是否启用traceId
@@ -92,11 +104,13 @@ func Init() error {
 	// 创建writeSyncer
 	writeSyncer := zapcore.AddSync(os.Stdout)
 
-	// 创建core
-	core := zapcore.NewCore(encoder, writeSyncer, level)
+	// 创建core（级别由 levelCore 过滤）
+	atomicLevel.SetLevel(level)
+	core := newRedactCore(zapcore.NewCore(encoder, writeSyncer, zapcore.DebugLevel))
 
 	// 创建logger，跳过1层调用栈以便正确显示caller
-	log = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.Development())
+	setRoot(zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.Development()))
+	refreshNamedLevels()
 
 	return nil
 }
@@ -160,13 +174,27 @@ func InitWithConfig(logConfig LogConfig) error {
 	// 添加文件输出
 	writeSyncers = append(writeSyncers, zapcore.AddSync(lumberJackLogger))
 
-	// 创建core
+	// 创建core（级别由 levelCore 过滤）
+	atomicLevel.SetLevel(level)
 	core := zapcore.NewTee(
-		zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(writeSyncers...), level),
+		newRedactCore(zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(writeSyncers...), zapcore.DebugLevel)),
 	)
+	core = logConfig.Sampling.wrap(core)
+
+	// 按名称覆盖的级别，无法解析的项忽略
+	parsed, _ := parseLevels(logConfig.Levels)
+	levelsMu.Lock()
+	overrides = parsed
+	levelsMu.Unlock()
 
 	// 创建logger，跳过1层调用栈以便正确显示caller
-	log = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.Development())
+	setRoot(zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.Development()))
+	refreshNamedLevels()
+
+	// 脱敏规则无效时保留内置规则，不影响日志初始化
+	if err := SetRedaction(logConfig.Redact); err != nil {
+		log.Warn("日志脱敏配置无效，使用内置规则", zap.Error(err))
+	}
 
 	return nil
 }
@@ -183,6 +211,32 @@ type LogConfig struct {
 	Stdout     bool   `mapstructure:"stdout"`
 	LogSQL     bool   `mapstructure:"log_sql"`     // 新增：是否记录SQL日志
 	TimeFormat string `mapstructure:"time_format"` // 可选：时间格式配置
+	// Levels 按命名 logger 覆盖级别，如 rpc = "warn"
+	Levels map[string]string `mapstructure:"levels"`
+	// Sampling 高频日志采样，零值不采样
+	Sampling SamplingConfig `mapstructure:"sampling"`
+	// Redact 脱敏规则，零值使用内置敏感键与全部检测器
+	Redact RedactConfig `mapstructure:"redact"`
+}
+
+// SamplingConfig 采样配置：每个周期内同级别、同消息的前 Initial 条全部输出，之后每 Thereafter 条输出 1 条；
+// Thereafter 为 0 时超出 Initial 的全部丢弃
+type SamplingConfig struct {
+	Initial     int `mapstructure:"initial"`
+	Thereafter  int `mapstructure:"thereafter"`
+	TickSeconds int `mapstructure:"tick_seconds"` // 统计周期，默认 1 秒
+}
+
+// wrap 启用时在 core 外包装采样；级别过滤在采样之前，被过滤的日志不计数
+func (s SamplingConfig) wrap(core zapcore.Core) zapcore.Core {
+	if s.Initial <= 0 && s.Thereafter <= 0 {
+		return core
+	}
+	tick := time.Duration(s.TickSeconds) * time.Second
+	if tick <= 0 {
+		tick = time.Second
+	}
+	return zapcore.NewSamplerWithOptions(core, tick, s.Initial, s.Thereafter)
 }
 
 // Sync 同步日志
@@ -2,58 +2,46 @@ package myLogger
 
 import (
 	"context"
-	"github.com/muyi-zcy/tech-muyi-base-go/myContext"
+
 	"go.uber.org/zap"
 )
 
-// addTraceIdToFields 自动从context中获取traceId并添加到日志字段中
-func addTraceIdToFields(ctx context.Context, fields ...zap.Field) []zap.Field {
-	// 获取traceId
-	traceId, traceErr := myContext.GetTraceId(ctx)
-	// 如果traceId不为空，添加到字段中
-	if traceErr == nil {
-		fields = append(fields, zap.String(myContext.TraceId, traceId))
-	}
-
-	ssoId := myContext.TryGetSsoId(ctx)
-	if ssoId != "" {
-		fields = append(fields, zap.String(myContext.SsoId, ssoId))
+// forCtx ctx 对应的全局 logger（含 With 绑定字段）与追加提取器字段后的调用方字段
+func forCtx(ctx context.Context, fields []zap.Field) (*zap.Logger, []zap.Field) {
+	l := rootFor(ctx)
+	if l == nil {
+		return nil, nil
 	}
-	return fields
+	return l, extracted(ctx, boundFrom(ctx), nil, fields...)
 }
 
 func DebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
-	if log != nil {
-		fields = addTraceIdToFields(ctx, fields...)
-		log.Debug(msg, fields...)
+	if l, fields := forCtx(ctx, fields); l != nil {
+		l.Debug(msg, fields...)
 	}
 }
 
 func InfoCtx(ctx context.Context, msg string, fields ...zap.Field) {
-	if log != nil {
-		fields = addTraceIdToFields(ctx, fields...)
-		log.Info(msg, fields...)
+	if l, fields := forCtx(ctx, fields); l != nil {
+		l.Info(msg, fields...)
 	}
 }
 
 func WarnCtx(ctx context.Context, msg string, fields ...zap.Field) {
-	if log != nil {
-		fields = addTraceIdToFields(ctx, fields...)
-		log.Warn(msg, fields...)
+	if l, fields := forCtx(ctx, fields); l != nil {
+		l.Warn(msg, fields...)
 	}
 }
 
 func ErrorCtx(ctx context.Context, msg string, fields ...zap.Field) {
-	if log != nil {
-		fields = addTraceIdToFields(ctx, fields...)
-		log.Error(msg, fields...)
+	if l, fields := forCtx(ctx, fields); l != nil {
+		l.Error(msg, fields...)
 	}
 }
 
 func FatalCtx(ctx context.Context, msg string, fields ...zap.Field) {
-	if log != nil {
-		fields = addTraceIdToFields(ctx, fields...)
-		log.Fatal(msg, fields...)
+	if l, fields := forCtx(ctx, fields); l != nil {
+		l.Fatal(msg, fields...)
 	}
 }
 
@@ -0,0 +1,171 @@
+package myLogger
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// Logger 命名子日志，输出中 myLogger 字段为名称；级别可由 log.levels 按名称单独覆盖。
+// 可在包级变量中创建（早于 Init），首次输出时才绑定到当前的全局日志
+type Logger struct {
+	name  string
+	level zap.AtomicLevel
+	cache atomic.Pointer[namedCache]
+}
+
+type namedCache struct {
+	generation uint64
+	filtered   *zap.Logger
+	debug      *zap.Logger
+}
+
+var (
+	namedMu sync.Mutex
+	named   = map[string]*Logger{}
+)
+
+// Named 获取命名 logger，同名返回同一实例；层级以 . 分隔，如 rpc.client
+func Named(name string) *Logger {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	if l, ok := named[name]; ok {
+		return l
+	}
+	l := &Logger{name: name, level: zap.NewAtomicLevelAt(resolveLevel(name))}
+	named[name] = l
+	return l
+}
+
+// Named 子 logger，名称为 父名称.name
+func (l *Logger) Named(name string) *Logger {
+	if l.name == "" {
+		return Named(name)
+	}
+	return Named(l.name + "." + name)
+}
+
+// Name 名称
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// Zap 底层 *zap.Logger（已按名称级别过滤），未初始化时为 nil
+func (l *Logger) Zap() *zap.Logger {
+	if c := l.get(); c != nil {
+		return c.filtered
+	}
+	return nil
+}
+
+// loggerNames 已创建的命名 logger 及其生效级别
+func loggerNames() map[string]string {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	out := make(map[string]string, len(named))
+	for name, l := range named {
+		out[name] = l.level.Level().String()
+	}
+	return out
+}
+
+// refreshNamedLevels 全局级别或覆盖变化后重新计算各命名 logger 的生效级别
+func refreshNamedLevels() {
+	namedMu.Lock()
+	list := make([]*Logger, 0, len(named))
+	for _, l := range named {
+		list = append(list, l)
+	}
+	namedMu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	for _, l := range list {
+		l.level.SetLevel(resolveLevel(l.name))
+	}
+}
+
+// get 全局日志重新初始化后重建
+func (l *Logger) get() *namedCache {
+	gen := generation.Load()
+	if c := l.cache.Load(); c != nil && c.generation == gen {
+		return c
+	}
+	base := debugLog
+	if base == nil {
+		return nil
+	}
+	base = base.Named(l.name)
+	c := &namedCache{generation: gen, filtered: base.WithOptions(wrapLevel(l.level)), debug: base}
+	l.cache.Store(c)
+	return c
+}
+
+// pick 命中按 traceId / ssoId 的临时调试时使用不过滤级别的 logger
+func (c *namedCache) pick(ctx context.Context) *zap.Logger {
+	if elevated(ctx) {
+		return c.debug
+	}
+	return c.filtered
+}
+
+func (l *Logger) Debug(msg string, fields ...zap.Field) {
+	if c := l.get(); c != nil {
+		c.filtered.Debug(msg, fields...)
+	}
+}
+
+func (l *Logger) Info(msg string, fields ...zap.Field) {
+	if c := l.get(); c != nil {
+		c.filtered.Info(msg, fields...)
+	}
+}
+
+func (l *Logger) Warn(msg string, fields ...zap.Field) {
+	if c := l.get(); c != nil {
+		c.filtered.Warn(msg, fields...)
+	}
+}
+
+func (l *Logger) Error(msg string, fields ...zap.Field) {
+	if c := l.get(); c != nil {
+		c.filtered.Error(msg, fields...)
+	}
+}
+
+func (l *Logger) Fatal(msg string, fields ...zap.Field) {
+	if c := l.get(); c != nil {
+		c.filtered.Fatal(msg, fields...)
+	}
+}
+
+func (l *Logger) DebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if c := l.get(); c != nil {
+		c.pick(ctx).Debug(msg, ctxFields(ctx, fields)...)
+	}
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if c := l.get(); c != nil {
+		c.pick(ctx).Info(msg, ctxFields(ctx, fields)...)
+	}
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if c := l.get(); c != nil {
+		c.pick(ctx).Warn(msg, ctxFields(ctx, fields)...)
+	}
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if c := l.get(); c != nil {
+		c.pick(ctx).Error(msg, ctxFields(ctx, fields)...)
+	}
+}
+
+func (l *Logger) FatalCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	if c := l.get(); c != nil {
+		c.pick(ctx).Fatal(msg, ctxFields(ctx, fields)...)
+	}
+}
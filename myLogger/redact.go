@@ -0,0 +1,332 @@
+package myLogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redacted 脱敏后的占位值
+const Redacted = "******"
+
+// 内置检测器名称，可通过 RedactConfig.DisabledDetectors 关闭
+const (
+	DetectorBearer = "bearer" // Authorization: Bearer xxx
+	DetectorJWT    = "jwt"    // eyJ 开头的三段式 JWT
+	DetectorCard   = "card"   // 13~19 位银行卡号（前缀与 Luhn 校验均通过）
+)
+
+// defaultRedactKeys 内置敏感键；键名去掉 _ / - 并转小写后以其结尾即视为敏感，如 newPassword、access_token、user_phone
+var defaultRedactKeys = []string{
+	"password", "passwd", "secret", "token", "authorization", "cookie",
+	"privatekey", "apikey", "idcard", "phone", "mobile", "bankcard", "cardno",
+}
+
+var (
+	bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	jwtPattern    = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	cardPattern   = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	// kvPattern 文本中的 key=value / "key":"value" / key = 'value'（SQL、表单、截断的 JSON）
+	kvPattern = regexp.MustCompile("([A-Za-z0-9_.\\-]+)([\"'`]?\\s*[:=]\\s*)(\"(?:[^\"\\\\]|\\\\.)*\"|'[^']*'|[^\\s,&;)\"'{}\\[\\]]+)")
+)
+
+// RedactConfig 日志脱敏配置
+type RedactConfig struct {
+	// Keys 追加的敏感键，与内置列表合并
+	Keys []string `mapstructure:"keys"`
+	// DisabledDetectors 关闭的内置检测器（bearer / jwt / card）
+	DisabledDetectors []string `mapstructure:"disabled_detectors"`
+}
+
+type detector struct {
+	name    string
+	pattern *regexp.Regexp
+	replace func(string) string
+}
+
+var builtinDetectors = []detector{
+	{name: DetectorBearer, pattern: bearerPattern, replace: func(string) string { return "Bearer " + Redacted }},
+	{name: DetectorJWT, pattern: jwtPattern, replace: func(string) string { return Redacted }},
+	{name: DetectorCard, pattern: cardPattern, replace: maskCard},
+}
+
+type redactor struct {
+	keys      []string
+	detectors []detector
+	// fieldKeys 日志字段键名判定缓存；字段键名基本是代码中的常量，数量有限。
+	// 正文中的键来自外部输入，不缓存
+	fieldKeys sync.Map
+}
+
+var activeRedactor atomic.Pointer[redactor]
+
+func init() {
+	activeRedactor.Store(newRedactor(defaultRedactKeys, builtinDetectors))
+}
+
+// SetRedaction 替换脱敏规则，立即对全部日志生效；检测器名称无法识别时返回错误且不做修改
+func SetRedaction(cfg RedactConfig) error {
+	disabled := make(map[string]bool, len(cfg.DisabledDetectors))
+	for _, name := range cfg.DisabledDetectors {
+		name = strings.ToLower(strings.TrimSpace(name))
+		known := false
+		for _, d := range builtinDetectors {
+			known = known || d.name == name
+		}
+		if !known {
+			return fmt.Errorf("myLogger: unknown redaction detector %q", name)
+		}
+		disabled[name] = true
+	}
+	detectors := make([]detector, 0, len(builtinDetectors))
+	for _, d := range builtinDetectors {
+		if !disabled[d.name] {
+			detectors = append(detectors, d)
+		}
+	}
+	activeRedactor.Store(newRedactor(append(append([]string{}, defaultRedactKeys...), cfg.Keys...), detectors))
+	return nil
+}
+
+func newRedactor(keys []string, detectors []detector) *redactor {
+	r := &redactor{detectors: detectors}
+	for _, k := range keys {
+		if k = normalizeKey(k); k != "" {
+			r.keys = append(r.keys, k)
+		}
+	}
+	return r
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.NewReplacer("_", "", "-", "").Replace(key)
+}
+
+// SensitiveKey 键名是否命中敏感键（日志字段、JSON 键、key=value 中的 key）
+func SensitiveKey(key string) bool {
+	return activeRedactor.Load().isSensitive(key)
+}
+
+func (r *redactor) isSensitiveField(key string) bool {
+	if v, ok := r.fieldKeys.Load(key); ok {
+		return v.(bool)
+	}
+	hit := r.isSensitive(key)
+	r.fieldKeys.Store(key, hit)
+	return hit
+}
+
+func (r *redactor) isSensitive(key string) bool {
+	normalized := normalizeKey(key)
+	// 取 a.b.password 的最后一段
+	if i := strings.LastIndex(normalized, "."); i >= 0 {
+		normalized = normalized[i+1:]
+	}
+	for _, k := range r.keys {
+		if strings.HasSuffix(normalized, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactText 文本脱敏：检测器命中的片段与敏感键的 key=value 值替换为 Redacted，用于 SQL、表单与非 JSON 文本
+func RedactText(s string) string {
+	if s == "" {
+		return s
+	}
+	r := activeRedactor.Load()
+	s = r.detect(s)
+	return kvPattern.ReplaceAllStringFunc(s, func(m string) string {
+		sub := kvPattern.FindStringSubmatch(m)
+		if !r.isSensitive(sub[1]) {
+			return m
+		}
+		value := sub[3]
+		if n := len(value); n >= 2 && (value[0] == '"' || value[0] == '\'') && value[n-1] == value[0] {
+			if n == 2 {
+				return m
+			}
+			value = string(value[0]) + Redacted + string(value[0])
+		} else {
+			value = Redacted
+		}
+		return sub[1] + sub[2] + value
+	})
+}
+
+// RedactJSON JSON 文本脱敏：敏感键下的值（含嵌套对象、数组）整体替换，其余字符串值经检测器处理；
+// 非合法 JSON（如被截断）时退回 RedactText
+func RedactJSON(body []byte) string {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return RedactText(string(body))
+	}
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return RedactText(string(body))
+	}
+	r := activeRedactor.Load()
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(r.redactValue(v)); err != nil {
+		return RedactText(string(body))
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+func (r *redactor) redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if r.isSensitive(k) {
+				val[k] = maskAny(item)
+				continue
+			}
+			val[k] = r.redactValue(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = r.redactValue(item)
+		}
+		return val
+	case string:
+		return r.detect(val)
+	case json.Number:
+		if masked := r.detect(val.String()); masked != val.String() {
+			return masked
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// maskAny 敏感键下的值整体脱敏；空值与 null 保留，便于区分「未传」
+func maskAny(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if val == "" {
+			return val
+		}
+	}
+	return Redacted
+}
+
+func (r *redactor) detect(s string) string {
+	for _, d := range r.detectors {
+		s = d.pattern.ReplaceAllStringFunc(s, d.replace)
+	}
+	return s
+}
+
+// maskCard 保留卡号前 6 后 4 位；前缀或 Luhn 校验不通过的数字串（如雪花 ID）原样返回
+func maskCard(s string) string {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	if !cardPrefix(digits) || !luhn(digits) {
+		return s
+	}
+	return digits[:6] + strings.Repeat("*", len(digits)-10) + digits[len(digits)-4:]
+}
+
+// cardPrefix 常见卡组织前缀：银联 62、Visa 4、MasterCard 51-55 / 22-27、Amex 34 / 37、JCB 35
+func cardPrefix(digits string) bool {
+	switch {
+	case strings.HasPrefix(digits, "62"), strings.HasPrefix(digits, "4"):
+		return len(digits) >= 13
+	case digits[0] == '5' && digits[1] >= '1' && digits[1] <= '5', digits[0] == '2' && digits[1] >= '2' && digits[1] <= '7':
+		return len(digits) == 16
+	case strings.HasPrefix(digits, "34"), strings.HasPrefix(digits, "37"):
+		return len(digits) == 15
+	case strings.HasPrefix(digits, "35"):
+		return len(digits) >= 16
+	}
+	return false
+}
+
+func luhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactCore 敏感键的日志字段替换为 Redacted，其余字符串字段与日志消息经检测器处理（含 With 绑定的字段）。
+// 须直接包在 ioCore 外：Check 时将自身登记为写入方，外层的采样、级别过滤仍先于它生效
+type redactCore struct {
+	zapcore.Core
+}
+
+func newRedactCore(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = activeRedactor.Load().detect(ent.Message)
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+// redactFields 无需脱敏时原样返回，不分配
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	r := activeRedactor.Load()
+	var out []zapcore.Field
+	for i, f := range fields {
+		var masked string
+		switch {
+		case r.isSensitiveField(f.Key):
+			if f.Type == zapcore.StringType && f.String == "" {
+				continue
+			}
+			masked = Redacted
+		case f.Type == zapcore.StringType:
+			if masked = r.detect(f.String); masked == f.String {
+				continue
+			}
+		default:
+			continue
+		}
+		if out == nil {
+			out = append(make([]zapcore.Field, 0, len(fields)), fields...)
+		}
+		out[i] = zap.String(f.Key, masked)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package myLogger
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/muyi-zcy/tech-muyi-base-go/myException"
+	"github.com/muyi-zcy/tech-muyi-base-go/myResult"
+	"go.uber.org/zap"
+)
+
+// RegisterRoutes 注册日志级别管理接口，须挂载在已鉴权的路由组下（如管理接口 /admin）：
+//
+//	GET    /loggers             全局级别、按名称覆盖、各命名 logger 生效级别、临时调试规则
+//	PUT    /loggers             {"name":"rpc","level":"debug"}，name 为空调整全局，level 为空移除覆盖
+//	POST   /loggers/elevations  {"traceId":"...","ttlSeconds":300} 或 {"ssoId":"..."}
+//	DELETE /loggers/elevations?traceId=...&ssoId=...
+//
+// 运行时调整在 log.level / log.levels 配置变更时被覆盖，进程重启后恢复为配置值
+func RegisterRoutes(group *gin.RouterGroup) {
+	group.GET("/loggers", getLoggers)
+	group.PUT("/loggers", setLoggerLevel)
+	group.POST("/loggers/elevations", addElevation)
+	group.DELETE("/loggers/elevations", removeElevation)
+}
+
+type levelRequest struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+type elevationRequest struct {
+	TraceId    string `json:"traceId"`
+	SsoId      string `json:"ssoId"`
+	TtlSeconds int    `json:"ttlSeconds"`
+}
+
+func getLoggers(c *gin.Context) {
+	myResult.Success(c, gin.H{
+		"level":      Level(),
+		"levels":     Levels(),
+		"loggers":    loggerNames(),
+		"elevations": Elevations(),
+	})
+}
+
+func setLoggerLevel(c *gin.Context) {
+	var req levelRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Name == "" && req.Level == "") {
+		myResult.ErrorWithError(c, myException.NewValidationError("level", "level 不能为空"))
+		return
+	}
+	previous := NamedLevel(req.Name)
+	if err := SetNamedLevel(req.Name, req.Level); err != nil {
+		myResult.ErrorWithError(c, myException.NewValidationError("level", err.Error()))
+		return
+	}
+	current := NamedLevel(req.Name)
+	WarnCtx(c.Request.Context(), "日志级别已调整",
+		zap.String("name", req.Name),
+		zap.String("from", previous),
+		zap.String("to", current),
+		zap.String("clientIp", c.ClientIP()),
+	)
+	myResult.Success(c, gin.H{"name": req.Name, "level": current})
+}
+
+func addElevation(c *gin.Context) {
+	var req elevationRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.TraceId == "") == (req.SsoId == "") {
+		myResult.ErrorWithError(c, myException.NewValidationError("traceId", "traceId 与 ssoId 须且仅须指定一个"))
+		return
+	}
+	ttl := time.Duration(req.TtlSeconds) * time.Second
+	var e Elevation
+	if req.TraceId != "" {
+		e = ElevateTrace(req.TraceId, ttl)
+	} else {
+		e = ElevateSso(req.SsoId, ttl)
+	}
+	WarnCtx(c.Request.Context(), "已开启临时调试日志",
+		zap.String("kind", e.Kind),
+		zap.String("id", e.Id),
+		zap.Time("expireAt", e.ExpireAt),
+		zap.String("clientIp", c.ClientIP()),
+	)
+	myResult.Success(c, e)
+}
+
+func removeElevation(c *gin.Context) {
+	traceId, ssoId := c.Query("traceId"), c.Query("ssoId")
+	if traceId == "" && ssoId == "" {
+		myResult.ErrorWithError(c, myException.NewValidationError("traceId", "traceId 与 ssoId 至少指定一个"))
+		return
+	}
+	if traceId != "" {
+		ClearElevation(ElevateTraceId, traceId)
+	}
+	if ssoId != "" {
+		ClearElevation(ElevateSsoId, ssoId)
+	}
+	myResult.Success(c, Elevations())
+}
@@ -248,8 +248,12 @@ func NotFound(message string) MyResult {
 	}
 }
 
+// CodeKey 已写出响应的 code 在 gin.Context 中的键，供指标、访问日志读取
+const CodeKey = "myResult.code"
+
 // JSON 返回JSON响应
 func JSON(c *gin.Context, result MyResult) {
+	c.Set(CodeKey, result.Code)
 	c.JSON(http.StatusOK, result)
 }
 